@@ -0,0 +1,333 @@
+package controller
+
+import (
+	"github.com/GoFurry/gofurry-game-backend/apps/admin/models"
+	"github.com/GoFurry/gofurry-game-backend/apps/admin/service"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/abstract"
+	"github.com/gofiber/fiber/v2"
+)
+
+type adminApi struct{}
+
+var AdminApi *adminApi
+
+func init() {
+	AdminApi = &adminApi{}
+}
+
+// @Summary 新增游戏
+// @Schemes
+// @Description 新增游戏, 并清除相关缓存
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body models.GameSaveRequest true "请求body"
+// @Success 200 {object} models.SaveVo
+// @Router /api/admin/game [Post]
+func (api *adminApi) AddGame(c *fiber.Ctx) error {
+	req := models.GameSaveRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	req.ID = ""
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	data, err := service.GetAdminService().SaveGame(req)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 修改游戏
+// @Schemes
+// @Description 修改游戏, 并清除相关缓存
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body models.GameSaveRequest true "请求body"
+// @Success 200 {object} models.SaveVo
+// @Router /api/admin/game [Put]
+func (api *adminApi) UpdateGame(c *fiber.Ctx) error {
+	req := models.GameSaveRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	if req.ID == "" {
+		return common.NewResponse(c).Error("ID 不能为空")
+	}
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	data, err := service.GetAdminService().SaveGame(req)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 删除游戏
+// @Schemes
+// @Description 批量软删除游戏, 并清除相关缓存
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body models.DeleteRequest true "请求body"
+// @Success 200 {object} common.ResultData
+// @Router /api/admin/game [Delete]
+func (api *adminApi) DeleteGame(c *fiber.Ctx) error {
+	req := models.DeleteRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	err := service.GetAdminService().DeleteGame(req)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).Success()
+}
+
+// @Summary 新增标签
+// @Schemes
+// @Description 新增标签, 并清除相关缓存
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body models.TagSaveRequest true "请求body"
+// @Success 200 {object} models.SaveVo
+// @Router /api/admin/tag [Post]
+func (api *adminApi) AddTag(c *fiber.Ctx) error {
+	req := models.TagSaveRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	req.ID = ""
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	data, err := service.GetAdminService().SaveTag(req)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 修改标签
+// @Schemes
+// @Description 修改标签, 并清除相关缓存
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body models.TagSaveRequest true "请求body"
+// @Success 200 {object} models.SaveVo
+// @Router /api/admin/tag [Put]
+func (api *adminApi) UpdateTag(c *fiber.Ctx) error {
+	req := models.TagSaveRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	if req.ID == "" {
+		return common.NewResponse(c).Error("ID 不能为空")
+	}
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	data, err := service.GetAdminService().SaveTag(req)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 删除标签
+// @Schemes
+// @Description 批量软删除标签, 并清除相关缓存
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body models.DeleteRequest true "请求body"
+// @Success 200 {object} common.ResultData
+// @Router /api/admin/tag [Delete]
+func (api *adminApi) DeleteTag(c *fiber.Ctx) error {
+	req := models.DeleteRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	err := service.GetAdminService().DeleteTag(req)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).Success()
+}
+
+// @Summary 新增游戏标签映射
+// @Schemes
+// @Description 新增游戏标签映射, 并清除相关缓存
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body models.TagMapSaveRequest true "请求body"
+// @Success 200 {object} models.SaveVo
+// @Router /api/admin/tag-map [Post]
+func (api *adminApi) AddTagMap(c *fiber.Ctx) error {
+	req := models.TagMapSaveRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	req.ID = ""
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	data, err := service.GetAdminService().SaveTagMap(req)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 修改游戏标签映射
+// @Schemes
+// @Description 修改游戏标签映射, 并清除相关缓存
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body models.TagMapSaveRequest true "请求body"
+// @Success 200 {object} models.SaveVo
+// @Router /api/admin/tag-map [Put]
+func (api *adminApi) UpdateTagMap(c *fiber.Ctx) error {
+	req := models.TagMapSaveRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	if req.ID == "" {
+		return common.NewResponse(c).Error("ID 不能为空")
+	}
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	data, err := service.GetAdminService().SaveTagMap(req)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 删除游戏标签映射
+// @Schemes
+// @Description 批量软删除游戏标签映射, 并清除相关缓存
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body models.DeleteRequest true "请求body"
+// @Success 200 {object} common.ResultData
+// @Router /api/admin/tag-map [Delete]
+func (api *adminApi) DeleteTagMap(c *fiber.Ctx) error {
+	req := models.DeleteRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	err := service.GetAdminService().DeleteTagMap(req)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).Success()
+}
+
+// @Summary 新增相关作者
+// @Schemes
+// @Description 新增相关作者, 并清除相关缓存
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body models.CreatorSaveRequest true "请求body"
+// @Success 200 {object} models.SaveVo
+// @Router /api/admin/creator [Post]
+func (api *adminApi) AddCreator(c *fiber.Ctx) error {
+	req := models.CreatorSaveRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	req.ID = ""
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	data, err := service.GetAdminService().SaveCreator(req)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 修改相关作者
+// @Schemes
+// @Description 修改相关作者, 并清除相关缓存
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body models.CreatorSaveRequest true "请求body"
+// @Success 200 {object} models.SaveVo
+// @Router /api/admin/creator [Put]
+func (api *adminApi) UpdateCreator(c *fiber.Ctx) error {
+	req := models.CreatorSaveRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	if req.ID == "" {
+		return common.NewResponse(c).Error("ID 不能为空")
+	}
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	data, err := service.GetAdminService().SaveCreator(req)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 删除相关作者
+// @Schemes
+// @Description 批量软删除相关作者, 并清除相关缓存
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param body body models.DeleteRequest true "请求body"
+// @Success 200 {object} common.ResultData
+// @Router /api/admin/creator [Delete]
+func (api *adminApi) DeleteCreator(c *fiber.Ctx) error {
+	req := models.DeleteRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	err := service.GetAdminService().DeleteCreator(req)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).Success()
+}
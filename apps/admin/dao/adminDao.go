@@ -0,0 +1,95 @@
+package dao
+
+import (
+	"time"
+
+	rm "github.com/GoFurry/gofurry-game-backend/apps/recommend/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/abstract"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+var newAdminDao = new(adminDao)
+
+func init() {
+	newAdminDao.Init()
+}
+
+type adminDao struct{ abstract.Dao }
+
+func GetAdminDao() *adminDao { return newAdminDao }
+
+// Exists 判断未删除的记录是否存在
+func (dao adminDao) Exists(table string, id int64) (bool, common.GFError) {
+	var count int64
+	db := dao.Gm.Table(table).Where("id = ? AND deleted IS NOT TRUE", id).Count(&count)
+	if err := db.Error; err != nil {
+		log.Error(err)
+		return false, common.NewDaoError(err.Error())
+	}
+	return count > 0, nil
+}
+
+// ExistsTagMap 判断游戏与标签的映射是否已存在, 排除 excludeID 本身
+func (dao adminDao) ExistsTagMap(gameID int64, tagID int64, excludeID int64) (bool, common.GFError) {
+	var count int64
+	db := dao.Gm.Table(rm.TableNameGfgTagMap).
+		Where("game_id = ? AND tag_id = ? AND id <> ? AND deleted IS NOT TRUE", gameID, tagID, excludeID).
+		Count(&count)
+	if err := db.Error; err != nil {
+		log.Error(err)
+		return false, common.NewDaoError(err.Error())
+	}
+	return count > 0, nil
+}
+
+// CountChildTag 统计未删除的子标签数量, 排除待删除的标签本身
+func (dao adminDao) CountChildTag(idList []int64) (int64, common.GFError) {
+	var count int64
+	db := dao.Gm.Table(rm.TableNameGfgTag).
+		Where("prefix IN ? AND id NOT IN ? AND deleted IS NOT TRUE", idList, idList).
+		Count(&count)
+	if err := db.Error; err != nil {
+		log.Error(err)
+		return 0, common.NewDaoError(err.Error())
+	}
+	return count, nil
+}
+
+// UpdateColumns 修改未删除记录的指定字段, 零值同样写入
+func (dao adminDao) UpdateColumns(table string, id int64, record any, columns []string) common.GFError {
+	db := dao.Gm.Table(table).Where("id = ? AND deleted IS NOT TRUE", id).Select(columns).Updates(record)
+	if err := db.Error; err != nil {
+		log.Error(err)
+		if pe, ok := err.(*pgconn.PgError); ok && pe.Code == "23505" {
+			return common.NewDaoError("数据重复，入库失败")
+		}
+		return common.NewDaoError(err.Error())
+	}
+	return nil
+}
+
+// SoftDelete 软删除记录, refColumn 不为空时同时软删除引用这些记录的标签映射
+func (dao adminDao) SoftDelete(table string, idList []int64, refColumn string) (res int64, err common.GFError) {
+	now := time.Now()
+	txErr := dao.Gm.Transaction(func(tx *gorm.DB) error {
+		db := tx.Table(table).Where("id IN ? AND deleted IS NOT TRUE", idList).
+			Updates(map[string]any{"deleted": true, "update_time": now})
+		if db.Error != nil {
+			return db.Error
+		}
+		res = db.RowsAffected
+		if refColumn == "" {
+			return nil
+		}
+		return tx.Table(rm.TableNameGfgTagMap).Where(refColumn+" IN ? AND deleted IS NOT TRUE", idList).
+			Updates(map[string]any{"deleted": true, "update_time": now}).Error
+	})
+	if txErr != nil {
+		log.Error(txErr)
+		return 0, common.NewDaoError(txErr.Error())
+	}
+	return res, nil
+}
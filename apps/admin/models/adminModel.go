@@ -0,0 +1,70 @@
+package models
+
+/*
+ * @Desc: 后台管理
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import cm "github.com/GoFurry/gofurry-game-backend/common/models"
+
+// GameSaveRequest 新增/修改游戏, 修改时 ID 必填
+type GameSaveRequest struct {
+	ID          string       `json:"id" validate:"omitempty,number" label:"游戏ID"`
+	Name        string       `json:"name" validate:"required,max=255" label:"游戏名称"`
+	NameEn      string       `json:"name_en" validate:"required,max=255" label:"游戏英文名称"`
+	Info        string       `json:"info" validate:"required,max=300" label:"游戏简介"`
+	InfoEn      string       `json:"info_en" validate:"required,max=300" label:"游戏英文简介"`
+	ReleaseDate string       `json:"release_date" validate:"required,max=255" label:"发行日期"`
+	Developers  []string     `json:"developers" validate:"required,min=1,dive,required" label:"开发商"`
+	Publishers  []string     `json:"publishers" validate:"required,min=1,dive,required" label:"发行商"`
+	Appid       int64        `json:"appid" validate:"gte=0" label:"Steam appid"`
+	Header      string       `json:"header" validate:"required,max=255" label:"游戏封面图"`
+	Resources   []cm.KvModel `json:"resources" label:"游戏相关资源"`
+	Groups      []cm.KvModel `json:"groups" label:"游戏相关社群"`
+	Links       []cm.KvModel `json:"links" label:"三方网站链接"`
+	Weight      *int64       `json:"weight" label:"权重"` // 不传时新增为 0, 修改时不变
+}
+
+// TagSaveRequest 新增/修改标签, 修改时 ID 必填
+type TagSaveRequest struct {
+	ID     string   `json:"id" validate:"omitempty,number" label:"标签ID"`
+	Name   string   `json:"name" validate:"required,max=255" label:"标签名称"`
+	NameEn string   `json:"name_en" validate:"required,max=255" label:"标签英文名称"`
+	Info   string   `json:"info" validate:"required,max=255" label:"标签简介"`
+	InfoEn string   `json:"info_en" validate:"required,max=255" label:"标签英文简介"`
+	Prefix string   `json:"prefix" validate:"required,numeric" label:"父标签"`       // 没有父标签为-1
+	Weight *float64 `json:"weight" validate:"omitempty,gt=0,lte=10" label:"推荐权重"` // 不传时新增为 1, 修改时不变
+}
+
+// TagMapSaveRequest 新增/修改游戏标签映射, 修改时 ID 必填
+type TagMapSaveRequest struct {
+	ID     string   `json:"id" validate:"omitempty,number" label:"映射ID"`
+	GameID string   `json:"game_id" validate:"required,number" label:"游戏ID"`
+	TagID  string   `json:"tag_id" validate:"required,number" label:"标签ID"`
+	Weight *float64 `json:"weight" validate:"omitempty,gt=0,lte=10" label:"标签权重"` // 不传时新增为 1, 修改时不变
+}
+
+// CreatorSaveRequest 新增/修改相关作者, 修改时 ID 必填
+type CreatorSaveRequest struct {
+	ID      string       `json:"id" validate:"omitempty,number" label:"作者ID"`
+	Name    string       `json:"name" validate:"required,max=50" label:"名称"`
+	NameEn  *string      `json:"name_en" validate:"omitempty,max=255" label:"英文名"`
+	Info    string       `json:"info" validate:"required,max=255" label:"相关描述"`
+	InfoEn  *string      `json:"info_en" validate:"omitempty,max=255" label:"英文描述"`
+	MainURL string       `json:"main_url" validate:"required,url,max=255" label:"主链接"`
+	Cover   string       `json:"cover" validate:"required,max=255" label:"封面图"`
+	Links   []cm.KvModel `json:"links" label:"其他链接"`
+	Contact []cm.KvModel `json:"contact" label:"联系方式"`
+	Type    int64        `json:"type" validate:"required,min=1,max=6" label:"类型"` // 1=Steam鉴赏家 2=博主 3=开发者 4=发行者 5=汉化者 6=内容创作者
+}
+
+// DeleteRequest 批量软删除
+type DeleteRequest struct {
+	IDs []string `json:"ids" validate:"required,min=1,max=100,dive,number" label:"ID列表"`
+}
+
+// SaveVo 新增/修改后返回的记录 ID
+type SaveVo struct {
+	ID string `json:"id"`
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"slices"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/admin/dao"
+	"github.com/GoFurry/gofurry-game-backend/apps/admin/models"
+	gm "github.com/GoFurry/gofurry-game-backend/apps/game/models"
+	gs "github.com/GoFurry/gofurry-game-backend/apps/game/service"
+	rm "github.com/GoFurry/gofurry-game-backend/apps/recommend/models"
+	rs "github.com/GoFurry/gofurry-game-backend/apps/recommend/service"
+	"github.com/GoFurry/gofurry-game-backend/apps/schedule"
+	"github.com/GoFurry/gofurry-game-backend/apps/schedule/task"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	cm "github.com/GoFurry/gofurry-game-backend/common/models"
+	cs "github.com/GoFurry/gofurry-game-backend/common/service"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+	"github.com/bytedance/sonic"
+)
+
+type adminService struct{}
+
+var adminSingleton = new(adminService)
+
+func GetAdminService() *adminService { return adminSingleton }
+
+// Redis 定义
+const (
+	redisGameDetailPrefix = "game:" // game:zh-info<ID> game:en-info<ID>
+)
+
+// 修改时写入的字段, 零值与空值同样写入; weight 未传时从中去掉, 保持原值
+var (
+	gameColumns = []string{"name", "name_en", "info", "info_en", "release_date", "developers", "publishers",
+		"appid", "header", "resources", "groups", "links", "weight", "update_time"}
+	tagColumns     = []string{"name", "name_en", "info", "info_en", "prefix", "weight", "update_time"}
+	tagMapColumns  = []string{"game_id", "tag_id", "weight", "update_time"}
+	creatorColumns = []string{"name", "name_en", "info", "info_en", "main_url", "cover", "links", "contact", "type", "update_time"}
+)
+
+// 保存游戏, ID 为空时新增
+func (s adminService) SaveGame(req models.GameSaveRequest) (res models.SaveVo, err common.GFError) {
+	developers, jsonErr := sonic.Marshal(req.Developers)
+	if jsonErr != nil {
+		return res, common.NewServiceError("开发商格式有误")
+	}
+	publishers, jsonErr := sonic.Marshal(req.Publishers)
+	if jsonErr != nil {
+		return res, common.NewServiceError("发行商格式有误")
+	}
+	record := gm.GfgGame{
+		Name:        req.Name,
+		NameEn:      req.NameEn,
+		Info:        req.Info,
+		InfoEn:      req.InfoEn,
+		ReleaseDate: req.ReleaseDate,
+		Developers:  string(developers),
+		Publishers:  string(publishers),
+		Appid:       req.Appid,
+		Header:      req.Header,
+		Resources:   marshalKvList(req.Resources),
+		Groups:      marshalKvList(req.Groups),
+		Links:       marshalKvList(req.Links),
+		UpdateTime:  cm.LocalTime(time.Now()),
+	}
+	columns := gameColumns
+	if req.Weight != nil {
+		record.Weight = *req.Weight
+	} else {
+		columns = withoutColumn(columns, "weight")
+	}
+
+	id, err := s.save(gm.TableNameGfgGame, req.ID, &record.ID, &record.CreateTime, &record, columns)
+	if err != nil {
+		return res, err
+	}
+	if req.ID != "" {
+		cs.Del(redisGameDetailPrefix+"zh-info"+req.ID, redisGameDetailPrefix+"en-info"+req.ID)
+	}
+	invalidateGameCache()
+	return models.SaveVo{ID: util.Int642String(id)}, nil
+}
+
+// 批量软删除游戏, 同时软删除其标签映射
+func (s adminService) DeleteGame(req models.DeleteRequest) common.GFError {
+	idList, err := parseIDList(req.IDs)
+	if err != nil {
+		return err
+	}
+	if _, err = dao.GetAdminDao().SoftDelete(gm.TableNameGfgGame, idList, "game_id"); err != nil {
+		return common.NewServiceError("删除游戏失败")
+	}
+	for _, id := range req.IDs {
+		cs.Del(redisGameDetailPrefix+"zh-info"+id, redisGameDetailPrefix+"en-info"+id)
+	}
+	invalidateGameCache()
+	invalidateTagCache()
+	return nil
+}
+
+// 保存标签, ID 为空时新增
+func (s adminService) SaveTag(req models.TagSaveRequest) (res models.SaveVo, err common.GFError) {
+	prefix, parseErr := util.String2Int64(req.Prefix)
+	if parseErr != nil {
+		return res, common.NewServiceError("父标签 ID 转换有误")
+	}
+	if req.ID != "" && req.ID == req.Prefix {
+		return res, common.NewServiceError("父标签不能是自身")
+	}
+	if prefix != -1 {
+		exists, err := dao.GetAdminDao().Exists(rm.TableNameGfgTag, prefix)
+		if err != nil {
+			return res, err
+		}
+		if !exists {
+			return res, common.NewServiceError("父标签不存在")
+		}
+	}
+	record := rm.GfgTag{
+		Name:       req.Name,
+		NameEn:     req.NameEn,
+		Info:       req.Info,
+		InfoEn:     req.InfoEn,
+		Prefix:     prefix,
+		UpdateTime: cm.LocalTime(time.Now()),
+	}
+	columns := tagColumns
+	if req.Weight != nil {
+		record.Weight = *req.Weight
+	} else {
+		columns = withoutColumn(columns, "weight")
+	}
+
+	id, err := s.save(rm.TableNameGfgTag, req.ID, &record.ID, &record.CreateTime, &record, columns)
+	if err != nil {
+		return res, err
+	}
+	invalidateTagCache()
+	return models.SaveVo{ID: util.Int642String(id)}, nil
+}
+
+// 批量软删除标签, 存在子标签时拒绝删除
+func (s adminService) DeleteTag(req models.DeleteRequest) common.GFError {
+	idList, err := parseIDList(req.IDs)
+	if err != nil {
+		return err
+	}
+	count, err := dao.GetAdminDao().CountChildTag(idList)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return common.NewServiceError("存在未删除的子标签, 请先删除子标签")
+	}
+	if _, err = dao.GetAdminDao().SoftDelete(rm.TableNameGfgTag, idList, "tag_id"); err != nil {
+		return common.NewServiceError("删除标签失败")
+	}
+	invalidateTagCache()
+	return nil
+}
+
+// 保存游戏标签映射, ID 为空时新增
+func (s adminService) SaveTagMap(req models.TagMapSaveRequest) (res models.SaveVo, err common.GFError) {
+	gameID, parseErr := util.String2Int64(req.GameID)
+	if parseErr != nil {
+		return res, common.NewServiceError("Game ID 转换有误")
+	}
+	tagID, parseErr := util.String2Int64(req.TagID)
+	if parseErr != nil {
+		return res, common.NewServiceError("Tag ID 转换有误")
+	}
+	var mapID int64
+	if req.ID != "" {
+		if mapID, parseErr = util.String2Int64(req.ID); parseErr != nil {
+			return res, common.NewServiceError("映射 ID 转换有误")
+		}
+	}
+
+	adminDao := dao.GetAdminDao()
+	if exists, err := adminDao.Exists(gm.TableNameGfgGame, gameID); err != nil {
+		return res, err
+	} else if !exists {
+		return res, common.NewServiceError("游戏不存在")
+	}
+	if exists, err := adminDao.Exists(rm.TableNameGfgTag, tagID); err != nil {
+		return res, err
+	} else if !exists {
+		return res, common.NewServiceError("标签不存在")
+	}
+	if exists, err := adminDao.ExistsTagMap(gameID, tagID, mapID); err != nil {
+		return res, err
+	} else if exists {
+		return res, common.NewServiceError("该游戏已关联此标签")
+	}
+
+	record := rm.GfgTagMap{
+		GameID:     gameID,
+		TagID:      tagID,
+		UpdateTime: cm.LocalTime(time.Now()),
+	}
+	columns := tagMapColumns
+	if req.Weight != nil {
+		record.Weight = *req.Weight
+	} else {
+		columns = withoutColumn(columns, "weight")
+	}
+	id, err := s.save(rm.TableNameGfgTagMap, req.ID, &record.ID, &record.CreateTime, &record, columns)
+	if err != nil {
+		return res, err
+	}
+	invalidateTagCache()
+	return models.SaveVo{ID: util.Int642String(id)}, nil
+}
+
+// 批量软删除游戏标签映射
+func (s adminService) DeleteTagMap(req models.DeleteRequest) common.GFError {
+	idList, err := parseIDList(req.IDs)
+	if err != nil {
+		return err
+	}
+	if _, err = dao.GetAdminDao().SoftDelete(rm.TableNameGfgTagMap, idList, ""); err != nil {
+		return common.NewServiceError("删除标签映射失败")
+	}
+	invalidateTagCache()
+	return nil
+}
+
+// 保存相关作者, ID 为空时新增
+func (s adminService) SaveCreator(req models.CreatorSaveRequest) (res models.SaveVo, err common.GFError) {
+	record := gm.GfgGameCreator{
+		Name:       req.Name,
+		NameEn:     req.NameEn,
+		Info:       req.Info,
+		InfoEn:     req.InfoEn,
+		MainURL:    req.MainURL,
+		Cover:      req.Cover,
+		Links:      marshalKvList(req.Links),
+		Contact:    marshalKvList(req.Contact),
+		Type:       req.Type,
+		UpdateTime: cm.LocalTime(time.Now()),
+	}
+
+	id, err := s.save(gm.TableNameGfgGameCreator, req.ID, &record.ID, &record.CreateTime, &record, creatorColumns)
+	if err != nil {
+		return res, err
+	}
+	invalidateCreatorCache()
+	return models.SaveVo{ID: util.Int642String(id)}, nil
+}
+
+// 批量软删除相关作者
+func (s adminService) DeleteCreator(req models.DeleteRequest) common.GFError {
+	idList, err := parseIDList(req.IDs)
+	if err != nil {
+		return err
+	}
+	if _, err = dao.GetAdminDao().SoftDelete(gm.TableNameGfgGameCreator, idList, ""); err != nil {
+		return common.NewServiceError("删除相关作者失败")
+	}
+	invalidateCreatorCache()
+	return nil
+}
+
+// save 新增或修改记录, 返回记录 ID
+// 新增时生成 ID 与创建时间, 修改时只允许修改未删除的记录, 并且只写入 columns 中的字段
+func (s adminService) save(table string, reqID string, id *int64, createTime *cm.LocalTime, record any, columns []string) (int64, common.GFError) {
+	adminDao := dao.GetAdminDao()
+	if reqID == "" {
+		*id = util.GenerateId()
+		*createTime = cm.LocalTime(time.Now())
+		if err := adminDao.Add(record); err != nil {
+			return 0, err
+		}
+		return *id, nil
+	}
+
+	intID, parseErr := util.String2Int64(reqID)
+	if parseErr != nil {
+		return 0, common.NewServiceError("ID 转换有误")
+	}
+	exists, err := adminDao.Exists(table, intID)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, common.NewServiceError("记录不存在或已删除")
+	}
+	*id = intID
+	if err = adminDao.UpdateColumns(table, intID, record, columns); err != nil {
+		return 0, err
+	}
+	return intID, nil
+}
+
+// withoutColumn 返回去掉 name 后的字段列表, 不修改原列表
+func withoutColumn(columns []string, name string) []string {
+	return slices.DeleteFunc(slices.Clone(columns), func(c string) bool { return c == name })
+}
+
+// parseIDList 将字符串 ID 列表转换为 int64
+func parseIDList(ids []string) ([]int64, common.GFError) {
+	idList := make([]int64, 0, len(ids))
+	for _, v := range ids {
+		intID, parseErr := util.String2Int64(v)
+		if parseErr != nil {
+			return nil, common.NewServiceError("ID 转换有误: " + v)
+		}
+		idList = append(idList, intID)
+	}
+	return idList, nil
+}
+
+// marshalKvList 将键值对列表转换为 json 字符串, 为空时返回 nil
+func marshalKvList(list []cm.KvModel) *string {
+	if len(list) == 0 {
+		return nil
+	}
+	bytes, err := sonic.Marshal(list)
+	if err != nil {
+		return nil
+	}
+	str := string(bytes)
+	return &str
+}
+
+// invalidateGameCache 清除游戏相关缓存并异步重建
+func invalidateGameCache() {
+	if err := gs.GetGameService().InvalidateInfoCache(); err != nil {
+		log.Error("invalidateGameCache err: ", err.GetMsg())
+	}
+	schedule.Go(task.UpdateMainInfoCache)
+	schedule.Go(task.UpdateGamePanelCache)
+	schedule.Go(task.UpdateGameNewsCache)
+	schedule.Go(task.UpdateFeedCache)
+}
+
+// invalidateTagCache 清除推荐使用的标签缓存, 并异步增量更新相似游戏索引
+func invalidateTagCache() {
+	if err := rs.GetRecommendService().InvalidateTagCache(); err != nil {
+		log.Error("invalidateTagCache err: ", err.GetMsg())
+	}
+	schedule.Go(task.UpdateCBFIndex)
+}
+
+// invalidateCreatorCache 清除相关作者缓存并异步重建
+func invalidateCreatorCache() {
+	if err := gs.GetGameService().InvalidateCreatorCache(); err != nil {
+		log.Error("invalidateCreatorCache err: ", err.GetMsg())
+	}
+	schedule.Go(task.UpdateGameCreatorCache)
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"github.com/GoFurry/gofurry-game-backend/apps/auth/models"
+	"github.com/GoFurry/gofurry-game-backend/apps/auth/service"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/abstract"
+	"github.com/gofiber/fiber/v2"
+)
+
+type authApi struct{}
+
+var AuthApi *authApi
+
+func init() {
+	AuthApi = &authApi{}
+}
+
+// @Summary 管理员登录
+// @Schemes
+// @Description 密码使用 RSA 公钥加密后 Base64 传输, 成功后返回 JWT
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body models.LoginRequest true "请求body"
+// @Success 200 {object} models.LoginVo
+// @Router /api/auth/login [Post]
+func (api *authApi) Login(c *fiber.Ctx) error {
+	req := models.LoginRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	data, err := service.GetAuthService().Login(req, c.IP())
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 获取登录公钥
+// @Schemes
+// @Description 获取登录时加密密码使用的 RSA 公钥
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.PublicKeyVo
+// @Router /api/auth/pubkey [Get]
+func (api *authApi) GetPublicKey(c *fiber.Ctx) error {
+	data, err := service.GetAuthService().GetPublicKey()
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
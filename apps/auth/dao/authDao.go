@@ -0,0 +1,52 @@
+package dao
+
+import (
+	"errors"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/auth/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/abstract"
+	"gorm.io/gorm"
+)
+
+var newAuthDao = new(authDao)
+
+func init() {
+	newAuthDao.Init()
+}
+
+type authDao struct{ abstract.Dao }
+
+func GetAuthDao() *authDao { return newAuthDao }
+
+// 按用户名获取未删除的管理员, 不存在时 ID 为 0
+func (dao authDao) GetByUsername(username string) (res models.GfgAdminUser, err common.GFError) {
+	db := dao.Gm.Table(models.TableNameGfgAdminUser).Where("username = ? AND deleted IS NOT TRUE", username).Take(&res)
+	if dbErr := db.Error; dbErr != nil {
+		if errors.Is(dbErr, gorm.ErrRecordNotFound) {
+			return res, nil
+		}
+		return res, common.NewDaoError(dbErr.Error())
+	}
+	return res, nil
+}
+
+// 更新最后登录时间
+func (dao authDao) UpdateLastLogin(id int64, loginTime time.Time) common.GFError {
+	db := dao.Gm.Table(models.TableNameGfgAdminUser).Where("id = ?", id).Update("last_login_time", loginTime)
+	if dbErr := db.Error; dbErr != nil {
+		return common.NewDaoError(dbErr.Error())
+	}
+	return nil
+}
+
+// 更新密码哈希
+func (dao authDao) UpdatePassword(id int64, hash string) common.GFError {
+	db := dao.Gm.Table(models.TableNameGfgAdminUser).Where("id = ?", id).
+		Updates(map[string]any{"password": hash, "update_time": time.Now()})
+	if dbErr := db.Error; dbErr != nil {
+		return common.NewDaoError(dbErr.Error())
+	}
+	return nil
+}
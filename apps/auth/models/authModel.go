@@ -0,0 +1,45 @@
+package models
+
+/*
+ * @Desc: 登录鉴权
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import cm "github.com/GoFurry/gofurry-game-backend/common/models"
+
+const TableNameGfgAdminUser = "gfg_admin_user"
+
+// GfgAdminUser mapped from table <gfg_admin_user>
+type GfgAdminUser struct {
+	ID            int64         `gorm:"column:id;type:bigint;primaryKey;comment:管理员表ID" json:"id"`                                        // 管理员表ID
+	Username      string        `gorm:"column:username;type:character varying(50);not null;uniqueIndex;comment:用户名" json:"username"`      // 用户名
+	Password      string        `gorm:"column:password;type:character varying(255);not null;comment:bcrypt 密码哈希" json:"-"`                // bcrypt 密码哈希
+	Role          string        `gorm:"column:role;type:character varying(20);not null;comment:角色 admin/editor" json:"role"`              // 角色 admin/editor
+	LastLoginTime *cm.LocalTime `gorm:"column:last_login_time;type:timestamp(0) without time zone;comment:最后登录时间" json:"lastLoginTime"`   // 最后登录时间
+	CreateTime    cm.LocalTime  `gorm:"column:create_time;type:int;type:unsigned;not null;autoCreateTime;comment:创建时间" json:"createTime"` // 创建时间
+	UpdateTime    cm.LocalTime  `gorm:"column:update_time;type:int;type:unsigned;not null;autoUpdateTime;comment:修改时间" json:"updateTime"` // 修改时间
+	Deleted       bool          `gorm:"column:deleted;type:boolean;comment:软删除" json:"deleted"`                                           // 软删除
+}
+
+// TableName GfgAdminUser's table name
+func (*GfgAdminUser) TableName() string {
+	return TableNameGfgAdminUser
+}
+
+type LoginRequest struct {
+	Username string `json:"username" validate:"required,max=50" label:"用户名"`
+	Password string `json:"password" validate:"required,max=1024" label:"密码"` // RSA 公钥加密后的 Base64 密文
+}
+
+type LoginVo struct {
+	Token      string       `json:"token"`
+	UserID     string       `json:"user_id"`
+	UserName   string       `json:"user_name"`
+	Role       string       `json:"role"`
+	ExpireTime cm.LocalTime `json:"expire_time"`
+}
+
+type PublicKeyVo struct {
+	PublicKey string `json:"public_key"`
+}
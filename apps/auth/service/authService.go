@@ -0,0 +1,158 @@
+package service
+
+import (
+	"crypto/subtle"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/auth/dao"
+	"github.com/GoFurry/gofurry-game-backend/apps/auth/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	cm "github.com/GoFurry/gofurry-game-backend/common/models"
+	cs "github.com/GoFurry/gofurry-game-backend/common/service"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+	"github.com/GoFurry/gofurry-game-backend/roof/env"
+)
+
+type authService struct{}
+
+var authSingleton = new(authService)
+
+func GetAuthService() *authService { return authSingleton }
+
+// Redis 定义
+const (
+	redisLoginFailPrefix     = "auth:login-fail:"      // 登录尝试计数 auth:login-fail:<IP>:<用户名>
+	redisLoginUserFailPrefix = "auth:login-fail-user:" // 不区分 IP 的登录尝试计数 auth:login-fail-user:<用户名>
+)
+
+const (
+	loginFailLimit     = 5                // 同一 IP 对同一用户名的尝试次数上限
+	loginUserFailLimit = 20               // 同一用户名的尝试次数上限, 防止轮换 IP 猜测密码
+	loginLockTime      = 15 * time.Minute // 计数周期, 达到上限后锁定至周期结束
+)
+
+// 用户不存在时参与比较的哈希, 使响应时间与密码错误时一致, 避免枚举用户名
+const dummyPasswordHash = "$2a$10$4J5TNKdhSi6VYVwRJjhpd.i1RDTQ7D6g19auaYWSDDvmixOVHeSJ6"
+
+// 登录 解密 RSA 密文后校验 bcrypt 哈希, 成功后签发 JWT
+// 校验前先原子地记录一次尝试, 并发请求也无法超出次数上限; 登录成功后清除计数
+// 同一 IP 的上限较低, 他人输错密码只会在较高的用户名上限处锁定管理员账号
+func (s authService) Login(req models.LoginRequest, ip string) (res models.LoginVo, err common.GFError) {
+	username := strings.TrimSpace(req.Username)
+	failKey := redisLoginFailPrefix + ip + ":" + username
+	userFailKey := redisLoginUserFailPrefix + username
+
+	remain, err := s.loginAttempt(failKey, userFailKey)
+	if err != nil {
+		return res, err
+	}
+
+	password, decryptErr := util.DecryptPassword(req.Password, env.GetServerConfig().Key.LoginPrivate)
+	if decryptErr != nil {
+		log.Error("Login decrypt err: ", decryptErr)
+		return res, common.NewServiceError("密码解密失败")
+	}
+
+	user, err := dao.GetAuthDao().GetByUsername(username)
+	if err != nil {
+		log.Error("Login GetByUsername err: ", err.GetMsg())
+		return res, common.NewServiceError("登录失败, 请稍后再试")
+	}
+	if user.ID == 0 {
+		util.CheckPassword(password, dummyPasswordHash)
+		return res, loginFailed(remain)
+	}
+	if !checkPassword(user, password) {
+		return res, loginFailed(remain)
+	}
+
+	cs.Del(failKey, userFailKey)
+	now := time.Now()
+	token, tokenErr := util.NewToken(util.Int642String(user.ID), user.Username, user.Role)
+	if tokenErr != nil {
+		log.Error("Login NewToken err: ", tokenErr)
+		return res, common.NewServiceError("签发 Token 失败")
+	}
+	if err = dao.GetAuthDao().UpdateLastLogin(user.ID, now); err != nil {
+		log.Error("Login UpdateLastLogin err: ", err.GetMsg())
+	}
+
+	return models.LoginVo{
+		Token:      token,
+		UserID:     util.Int642String(user.ID),
+		UserName:   user.Username,
+		Role:       user.Role,
+		ExpireTime: cm.LocalTime(now.Add(common.JWT_RELET_NUM * time.Hour)),
+	}, nil
+}
+
+// 获取登录加密使用的 RSA 公钥
+func (s authService) GetPublicKey() (res models.PublicKeyVo, err common.GFError) {
+	data, readErr := os.ReadFile(env.GetServerConfig().Key.LoginPublic)
+	if readErr != nil {
+		log.Error("GetPublicKey err: ", readErr)
+		return res, common.NewServiceError("获取公钥失败")
+	}
+	res.PublicKey = string(data)
+	return res, nil
+}
+
+// checkPassword 校验密码, 旧版 MD5 加盐哈希校验通过后升级为 bcrypt
+func checkPassword(user models.GfgAdminUser, password string) bool {
+	if !isLegacyPasswordHash(user.Password) {
+		return util.CheckPassword(password, user.Password)
+	}
+	salt := env.GetServerConfig().Auth.AuthSalt
+	if salt == "" {
+		salt = common.COMMON_AUTH_SALT
+	}
+	legacy := util.CreateMD5(password + salt)
+	if subtle.ConstantTimeCompare([]byte(legacy), []byte(user.Password)) != 1 {
+		return false
+	}
+	hash, hashErr := util.HashPassword(password)
+	if hashErr != nil {
+		log.Error("Login HashPassword err: ", hashErr)
+		return true
+	}
+	if err := dao.GetAuthDao().UpdatePassword(user.ID, hash); err != nil {
+		log.Error("Login UpdatePassword err: ", err.GetMsg())
+	}
+	return true
+}
+
+// isLegacyPasswordHash 旧版哈希为 32 位十六进制 MD5, bcrypt 哈希以 $2 开头
+func isLegacyPasswordHash(hash string) bool {
+	return len(hash) == 32 && !strings.HasPrefix(hash, "$")
+}
+
+// loginAttempt 记录一次登录尝试, 返回本次失败后剩余的尝试次数, 已达到上限时返回错误
+func (s authService) loginAttempt(failKey string, userFailKey string) (int64, common.GFError) {
+	count, err := cs.IncrExpire(failKey, loginLockTime)
+	if err != nil {
+		return 0, common.NewServiceError("登录失败, 请稍后再试")
+	}
+	userCount, err := cs.IncrExpire(userFailKey, loginLockTime)
+	if err != nil {
+		return 0, common.NewServiceError("登录失败, 请稍后再试")
+	}
+	if count > loginFailLimit || userCount > loginUserFailLimit {
+		return 0, lockedError()
+	}
+	return min(loginFailLimit-count, loginUserFailLimit-userCount), nil
+}
+
+// loginFailed 统一返回用户名或密码错误
+func loginFailed(remain int64) common.GFError {
+	if remain > 0 {
+		return common.NewServiceError("用户名或密码错误, 还可尝试 " + util.Int642String(remain) + " 次")
+	}
+	return lockedError()
+}
+
+func lockedError() common.GFError {
+	return common.NewServiceError("登录失败次数过多, 请 " + util.Int2String(int(loginLockTime.Minutes())) + " 分钟后再试")
+}
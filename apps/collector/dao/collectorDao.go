@@ -0,0 +1,163 @@
+package dao
+
+import (
+	"errors"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/collector/models"
+	gm "github.com/GoFurry/gofurry-game-backend/apps/game/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/abstract"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	cm "github.com/GoFurry/gofurry-game-backend/common/models"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+	"gorm.io/gorm"
+)
+
+var newCollectorDao = new(collectorDao)
+
+func init() {
+	newCollectorDao.Init()
+}
+
+type collectorDao struct{ abstract.Dao }
+
+func GetCollectorDao() *collectorDao { return newCollectorDao }
+
+// ListSteamGames 获取配置了 appid 的游戏
+func (dao collectorDao) ListSteamGames() (res []models.SteamGame, err common.GFError) {
+	db := dao.Gm.Table(gm.TableNameGfgGame).Select("id, appid").
+		Where("deleted IS NOT TRUE AND appid > 0").Order("id")
+	if dbErr := db.Find(&res).Error; dbErr != nil {
+		return res, common.NewDaoError(dbErr.Error())
+	}
+	return res, nil
+}
+
+// SaveAppDetail 在同一事务中写入游戏记录与该语言的更新公告
+// 记录按 game_id + lang 覆盖, 不存在时新增
+func (dao collectorDao) SaveAppDetail(gameID int64, detail models.AppDetail) common.GFError {
+	err := dao.Gm.Transaction(func(tx *gorm.DB) error {
+		record := detail.Record
+		record.GameID = gameID
+		record.Lang = detail.Lang
+
+		var existing gm.GfgGameRecord
+		findErr := tx.Table(gm.TableNameGfgGameRecord).Select("id").
+			Where("game_id = ? AND lang = ?", gameID, detail.Lang).Take(&existing).Error
+		switch {
+		case findErr == nil:
+			record.ID = existing.ID
+			if err := tx.Table(gm.TableNameGfgGameRecord).Where("id = ?", record.ID).
+				Select("*").Omit("id").Updates(&record).Error; err != nil {
+				return err
+			}
+		case errors.Is(findErr, gorm.ErrRecordNotFound):
+			record.ID = util.GenerateId()
+			if err := tx.Create(&record).Error; err != nil {
+				return err
+			}
+		default:
+			return findErr
+		}
+
+		return saveNews(tx, gameID, detail.Lang, detail.News)
+	})
+	if err != nil {
+		log.Error(err)
+		return common.NewDaoError(err.Error())
+	}
+	return nil
+}
+
+// saveNews 按 gid 更新已有公告并保留原 id, 未匹配的新增
+// 旧数据没有 gid 时按 url 匹配; 本次未返回的旧公告保留不删除
+func saveNews(tx *gorm.DB, gameID int64, lang string, news []gm.GfgGameNews) error {
+	if len(news) == 0 {
+		return nil
+	}
+	var existing []gm.GfgGameNews
+	if err := tx.Table(gm.TableNameGfgGameNews).Select("id", "gid", "url").
+		Where("game_id = ? AND lang = ?", gameID, lang).Find(&existing).Error; err != nil {
+		return err
+	}
+	byGID, byURL := map[string]int64{}, map[string]int64{}
+	for _, v := range existing {
+		if v.GID != "" {
+			byGID[v.GID] = v.ID
+		} else if v.URL != "" {
+			byURL[v.URL] = v.ID
+		}
+	}
+
+	var created []gm.GfgGameNews
+	for _, v := range news {
+		v.GameID = gameID
+		v.Lang = lang
+		id, ok := byGID[v.GID]
+		if !ok {
+			id, ok = byURL[v.URL]
+			delete(byURL, v.URL)
+		}
+		if !ok {
+			v.ID = util.GenerateId()
+			created = append(created, v)
+			if v.GID != "" {
+				byGID[v.GID] = v.ID
+			}
+			continue
+		}
+		v.ID = id
+		if err := tx.Table(gm.TableNameGfgGameNews).Where("id = ?", id).
+			Select("*").Omit("id", "create_time").Updates(&v).Error; err != nil {
+			return err
+		}
+	}
+	if len(created) == 0 {
+		return nil
+	}
+	return tx.Create(&created).Error
+}
+
+// AppendPriceHistory 价格与该地区最近一条记录不同时追加价格历史
+func (dao collectorDao) AppendPriceHistory(gameID int64, points map[string]gm.GfgGamePriceHistory) common.GFError {
+	if len(points) == 0 {
+		return nil
+	}
+	err := dao.Gm.Transaction(func(tx *gorm.DB) error {
+		now := cm.LocalTime(time.Now())
+		for region, point := range points {
+			var last gm.GfgGamePriceHistory
+			findErr := tx.Table(gm.TableNameGfgGamePriceHistory).
+				Where("game_id = ? AND region = ?", gameID, region).
+				Order("create_time DESC").Take(&last).Error
+			switch {
+			case findErr == nil:
+				if last.Currency == point.Currency && last.Initial == point.Initial &&
+					last.Final == point.Final && last.Discount == point.Discount {
+					continue
+				}
+			case !errors.Is(findErr, gorm.ErrRecordNotFound):
+				return findErr
+			}
+			point.ID = util.GenerateId()
+			point.GameID = gameID
+			point.Region = region
+			point.CreateTime = now
+			if err := tx.Create(&point).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error(err)
+		return common.NewDaoError(err.Error())
+	}
+	return nil
+}
+
+// AddPlayerCount 新增在线人数记录
+func (dao collectorDao) AddPlayerCount(record *gm.GfgGamePlayerCount) common.GFError {
+	return dao.Add(record)
+}
@@ -0,0 +1,43 @@
+package models
+
+/*
+ * @Desc: Steam 数据采集
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import gm "github.com/GoFurry/gofurry-game-backend/apps/game/models"
+
+// SteamLang 站内语言与 Steam 语言、地区的对应关系
+type SteamLang struct {
+	Lang    string // 站内语言 zh/en
+	Steam   string // Steam l 参数
+	Country string // 详情价格使用的地区
+}
+
+var SteamLangs = []SteamLang{
+	{Lang: "zh", Steam: "schinese", Country: "cn"},
+	{Lang: "en", Steam: "english", Country: "us"},
+}
+
+// SteamGame 需要采集的游戏
+type SteamGame struct {
+	ID    int64 `gorm:"column:id"`
+	Appid int64 `gorm:"column:appid"`
+}
+
+// AppDetail 单个语言的采集结果
+type AppDetail struct {
+	Lang     string
+	Record   gm.GfgGameRecord
+	Snapshot gm.GameSaveModel
+	News     []gm.GfgGameNews
+}
+
+// CollectVo 一次采集的汇总
+type CollectVo struct {
+	Total   int      `json:"total"`
+	Success int      `json:"success"`
+	Failed  int      `json:"failed"`
+	Errors  []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/collector/dao"
+	"github.com/GoFurry/gofurry-game-backend/apps/collector/models"
+	"github.com/GoFurry/gofurry-game-backend/apps/collector/steam"
+	gm "github.com/GoFurry/gofurry-game-backend/apps/game/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	cm "github.com/GoFurry/gofurry-game-backend/common/models"
+	cs "github.com/GoFurry/gofurry-game-backend/common/service"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+	"github.com/GoFurry/gofurry-game-backend/roof/env"
+	"github.com/bytedance/sonic"
+	"github.com/sourcegraph/conc/pool"
+)
+
+type collectorService struct{}
+
+var collectorSingleton = new(collectorService)
+
+func GetCollectorService() *collectorService { return collectorSingleton }
+
+// 默认每种语言保留的更新公告数
+const defaultNewsCount = 10
+
+// 采集商店详情、价格与更新公告, 写入游戏记录并刷新 Redis 详情快照
+// 单个游戏失败不影响其他游戏, 失败原因记录在返回结果中
+func (s collectorService) CollectAppInfo(ctx context.Context) (models.CollectVo, common.GFError) {
+	return s.collect(ctx, s.collectAppInfo)
+}
+
+// 采集在线人数, 写入在线人数表并刷新 Redis 最新在线人数
+func (s collectorService) CollectPlayerCount(ctx context.Context) (models.CollectVo, common.GFError) {
+	return s.collect(ctx, s.collectPlayerCount)
+}
+
+// newSteamClient 按当前配置创建客户端, 每轮采集创建一次以便热重载生效
+func newSteamClient() (*steam.Client, error) {
+	cfg := env.GetServerConfig()
+	steamCfg := steam.Config{
+		StoreURL: cfg.Collector.StoreURL,
+		APIURL:   cfg.Collector.APIURL,
+		Timeout:  time.Duration(cfg.Collector.Timeout) * time.Second,
+		Retry:    cfg.Collector.Retry,
+		Backoff:  time.Duration(cfg.Collector.Backoff) * time.Millisecond,
+	}
+	if cfg.Collector.UseProxy {
+		steamCfg.ProxyURL = cfg.Proxy.Url
+	}
+	return steam.NewClient(steamCfg)
+}
+
+// collect 以 thread.steam_app_info_thread 为上限并发采集所有游戏
+func (s collectorService) collect(ctx context.Context, job func(context.Context, *steam.Client, models.SteamGame) error) (res models.CollectVo, err common.GFError) {
+	games, err := dao.GetCollectorDao().ListSteamGames()
+	if err != nil {
+		return res, err
+	}
+	client, clientErr := newSteamClient()
+	if clientErr != nil {
+		return res, common.NewServiceError(clientErr.Error())
+	}
+
+	res.Total = len(games)
+	var mu sync.Mutex
+	p := pool.New().WithMaxGoroutines(max(env.GetServerConfig().Thread.SteamAppInfoThread, 1))
+	for _, game := range games {
+		p.Go(func() {
+			jobErr := ctx.Err()
+			if jobErr == nil {
+				jobErr = job(ctx, client, game)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if jobErr != nil {
+				res.Failed++
+				res.Errors = append(res.Errors, fmt.Sprintf("appid %d: %v", game.Appid, jobErr))
+				return
+			}
+			res.Success++
+		})
+	}
+	p.Wait()
+	return res, nil
+}
+
+func (s collectorService) collectAppInfo(ctx context.Context, client *steam.Client, game models.SteamGame) error {
+	priceList, points, err := s.priceList(ctx, client, game.Appid)
+	if err != nil {
+		return err
+	}
+	newsCount := env.GetServerConfig().Collector.NewsCount
+	if newsCount <= 0 {
+		newsCount = defaultNewsCount
+	}
+
+	id := strconv.FormatInt(game.ID, 10)
+	for _, lang := range models.SteamLangs {
+		data, err := client.AppDetails(ctx, game.Appid, lang.Steam, lang.Country, "")
+		if err != nil {
+			return err
+		}
+		record, snapshot := steam.ParseAppDetail(data, priceList)
+		// 记录对应地区未在 collector.countries 中时同样记录价格历史
+		if _, ok := points[lang.Country]; !ok && snapshot.Price.Currency != "" {
+			points[lang.Country] = steam.PricePoint(lang.Country, data.Get("price_overview"))
+		}
+		appNews, err := client.News(ctx, game.Appid, lang.Steam, newsCount)
+		if err != nil {
+			return err
+		}
+		detail := models.AppDetail{Lang: lang.Lang, Record: record, Snapshot: snapshot, News: steam.ParseNews(appNews)}
+		if gfErr := dao.GetCollectorDao().SaveAppDetail(game.ID, detail); gfErr != nil {
+			return errors.New(gfErr.GetMsg())
+		}
+
+		info, jsonErr := sonic.Marshal(snapshot)
+		if jsonErr != nil {
+			return jsonErr
+		}
+		if gfErr := cs.Set("game:"+lang.Lang+"-info"+id, string(info)); gfErr != nil {
+			return errors.New(gfErr.GetMsg())
+		}
+	}
+
+	if gfErr := dao.GetCollectorDao().AppendPriceHistory(game.ID, points); gfErr != nil {
+		return errors.New(gfErr.GetMsg())
+	}
+	return nil
+}
+
+// priceList 按 collector.countries 采集各地区价格, 未发售或免费的地区跳过
+// 返回价格列表 JSON 与按地区索引的价格历史记录
+func (s collectorService) priceList(ctx context.Context, client *steam.Client, appid int64) (string, map[string]gm.GfgGamePriceHistory, error) {
+	countries := env.GetServerConfig().Collector.Countries
+	prices := make([]gm.PriceModel, 0, len(countries))
+	points := make(map[string]gm.GfgGamePriceHistory, len(countries))
+	for _, country := range countries {
+		country = strings.ToLower(country)
+		data, err := client.AppDetails(ctx, appid, "english", country, "price_overview")
+		if err != nil {
+			return "", nil, err
+		}
+		price := data.Get("price_overview")
+		if price.Get("final_formatted").String() == "" {
+			continue
+		}
+		prices = append(prices, gm.PriceModel{Price: price.Get("final_formatted").String(), Country: strings.ToUpper(country)})
+		points[country] = steam.PricePoint(country, price)
+	}
+	res, err := sonic.Marshal(prices)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(res), points, nil
+}
+
+func (s collectorService) collectPlayerCount(ctx context.Context, client *steam.Client, game models.SteamGame) error {
+	count, err := client.PlayerCount(ctx, game.Appid)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	record := gm.GfgGamePlayerCount{
+		ID:         util.GenerateId(),
+		GameID:     game.ID,
+		Count_:     count,
+		CreateTime: cm.LocalTime(now),
+	}
+	if gfErr := dao.GetCollectorDao().AddPlayerCount(&record); gfErr != nil {
+		return errors.New(gfErr.GetMsg())
+	}
+
+	online, err := sonic.Marshal(gm.GameOnlineModel{
+		ID:         record.ID,
+		GameID:     strconv.FormatInt(game.ID, 10),
+		Count:      count,
+		CreateTime: now.Format(common.TIME_FORMAT_DATE),
+	})
+	if err != nil {
+		return err
+	}
+	if gfErr := cs.Set("game:online"+strconv.FormatInt(game.ID, 10), string(online)); gfErr != nil {
+		return errors.New(gfErr.GetMsg())
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package steam
+
+/*
+ * @Desc: Steam 接口请求, 带超时、重试与指数退避
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// 退避上限, 避免重试等待过长
+const maxBackoff = 30 * time.Second
+
+// Config 客户端配置
+type Config struct {
+	StoreURL string        // 商店接口地址
+	APIURL   string        // Web API 地址
+	Timeout  time.Duration // 单次请求超时, 0 时为 15 秒
+	Retry    int           // 失败重试次数
+	Backoff  time.Duration // 首次重试等待, 之后按指数增长
+	ProxyURL string        // 为空时直连
+}
+
+// Client Steam 接口客户端
+type Client struct {
+	http     *http.Client
+	storeURL string
+	apiURL   string
+	retry    int
+	backoff  time.Duration
+}
+
+// NewClient 创建客户端
+func NewClient(cfg Config) (*Client, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("代理地址有误: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	return &Client{
+		http:     &http.Client{Transport: transport, Timeout: timeout},
+		storeURL: strings.TrimRight(cfg.StoreURL, "/"),
+		apiURL:   strings.TrimRight(cfg.APIURL, "/"),
+		retry:    max(cfg.Retry, 0),
+		backoff:  max(cfg.Backoff, 0),
+	}, nil
+}
+
+// StatusError 非 200 响应
+type StatusError struct {
+	Code       int
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string { return "Steam 响应状态码 " + strconv.Itoa(e.Code) }
+
+// retryable 429、5xx 与网络错误可重试
+func retryable(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Code == http.StatusTooManyRequests || se.Code >= 500
+	}
+	return true
+}
+
+// retryDelay 第 attempt 次重试前的等待时间 backoff*2^(attempt-1), 不超过 maxBackoff
+// 逐次翻倍而不是直接移位, 重试次数较大时也不会溢出
+func retryDelay(backoff time.Duration, attempt int) time.Duration {
+	wait := backoff
+	for i := 1; i < attempt && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
+
+// get 请求并返回 JSON, 失败时按 backoff*2^n 等待后重试, 优先使用 Retry-After
+func (c *Client) get(ctx context.Context, rawURL string, params url.Values) (gjson.Result, error) {
+	if len(params) > 0 {
+		rawURL += "?" + params.Encode()
+	}
+	var lastErr error
+	for attempt := 0; attempt <= c.retry; attempt++ {
+		if attempt > 0 {
+			wait := retryDelay(c.backoff, attempt)
+			var se *StatusError
+			if errors.As(lastErr, &se) && se.RetryAfter > 0 {
+				wait = min(se.RetryAfter, maxBackoff)
+			}
+			select {
+			case <-ctx.Done():
+				return gjson.Result{}, ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+
+		body, err := c.do(ctx, rawURL)
+		if err == nil {
+			if !gjson.ValidBytes(body) {
+				return gjson.Result{}, errors.New("Steam 响应不是合法 JSON: " + rawURL)
+			}
+			return gjson.ParseBytes(body), nil
+		}
+		lastErr = err
+		if !retryable(err) || ctx.Err() != nil {
+			break
+		}
+	}
+	return gjson.Result{}, fmt.Errorf("请求 %s 失败: %w", rawURL, lastErr)
+}
+
+func (c *Client) do(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		se := &StatusError{Code: resp.StatusCode}
+		if seconds, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil && seconds > 0 {
+			se.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return nil, se
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// AppDetails 商店详情, filters 为空时返回全部字段
+func (c *Client) AppDetails(ctx context.Context, appid int64, lang string, country string, filters string) (gjson.Result, error) {
+	params := url.Values{}
+	params.Set("appids", strconv.FormatInt(appid, 10))
+	params.Set("l", lang)
+	params.Set("cc", country)
+	if filters != "" {
+		params.Set("filters", filters)
+	}
+	res, err := c.get(ctx, c.storeURL+"/api/appdetails", params)
+	if err != nil {
+		return res, err
+	}
+	app := res.Get(strconv.FormatInt(appid, 10))
+	if !app.Get("success").Bool() {
+		return res, fmt.Errorf("Steam 未返回 appid %d 的详情", appid)
+	}
+	return app.Get("data"), nil
+}
+
+// PlayerCount 当前在线人数
+func (c *Client) PlayerCount(ctx context.Context, appid int64) (int64, error) {
+	params := url.Values{}
+	params.Set("appid", strconv.FormatInt(appid, 10))
+	res, err := c.get(ctx, c.apiURL+"/ISteamUserStats/GetNumberOfCurrentPlayers/v1/", params)
+	if err != nil {
+		return 0, err
+	}
+	if res.Get("response.result").Int() != 1 {
+		return 0, fmt.Errorf("Steam 未返回 appid %d 的在线人数", appid)
+	}
+	return res.Get("response.player_count").Int(), nil
+}
+
+// News 官方公告, 返回 appnews 节点(newsitems 与 count)
+func (c *Client) News(ctx context.Context, appid int64, lang string, count int) (gjson.Result, error) {
+	params := url.Values{}
+	params.Set("appid", strconv.FormatInt(appid, 10))
+	params.Set("count", strconv.Itoa(count))
+	params.Set("feeds", "steam_community_announcements")
+	params.Set("l", lang)
+	res, err := c.get(ctx, c.apiURL+"/ISteamNews/GetNewsForApp/v2/", params)
+	if err != nil {
+		return res, err
+	}
+	return res.Get("appnews"), nil
+}
@@ -0,0 +1,191 @@
+package steam
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/collector/stub"
+)
+
+// 录制好的 Steam 响应
+const fixtureDir = "../../../conf/fixtures/steam"
+
+// newStubClient 启动替身服务并返回指向它的客户端与请求计数
+// failFirst 个请求直接返回 503, 之后交给替身服务处理
+func newStubClient(t *testing.T, dir string, retry int, failFirst int32) (*Client, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	handler := stub.NewHandler(dir)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failFirst {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(Config{
+		StoreURL: server.URL,
+		APIURL:   server.URL + "/",
+		Timeout:  5 * time.Second,
+		Retry:    retry,
+		Backoff:  time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, &requests
+}
+
+// writeStatus 让替身服务对 appid 的商店详情返回指定状态码
+func writeStatus(t *testing.T, appid string, status string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "appdetails"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "appdetails", appid+".status"), []byte(status), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestClientFixtures(t *testing.T) {
+	client, requests := newStubClient(t, fixtureDir, 0, 0)
+	ctx := context.Background()
+
+	data, err := client.AppDetails(ctx, 312520, "schinese", "cn", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := data.Get("name").String(); name != "Rain World" {
+		t.Fatalf("name = %q", name)
+	}
+	count, err := client.PlayerCount(ctx, 312520)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 4388 {
+		t.Fatalf("player count = %d, want 4388", count)
+	}
+	news, err := client.News(ctx, 312520, "english", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(news.Get("newsitems").Array()); n != 2 {
+		t.Fatalf("news items = %d, want 2", n)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Fatalf("requests = %d, want 3", n)
+	}
+}
+
+func TestClientNotRetryable(t *testing.T) {
+	client, requests := newStubClient(t, fixtureDir, 3, 0)
+
+	_, err := client.AppDetails(context.Background(), 1, "english", "us", "")
+	var se *StatusError
+	if !errors.As(err, &se) || se.Code != http.StatusNotFound {
+		t.Fatalf("err = %v, want status 404", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("requests = %d, 404 should not be retried", n)
+	}
+}
+
+func TestClientRetryThenSuccess(t *testing.T) {
+	client, requests := newStubClient(t, fixtureDir, 3, 2)
+
+	count, err := client.PlayerCount(context.Background(), 481510)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count <= 0 {
+		t.Fatalf("player count = %d", count)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Fatalf("requests = %d, want 3", n)
+	}
+}
+
+func TestClientRetryExhausted(t *testing.T) {
+	client, requests := newStubClient(t, fixtureDir, 2, 100)
+
+	_, err := client.PlayerCount(context.Background(), 481510)
+	var se *StatusError
+	if !errors.As(err, &se) || se.Code != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want status 503", err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Fatalf("requests = %d, want 1 + 2 retries", n)
+	}
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	// 替身服务返回 Retry-After: 1, 应覆盖 1ms 的退避
+	client, requests := newStubClient(t, writeStatus(t, "7", "429"), 1, 0)
+
+	begin := time.Now()
+	_, err := client.AppDetails(context.Background(), 7, "english", "us", "")
+	elapsed := time.Since(begin)
+
+	var se *StatusError
+	if !errors.As(err, &se) || se.Code != http.StatusTooManyRequests || se.RetryAfter != time.Second {
+		t.Fatalf("err = %v, want status 429 with Retry-After 1s", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Fatalf("requests = %d, want 2", n)
+	}
+	if elapsed < time.Second {
+		t.Fatalf("elapsed = %v, Retry-After was not honored", elapsed)
+	}
+}
+
+func TestClientRetryCanceled(t *testing.T) {
+	client, requests := newStubClient(t, writeStatus(t, "7", "503"), 5, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	begin := time.Now()
+	_, err := client.AppDetails(ctx, 7, "english", "us", "")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Fatalf("elapsed = %v, wait was not interrupted", elapsed)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("requests = %d, want 1", n)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	cases := []struct {
+		backoff time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{0, 3, 0},
+		{100 * time.Millisecond, 1, 100 * time.Millisecond},
+		{100 * time.Millisecond, 2, 200 * time.Millisecond},
+		{100 * time.Millisecond, 4, 800 * time.Millisecond},
+		{100 * time.Millisecond, 10, maxBackoff},
+		// 直接移位时会溢出为负数或 0
+		{500 * time.Millisecond, 64, maxBackoff},
+		{500 * time.Millisecond, 1000, maxBackoff},
+		{time.Hour, 1, maxBackoff},
+	}
+	for _, c := range cases {
+		if got := retryDelay(c.backoff, c.attempt); got != c.want {
+			t.Errorf("retryDelay(%v, %d) = %v, want %v", c.backoff, c.attempt, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package steam
+
+/*
+ * @Desc: Steam 响应解析
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	gm "github.com/GoFurry/gofurry-game-backend/apps/game/models"
+	cm "github.com/GoFurry/gofurry-game-backend/common/models"
+	"github.com/tidwall/gjson"
+)
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// ParseAppDetail 将 appdetails 的 data 节点转换为游戏记录与 Redis 快照
+// Steam 字段类型并不稳定(required_age 可能为数字或字符串, pc_requirements 为空时是数组), 统一用 gjson 按需读取
+func ParseAppDetail(data gjson.Result, priceList string) (record gm.GfgGameRecord, snapshot gm.GameSaveModel) {
+	price := data.Get("price_overview")
+	snapshot.Price = gm.SteamAppPrice{
+		Initial:          price.Get("initial").Int(),
+		Final:            price.Get("final").Int(),
+		Currency:         price.Get("currency").String(),
+		DiscountPercent:  price.Get("discount_percent").Int(),
+		InitialFormatted: price.Get("initial_formatted").String(),
+		FinalFormatted:   price.Get("final_formatted").String(),
+	}
+	snapshot.Support = gm.SteamAppSupport{
+		URL:   data.Get("support_info.url").String(),
+		Email: data.Get("support_info.email").String(),
+	}
+	for _, v := range data.Get("screenshots").Array() {
+		snapshot.Screenshots = append(snapshot.Screenshots, gm.SteamAppScreenshot{
+			ID:            v.Get("id").Int(),
+			PathThumbnail: v.Get("path_thumbnail").String(),
+			PathFull:      v.Get("path_full").String(),
+		})
+	}
+	for _, v := range data.Get("movies").Array() {
+		snapshot.Movies = append(snapshot.Movies, gm.SteamAppMovie{
+			ID:        v.Get("id").Int(),
+			Name:      v.Get("name").String(),
+			Thumbnail: v.Get("thumbnail").String(),
+			DashAv1:   v.Get("dash_av1").String(),
+			DashH264:  v.Get("dash_h264").String(),
+			HlsH264:   v.Get("hls_h264").String(),
+		})
+	}
+	snapshot.PriceList = priceList
+	snapshot.SupportedLanguages = data.Get("supported_languages").String()
+	snapshot.Developers = joinArray(data.Get("developers"))
+	snapshot.Publishers = joinArray(data.Get("publishers"))
+	snapshot.HeaderImage = data.Get("header_image").String()
+	snapshot.ShortDescription = data.Get("short_description").String()
+	snapshot.Date = data.Get("release_date.date").String()
+	snapshot.Release = gm.SteamAppRelease{
+		ComingSoon: data.Get("release_date.coming_soon").Bool(),
+		Date:       snapshot.Date,
+	}
+	snapshot.Platforms = platforms(data.Get("platforms"))
+	snapshot.RequiredAge = data.Get("required_age").String()
+	snapshot.Website = data.Get("website").String()
+	snapshot.ContentDescriptors = data.Get("content_descriptors.notes").String()
+	snapshot.DetailedDescription = data.Get("detailed_description").String()
+	snapshot.AboutTheGame = data.Get("about_the_game").String()
+	if req := data.Get("pc_requirements"); req.IsObject() {
+		snapshot.PcRequirements = gm.PcRequirementModel{
+			Minimum:     req.Get("minimum").String(),
+			Recommended: req.Get("recommended").String(),
+		}
+	}
+	snapshot.CollectDate = cm.LocalTime(time.Now())
+
+	record = gm.GfgGameRecord{
+		Language:    languages(snapshot.SupportedLanguages),
+		ReleaseDate: truncate(snapshot.Date, 30),
+		Platform:    truncate(snapshot.Platforms, 50),
+		Developer:   truncate(snapshot.Developers, 100),
+		Publisher:   truncate(snapshot.Publishers, 100),
+		Info:        snapshot.ShortDescription,
+		Cover:       truncate(snapshot.HeaderImage, 255),
+		PriceList:   priceList,
+		Initial:     snapshot.Price.Initial,
+		Final:       snapshot.Price.Final,
+		Discount:    snapshot.Price.DiscountPercent,
+		ComingSoon:  snapshot.Release.ComingSoon,
+	}
+	return
+}
+
+// PricePoint 将 price_overview 节点转换为价格历史记录
+func PricePoint(region string, price gjson.Result) gm.GfgGamePriceHistory {
+	return gm.GfgGamePriceHistory{
+		Region:   region,
+		Currency: price.Get("currency").String(),
+		Initial:  price.Get("initial").Int(),
+		Final:    price.Get("final").Int(),
+		Discount: price.Get("discount_percent").Int(),
+	}
+}
+
+// ParseNews 将 appnews 节点转换为更新公告, 编号从 1 开始, gid 用于采集时匹配已有公告
+func ParseNews(appNews gjson.Result) (res []gm.GfgGameNews) {
+	total := appNews.Get("count").Int()
+	now := cm.LocalTime(time.Now())
+	for i, v := range appNews.Get("newsitems").Array() {
+		res = append(res, gm.GfgGameNews{
+			GID:        v.Get("gid").String(),
+			Headline:   truncate(v.Get("title").String(), 255),
+			Content:    v.Get("contents").String(),
+			Index:      int64(i + 1),
+			PostTime:   cm.LocalTime(time.Unix(v.Get("date").Int(), 0)),
+			CreateTime: now,
+			Author:     truncate(v.Get("author").String(), 50),
+			URL:        truncate(v.Get("url").String(), 255),
+			Total:      total,
+		})
+	}
+	return
+}
+
+func joinArray(arr gjson.Result) string {
+	var items []string
+	for _, v := range arr.Array() {
+		items = append(items, v.String())
+	}
+	return strings.Join(items, ", ")
+}
+
+// platforms 返回支持的平台, 如 windows,mac,linux
+func platforms(p gjson.Result) string {
+	var items []string
+	for _, name := range []string{"windows", "mac", "linux"} {
+		if p.Get(name).Bool() {
+			items = append(items, name)
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// languages 将 supported_languages 的 HTML 转为纯文本语言列表
+// 如 "English<strong>*</strong>, Japanese<br><strong>*</strong>languages with full audio support" -> "English, Japanese"
+func languages(s string) string {
+	s, _, _ = strings.Cut(s, "<br>")
+	s = tagPattern.ReplaceAllString(s, "")
+	return strings.TrimSpace(strings.ReplaceAll(s, "*", ""))
+}
+
+// truncate 按字符截断, 避免超出字段长度
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
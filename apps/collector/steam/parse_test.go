@@ -0,0 +1,141 @@
+package steam
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestParseAppDetail(t *testing.T) {
+	client, _ := newStubClient(t, fixtureDir, 0, 0)
+	data, err := client.AppDetails(context.Background(), 312520, "english", "us", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	priceList := `[{"price":"$24.99","country":"US"}]`
+	record, snapshot := ParseAppDetail(data, priceList)
+
+	for _, c := range []struct{ field, got, want string }{
+		{"Language", record.Language, "English, Simplified Chinese"},
+		{"Platform", record.Platform, "windows,mac"},
+		{"Developer", record.Developer, "Videocult"},
+		{"Publisher", record.Publisher, "Akupara Games"},
+		{"ReleaseDate", record.ReleaseDate, "28 Mar, 2017"},
+		{"PriceList", record.PriceList, priceList},
+		{"Cover", record.Cover, "https://shared.akamai.steamstatic.com/store_item_assets/steam/apps/312520/header.jpg"},
+		{"Currency", snapshot.Price.Currency, "USD"},
+		{"FinalFormatted", snapshot.Price.FinalFormatted, "$24.99"},
+		{"RequiredAge", snapshot.RequiredAge, "0"},
+		{"Support.Email", snapshot.Support.Email, "support@example.com"},
+		{"Website", snapshot.Website, ""},
+		{"ContentDescriptors", snapshot.ContentDescriptors, ""},
+	} {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.field, c.got, c.want)
+		}
+	}
+	if record.Final != 2499 || record.Initial != 2499 || record.Discount != 0 || record.ComingSoon {
+		t.Errorf("price = %d/%d/%d coming soon %v", record.Initial, record.Final, record.Discount, record.ComingSoon)
+	}
+	if len(snapshot.Screenshots) != 2 || len(snapshot.Movies) != 1 {
+		t.Errorf("screenshots = %d, movies = %d", len(snapshot.Screenshots), len(snapshot.Movies))
+	}
+	if !strings.Contains(snapshot.PcRequirements.Minimum, "4 GB") {
+		t.Errorf("pc requirements = %+v", snapshot.PcRequirements)
+	}
+}
+
+func TestParseAppDetailLocalized(t *testing.T) {
+	client, _ := newStubClient(t, fixtureDir, 0, 0)
+	data, err := client.AppDetails(context.Background(), 312520, "schinese", "cn", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, _ := ParseAppDetail(data, "[]")
+	if record.Language != "简体中文, 英语" {
+		t.Fatalf("Language = %q", record.Language)
+	}
+}
+
+// Steam 字段类型不稳定时按零值处理, 超长字段按字符截断
+func TestParseAppDetailLooseTypes(t *testing.T) {
+	developer := strings.Repeat("福狼工作室", 30)
+	data := gjson.Parse(`{
+		"required_age": 18,
+		"pc_requirements": [],
+		"release_date": {"coming_soon": true, "date": "即将推出"},
+		"developers": ["` + developer + `"],
+		"platforms": {"linux": true}
+	}`)
+	record, snapshot := ParseAppDetail(data, "[]")
+
+	if snapshot.RequiredAge != "18" {
+		t.Errorf("RequiredAge = %q, want 18", snapshot.RequiredAge)
+	}
+	if snapshot.PcRequirements.Minimum != "" || snapshot.PcRequirements.Recommended != "" {
+		t.Errorf("PcRequirements = %+v, want empty", snapshot.PcRequirements)
+	}
+	if !record.ComingSoon || record.ReleaseDate != "即将推出" {
+		t.Errorf("release = %v %q", record.ComingSoon, record.ReleaseDate)
+	}
+	if record.Final != 0 || snapshot.Price.Currency != "" {
+		t.Errorf("price = %+v, want zero", snapshot.Price)
+	}
+	if record.Platform != "linux" {
+		t.Errorf("Platform = %q", record.Platform)
+	}
+	if n := utf8.RuneCountInString(record.Developer); n != 100 || !utf8.ValidString(record.Developer) {
+		t.Errorf("Developer has %d runes, want 100 valid runes", n)
+	}
+}
+
+func TestParseNews(t *testing.T) {
+	client, _ := newStubClient(t, fixtureDir, 0, 0)
+	appNews, err := client.News(context.Background(), 312520, "english", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	news := ParseNews(appNews)
+	if len(news) != 2 {
+		t.Fatalf("news = %d, want 2", len(news))
+	}
+	first := news[0]
+	if first.Index != 1 || news[1].Index != 2 || first.Total != 2 {
+		t.Errorf("index = %d/%d total = %d", first.Index, news[1].Index, first.Total)
+	}
+	if first.GID != "5000000000003125200" || news[1].GID != "5000000000003125201" {
+		t.Errorf("gid = %q/%q", first.GID, news[1].GID)
+	}
+	if first.Headline != "Rain World update 1" || first.Author != "Akupara Games" {
+		t.Errorf("news = %+v", first)
+	}
+	if got := time.Time(first.PostTime); !got.Equal(time.Unix(1748736000, 0)) {
+		t.Errorf("PostTime = %v", got)
+	}
+}
+
+func TestPricePoint(t *testing.T) {
+	price := gjson.Parse(`{"currency":"CNY","initial":8000,"final":2000,"discount_percent":75}`)
+	point := PricePoint("cn", price)
+	if point.Region != "cn" || point.Currency != "CNY" || point.Initial != 8000 || point.Final != 2000 || point.Discount != 75 {
+		t.Fatalf("point = %+v", point)
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	cases := map[string]string{
+		"English<strong>*</strong>, Japanese<br><strong>*</strong>languages with full audio support": "English, Japanese",
+		"English, French":    "English, French",
+		"<strong>*</strong>": "",
+		"":                   "",
+	}
+	for input, want := range cases {
+		if got := languages(input); got != want {
+			t.Errorf("languages(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package stub
+
+/*
+ * @Desc: Steam 接口替身, 返回录制好的响应, 用于本地联调与采集验证
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// 目录结构:
+//
+//	<dir>/appdetails/<appid>_<l>.json  按语言录制的商店详情, 不存在时回退到 <appid>.json
+//	<dir>/players/<appid>.json         在线人数
+//	<dir>/news/<appid>.json            更新公告
+//
+// 找不到录制文件时返回 404; 文件名带 .status 后缀且内容为状态码时直接返回该状态码, 便于模拟限流
+const (
+	appDetailsPath  = "/api/appdetails"
+	playerCountPath = "/ISteamUserStats/GetNumberOfCurrentPlayers/v1/"
+	newsPath        = "/ISteamNews/GetNewsForApp/v2/"
+)
+
+// NewHandler 创建替身服务, store_url 与 api_url 均可指向该服务
+func NewHandler(dir string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(appDetailsPath, func(w http.ResponseWriter, r *http.Request) {
+		appid := r.URL.Query().Get("appids")
+		serve(w, filepath.Join(dir, "appdetails"), appid+"_"+r.URL.Query().Get("l"), appid)
+	})
+	mux.HandleFunc(playerCountPath, func(w http.ResponseWriter, r *http.Request) {
+		serve(w, filepath.Join(dir, "players"), r.URL.Query().Get("appid"))
+	})
+	mux.HandleFunc(newsPath, func(w http.ResponseWriter, r *http.Request) {
+		serve(w, filepath.Join(dir, "news"), r.URL.Query().Get("appid"))
+	})
+	return mux
+}
+
+// serve 依次尝试候选文件名并返回第一个存在的录制响应
+func serve(w http.ResponseWriter, dir string, names ...string) {
+	for _, name := range names {
+		if name == "" || filepath.Base(name) != name {
+			continue
+		}
+		if status, err := os.ReadFile(filepath.Join(dir, name+".status")); err == nil {
+			code, convErr := strconv.Atoi(strings.TrimSpace(string(status)))
+			if convErr != nil || code < 100 || code > 599 {
+				code = http.StatusInternalServerError
+			}
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, http.StatusText(code), code)
+			return
+		}
+		content, err := os.ReadFile(filepath.Join(dir, name+".json"))
+		if err != nil {
+			continue
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(content)
+		return
+	}
+	http.Error(w, "recorded response not found", http.StatusNotFound)
+}
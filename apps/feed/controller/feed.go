@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/feed/models"
+	"github.com/GoFurry/gofurry-game-backend/apps/feed/service"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/gofiber/fiber/v2"
+)
+
+type feedApi struct{}
+
+var FeedApi *feedApi
+
+func init() {
+	FeedApi = &feedApi{}
+}
+
+// @Summary 更新公告 RSS
+// @Schemes
+// @Description 最新的更新公告 RSS 2.0 订阅源, 传 id 时为单个游戏的公告, 支持 ETag/Last-Modified 条件请求
+// @Tags Feed
+// @Produce xml
+// @Param lang query string false "语言 zh/en, 默认 zh"
+// @Param id query string false "游戏id"
+// @Success 200 {string} string
+// @Router /feed/news.xml [Get]
+func (api *feedApi) NewsRSS(c *fiber.Ctx) error {
+	return api.newsFeed(c, models.FormatRSS)
+}
+
+// @Summary 更新公告 Atom
+// @Schemes
+// @Description 最新的更新公告 Atom 1.0 订阅源, 传 id 时为单个游戏的公告, 支持 ETag/Last-Modified 条件请求
+// @Tags Feed
+// @Produce xml
+// @Param lang query string false "语言 zh/en, 默认 zh"
+// @Param id query string false "游戏id"
+// @Success 200 {string} string
+// @Router /feed/news.atom [Get]
+func (api *feedApi) NewsAtom(c *fiber.Ctx) error {
+	return api.newsFeed(c, models.FormatAtom)
+}
+
+// @Summary 更新公告 JSON Feed
+// @Schemes
+// @Description 最新的更新公告 JSON Feed 1.1 订阅源, 传 id 时为单个游戏的公告, 支持 ETag/Last-Modified 条件请求
+// @Tags Feed
+// @Produce json
+// @Param lang query string false "语言 zh/en, 默认 zh"
+// @Param id query string false "游戏id"
+// @Success 200 {object} models.JSONFeed
+// @Router /feed/news.json [Get]
+func (api *feedApi) NewsJSON(c *fiber.Ctx) error {
+	return api.newsFeed(c, models.FormatJSON)
+}
+
+// @Summary 新游收录 RSS
+// @Schemes
+// @Description 最近收录游戏的 RSS 2.0 订阅源, 支持 ETag/Last-Modified 条件请求
+// @Tags Feed
+// @Produce xml
+// @Param lang query string false "语言 zh/en, 默认 zh"
+// @Success 200 {string} string
+// @Router /feed/games.xml [Get]
+func (api *feedApi) GamesRSS(c *fiber.Ctx) error {
+	data, err := service.GetFeedService().GetGamesFeed(c.Query("lang", "zh"))
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+	return send(c, data)
+}
+
+func (api *feedApi) newsFeed(c *fiber.Ctx, format string) error {
+	data, err := service.GetFeedService().GetNewsFeed(format, c.Query("lang", "zh"), c.Query("id"))
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+	return send(c, data)
+}
+
+// send 写入缓存相关响应头, 客户端缓存仍有效时返回 304
+func send(c *fiber.Ctx, data models.FeedVo) error {
+	c.Set(fiber.HeaderETag, data.ETag)
+	if data.LastModified > 0 {
+		c.Set(fiber.HeaderLastModified, time.Unix(data.LastModified, 0).UTC().Format(http.TimeFormat))
+	}
+	c.Set(fiber.HeaderCacheControl, "public, max-age=600")
+	if notModified(c, data) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	c.Set(fiber.HeaderContentType, data.ContentType)
+	return c.SendString(data.Body)
+}
+
+// notModified 按 RFC 9110, 存在 If-None-Match 时只比较 ETag, 否则比较 If-Modified-Since
+func notModified(c *fiber.Ctx, data models.FeedVo) bool {
+	if noneMatch := c.Get(fiber.HeaderIfNoneMatch); noneMatch != "" {
+		for _, tag := range strings.Split(noneMatch, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == data.ETag {
+				return true
+			}
+		}
+		return false
+	}
+	modifiedSince, err := http.ParseTime(c.Get(fiber.HeaderIfModifiedSince))
+	if err != nil || data.LastModified <= 0 {
+		return false
+	}
+	return data.LastModified <= modifiedSince.Unix()
+}
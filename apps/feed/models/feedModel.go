@@ -0,0 +1,150 @@
+package models
+
+/*
+ * @Desc: 订阅源
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// 订阅源格式
+const (
+	FormatRSS  = "rss"
+	FormatAtom = "atom"
+	FormatJSON = "json"
+)
+
+// FeedVo 生成好的订阅源, 整体缓存到 Redis
+type FeedVo struct {
+	Body         string `json:"body"`
+	ContentType  string `json:"content_type"`
+	ETag         string `json:"etag"`
+	LastModified int64  `json:"last_modified"` // 最新条目的时间戳(秒)
+}
+
+// Channel 与格式无关的订阅源内容
+type Channel struct {
+	Title       string
+	Description string
+	Link        string // 站点页面
+	SelfURL     string // 订阅源自身地址
+	Lang        string
+	Items       []Item
+}
+
+type Item struct {
+	ID        string
+	Title     string
+	Link      string
+	Summary   string // 纯文本摘要
+	Author    string
+	Image     string
+	Published time.Time
+}
+
+// RSS 2.0
+type RSS struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DcNS    string     `xml:"xmlns:dc,attr"`
+	Channel RSSChannel `xml:"channel"`
+}
+
+type RSSChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	AtomLink      RSSLink   `xml:"atom:link"`
+	Items         []RSSItem `xml:"item"`
+}
+
+type RSSLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type RSSItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        RSSGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Creator     string        `xml:"dc:creator,omitempty"`
+	Description string        `xml:"description"`
+	Enclosure   *RSSEnclosure `xml:"enclosure,omitempty"`
+}
+
+type RSSGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+// Atom 1.0
+type AtomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang     string      `xml:"xml:lang,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []AtomLink  `xml:"link"`
+	Entries  []AtomEntry `xml:"entry"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type AtomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Published string      `xml:"published"`
+	Links     []AtomLink  `xml:"link"`
+	Author    *AtomAuthor `xml:"author,omitempty"`
+	Summary   string      `xml:"summary"`
+}
+
+type AtomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// JSON Feed 1.1
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Language    string         `json:"language"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentText   string           `json:"content_text"`
+	Image         string           `json:"image,omitempty"`
+	DatePublished string           `json:"date_published"`
+	Authors       []JSONFeedAuthor `json:"authors,omitempty"`
+}
+
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/feed/models"
+	gd "github.com/GoFurry/gofurry-game-backend/apps/game/dao"
+	gm "github.com/GoFurry/gofurry-game-backend/apps/game/models"
+	gs "github.com/GoFurry/gofurry-game-backend/apps/game/service"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	cs "github.com/GoFurry/gofurry-game-backend/common/service"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+	"github.com/GoFurry/gofurry-game-backend/roof/env"
+	"github.com/bytedance/sonic"
+)
+
+type feedService struct{}
+
+var feedSingleton = new(feedService)
+
+func GetFeedService() *feedService { return feedSingleton }
+
+// Redis 定义, 全站订阅源由每小时任务重建, 单个游戏的订阅源按需生成
+const (
+	redisFeedPrefix     = "feed:"           // feed:<类型>-<语言>.<格式>
+	redisGameFeedPrefix = "feed:game-news:" // feed:game-news:<ID>-<语言>.<格式>
+	feedCacheTimeout    = 2 * time.Hour
+	feedItemNum         = 30
+	feedSummaryLen      = 300
+)
+
+var feedContentTypes = map[string]string{
+	models.FormatRSS:  "application/rss+xml; charset=utf-8",
+	models.FormatAtom: "application/atom+xml; charset=utf-8",
+	models.FormatJSON: "application/feed+json; charset=utf-8",
+}
+
+// 获取更新公告订阅源, gameID 为空时为全站公告
+func (s feedService) GetNewsFeed(format string, lang string, gameID string) (res models.FeedVo, err common.GFError) {
+	if lang != "zh" && lang != "en" {
+		return res, common.NewServiceError("lang 仅支持 zh/en")
+	}
+	if gameID == "" {
+		return s.cached(redisFeedPrefix+"news-"+lang+"."+format, func() (models.FeedVo, common.GFError) {
+			return buildNewsFeed(format, lang)
+		})
+	}
+	intID, parseErr := util.String2Int64(gameID)
+	if parseErr != nil {
+		return res, common.NewServiceError("Game ID 转换有误")
+	}
+	return s.cached(redisGameFeedPrefix+gameID+"-"+lang+"."+format, func() (models.FeedVo, common.GFError) {
+		return buildGameNewsFeed(format, lang, intID)
+	})
+}
+
+// 获取最新收录游戏订阅源
+func (s feedService) GetGamesFeed(lang string) (res models.FeedVo, err common.GFError) {
+	if lang != "zh" && lang != "en" {
+		return res, common.NewServiceError("lang 仅支持 zh/en")
+	}
+	return s.cached(redisFeedPrefix+"games-"+lang+"."+models.FormatRSS, func() (models.FeedVo, common.GFError) {
+		return buildGamesFeed(lang)
+	})
+}
+
+// UpdateFeedCache 重建全站订阅源缓存, 单个游戏的订阅源直接清除
+func (s feedService) UpdateFeedCache() {
+	if err := cs.DelByPrefix(redisGameFeedPrefix); err != nil {
+		log.Error("UpdateFeedCache DelByPrefix err: ", err.GetMsg())
+	}
+	for _, lang := range []string{"zh", "en"} {
+		for _, format := range []string{models.FormatRSS, models.FormatAtom, models.FormatJSON} {
+			if feed, err := buildNewsFeed(format, lang); err != nil {
+				log.Error("UpdateFeedCache news ", lang, ".", format, " err: ", err.GetMsg())
+			} else {
+				saveFeed(redisFeedPrefix+"news-"+lang+"."+format, feed)
+			}
+		}
+		if feed, err := buildGamesFeed(lang); err != nil {
+			log.Error("UpdateFeedCache games ", lang, " err: ", err.GetMsg())
+		} else {
+			saveFeed(redisFeedPrefix+"games-"+lang+"."+models.FormatRSS, feed)
+		}
+	}
+}
+
+// cached 优先读取缓存, 未命中时生成并写入缓存
+func (s feedService) cached(key string, build func() (models.FeedVo, common.GFError)) (res models.FeedVo, err common.GFError) {
+	if jsonStr, cacheErr := cs.GetString(key); cacheErr == nil && sonic.Unmarshal([]byte(jsonStr), &res) == nil {
+		return res, nil
+	}
+	if res, err = build(); err != nil {
+		return
+	}
+	saveFeed(key, res)
+	return res, nil
+}
+
+func saveFeed(key string, feed models.FeedVo) {
+	if jsonRecord, jsonErr := sonic.Marshal(feed); jsonErr == nil {
+		cs.SetExpire(key, string(jsonRecord), feedCacheTimeout)
+	}
+}
+
+func buildNewsFeed(format string, lang string) (res models.FeedVo, err common.GFError) {
+	news, err := recentNews(gm.NewsQuery{Lang: lang})
+	if err != nil {
+		return res, err
+	}
+	site := env.GetServerConfig().Site
+	channel := models.Channel{
+		Title:       pick(lang, site.Title, site.TitleEn) + pick(lang, " - 更新公告", " - Updates"),
+		Description: pick(lang, site.Description, site.DescriptionEn),
+		Link:        site.URL,
+		SelfURL:     feedURL("news", format, lang, ""),
+		Lang:        lang,
+		Items:       newsItems(news, true),
+	}
+	return render(format, channel)
+}
+
+func buildGameNewsFeed(format string, lang string, gameID int64) (res models.FeedVo, err common.GFError) {
+	game, err := gd.GetGameDao().GetGame(gameID)
+	if err != nil {
+		return res, err
+	}
+	if game.ID == 0 {
+		return res, common.NewServiceError("游戏不存在")
+	}
+	news, err := recentNews(gm.NewsQuery{GameIDs: []int64{gameID}, Lang: lang})
+	if err != nil {
+		return res, err
+	}
+
+	id := util.Int642String(gameID)
+	channel := models.Channel{
+		Title:       pick(lang, game.Name, game.NameEn) + pick(lang, " - 更新公告", " - Updates"),
+		Description: pick(lang, game.Info, game.InfoEn),
+		Link:        GameURL(lang, id),
+		SelfURL:     feedURL("news", format, lang, id),
+		Lang:        lang,
+		Items:       newsItems(news, false),
+	}
+	return render(format, channel)
+}
+
+// recentNews 查询最新的 feedItemNum 条公告, 包含完整内容, 没有公告时返回空列表
+func recentNews(query gm.NewsQuery) ([]gm.NewsModel, common.GFError) {
+	query.InitPageIfAbsent()
+	query.PageSize = feedItemNum
+	_, news, err := gd.GetGameDao().PageNews(query)
+	return news, err
+}
+
+// newsItems 将公告转换为订阅条目, withName 为 true 时标题带上游戏名称
+func newsItems(news []gm.NewsModel, withName bool) (items []models.Item) {
+	for _, v := range news {
+		title := v.Headline
+		if withName {
+			title = v.Name + ": " + v.Headline
+		}
+		items = append(items, models.Item{
+			ID:        "news-" + util.Int642String(v.ID),
+			Title:     title,
+			Link:      v.URL,
+			Summary:   gs.NewsSummary(gs.RenderNewsContent(v.Content), feedSummaryLen),
+			Author:    v.Author,
+			Image:     v.Header,
+			Published: v.PostTime.Time(),
+		})
+	}
+	return
+}
+
+func buildGamesFeed(lang string) (res models.FeedVo, err common.GFError) {
+	ids, err := gd.GetGameDao().GetRecentGame(feedItemNum)
+	if err != nil {
+		return res, err
+	}
+	games, err := gd.GetGameDao().GetGameByIDs(ids)
+	if err != nil {
+		return res, err
+	}
+	site := env.GetServerConfig().Site
+	channel := models.Channel{
+		Title:       pick(lang, site.Title, site.TitleEn) + pick(lang, " - 新游收录", " - New Games"),
+		Description: pick(lang, site.Description, site.DescriptionEn),
+		Link:        site.URL,
+		SelfURL:     feedURL("games", models.FormatRSS, lang, ""),
+		Lang:        lang,
+	}
+	for _, v := range games {
+		id := util.Int642String(v.ID)
+		channel.Items = append(channel.Items, models.Item{
+			ID:        "game-" + id,
+			Title:     pick(lang, v.Name, v.NameEn),
+			Link:      GameURL(lang, id),
+			Summary:   pick(lang, v.Info, v.InfoEn),
+			Image:     v.Header,
+			Published: v.CreateTime.Time(),
+		})
+	}
+	return render(models.FormatRSS, channel)
+}
+
+// render 按格式生成订阅源, ETag 与 Last-Modified 只取决于内容, 数据不变时重建结果不变
+func render(format string, channel models.Channel) (res models.FeedVo, err common.GFError) {
+	var updated time.Time
+	for _, v := range channel.Items {
+		if v.Published.After(updated) {
+			updated = v.Published
+		}
+	}
+
+	var body []byte
+	var encodeErr error
+	switch format {
+	case models.FormatRSS:
+		body, encodeErr = xml.MarshalIndent(toRSS(channel, updated), "", "  ")
+		body = append([]byte(xml.Header), body...)
+	case models.FormatAtom:
+		body, encodeErr = xml.MarshalIndent(toAtom(channel, updated), "", "  ")
+		body = append([]byte(xml.Header), body...)
+	case models.FormatJSON:
+		body, encodeErr = sonic.Marshal(toJSONFeed(channel))
+	default:
+		return res, common.NewServiceError("不支持的订阅源格式")
+	}
+	if encodeErr != nil {
+		log.Error("render feed err: ", encodeErr)
+		return res, common.NewServiceError("生成订阅源失败")
+	}
+
+	sum := sha1.Sum(body)
+	res.Body = string(body)
+	res.ContentType = feedContentTypes[format]
+	res.ETag = `"` + hex.EncodeToString(sum[:10]) + `"`
+	if !updated.IsZero() {
+		res.LastModified = updated.Unix()
+	}
+	return res, nil
+}
+
+func toRSS(channel models.Channel, updated time.Time) models.RSS {
+	rss := models.RSS{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DcNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: models.RSSChannel{
+			Title:       channel.Title,
+			Link:        channel.Link,
+			Description: channel.Description,
+			Language:    pick(channel.Lang, "zh-cn", "en"),
+			AtomLink:    models.RSSLink{Href: channel.SelfURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	if !updated.IsZero() {
+		rss.Channel.LastBuildDate = updated.Format(time.RFC1123Z)
+	}
+	for _, v := range channel.Items {
+		item := models.RSSItem{
+			Title:       v.Title,
+			Link:        v.Link,
+			GUID:        models.RSSGUID{IsPermaLink: "false", Value: v.ID},
+			PubDate:     v.Published.Format(time.RFC1123Z),
+			Creator:     v.Author,
+			Description: v.Summary,
+		}
+		if v.Image != "" {
+			item.Enclosure = &models.RSSEnclosure{URL: v.Image, Type: imageType(v.Image), Length: "0"}
+		}
+		rss.Channel.Items = append(rss.Channel.Items, item)
+	}
+	return rss
+}
+
+func toAtom(channel models.Channel, updated time.Time) models.AtomFeed {
+	if updated.IsZero() {
+		updated = time.Unix(0, 0)
+	}
+	feed := models.AtomFeed{
+		Lang:     pick(channel.Lang, "zh-CN", "en"),
+		ID:       channel.SelfURL,
+		Title:    channel.Title,
+		Subtitle: channel.Description,
+		Updated:  updated.Format(time.RFC3339),
+		Links: []models.AtomLink{
+			{Href: channel.SelfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: channel.Link, Rel: "alternate", Type: "text/html"},
+		},
+	}
+	for _, v := range channel.Items {
+		entry := models.AtomEntry{
+			ID:        "tag:" + hostOf(channel.Link) + ",2025:" + v.ID,
+			Title:     v.Title,
+			Updated:   v.Published.Format(time.RFC3339),
+			Published: v.Published.Format(time.RFC3339),
+			Links:     []models.AtomLink{{Href: v.Link, Rel: "alternate", Type: "text/html"}},
+			Summary:   v.Summary,
+		}
+		if v.Author != "" {
+			entry.Author = &models.AtomAuthor{Name: v.Author}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+func toJSONFeed(channel models.Channel) models.JSONFeed {
+	feed := models.JSONFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       channel.Title,
+		HomePageURL: channel.Link,
+		FeedURL:     channel.SelfURL,
+		Description: channel.Description,
+		Language:    pick(channel.Lang, "zh-CN", "en"),
+		Items:       make([]models.JSONFeedItem, 0, len(channel.Items)),
+	}
+	for _, v := range channel.Items {
+		item := models.JSONFeedItem{
+			ID:            v.ID,
+			URL:           v.Link,
+			Title:         v.Title,
+			ContentText:   v.Summary,
+			Image:         v.Image,
+			DatePublished: v.Published.Format(time.RFC3339),
+		}
+		if v.Author != "" {
+			item.Authors = []models.JSONFeedAuthor{{Name: v.Author}}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed
+}
+
+// GameURL 按 site.game_path 生成游戏页面地址
+func GameURL(lang string, id string) string {
+	site := env.GetServerConfig().Site
+	path := site.GamePath
+	if path == "" {
+		path = "/game/{id}"
+	}
+	return site.PageURL(path, lang, id)
+}
+
+// feedURL 订阅源自身地址
+func feedURL(kind string, format string, lang string, gameID string) string {
+	ext := map[string]string{models.FormatRSS: ".xml", models.FormatAtom: ".atom", models.FormatJSON: ".json"}[format]
+	query := url.Values{}
+	query.Set("lang", lang)
+	if gameID != "" {
+		query.Set("id", gameID)
+	}
+	return strings.TrimRight(env.GetServerConfig().Site.APIURL, "/") + "/feed/" + kind + ext + "?" + query.Encode()
+}
+
+func hostOf(raw string) string {
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		return u.Hostname()
+	}
+	return "localhost"
+}
+
+func imageType(src string) string {
+	switch {
+	case strings.Contains(src, ".png"):
+		return "image/png"
+	case strings.Contains(src, ".webp"):
+		return "image/webp"
+	default:
+		return "image/jpeg"
+	}
+}
+
+func pick(lang string, zh string, en string) string {
+	if lang == "en" {
+		return en
+	}
+	return zh
+}
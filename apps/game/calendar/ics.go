@@ -0,0 +1,37 @@
+package calendar
+
+/*
+ * @Desc: iCalendar 文本输出
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const icsLineLimit = 75 // RFC 5545 每行最多 75 字节, 超出需折行
+
+// EscapeText 转义 TEXT 类型的值
+func EscapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "").Replace(s)
+}
+
+// WriteLine 按 75 字节折行写入一行, 不拆分多字节字符
+func WriteLine(b *strings.Builder, line string) {
+	limit := icsLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// 续行以空格开头, 占用 1 字节
+		limit = icsLineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,72 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	cases := map[string]string{
+		`a\b`:            `a\\b`,
+		"a;b,c":          `a\;b\,c`,
+		"line1\r\nline2": `line1\nline2`,
+		"line1\nline2\r": `line1\nline2`,
+		"雨世界":            "雨世界",
+	}
+	for input, want := range cases {
+		if got := EscapeText(input); got != want {
+			t.Errorf("EscapeText(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestWriteLine(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		lines int
+	}{
+		{"短行", "SUMMARY:Rain World", 1},
+		{"正好 75 字节", "SUMMARY:" + strings.Repeat("a", 67), 1},
+		{"76 字节", "SUMMARY:" + strings.Repeat("a", 68), 2},
+		// 3 字节的汉字在 75 字节处不能拆开
+		{"汉字", "SUMMARY:" + strings.Repeat("雨世界", 30), 4},
+		// 4 字节的 emoji 与汉字混排
+		{"emoji 混排", "DESCRIPTION:" + strings.Repeat("🐺狼a", 40), 5},
+		{"空行", "", 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var b strings.Builder
+			WriteLine(&b, c.input)
+			out := b.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output %q does not end with CRLF", out)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			if len(lines) != c.lines {
+				t.Fatalf("lines = %d, want %d: %q", len(lines), c.lines, lines)
+			}
+			var unfolded strings.Builder
+			for i, line := range lines {
+				if len(line) > icsLineLimit {
+					t.Errorf("line %d has %d bytes", i, len(line))
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a multi-byte character: %q", i, line)
+				}
+				if i > 0 {
+					if !strings.HasPrefix(line, " ") {
+						t.Fatalf("continuation line %d does not start with a space: %q", i, line)
+					}
+					line = line[1:]
+				}
+				unfolded.WriteString(line)
+			}
+			if unfolded.String() != c.input {
+				t.Fatalf("unfolded = %q, want %q", unfolded.String(), c.input)
+			}
+		})
+	}
+}
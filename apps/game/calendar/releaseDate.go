@@ -0,0 +1,125 @@
+package calendar
+
+/*
+ * @Desc: 发行日期解析
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/game/models"
+)
+
+// 发行日期精度, 非 day 的日期在日历中按整个区间处理
+const (
+	PrecisionDay     = "day"
+	PrecisionMonth   = "month"
+	PrecisionQuarter = "quarter"
+	PrecisionYear    = "year"
+	PrecisionUnknown = "unknown" // 即将推出、待定等无法解析的文本
+)
+
+var (
+	// 2026.03.05 / 2026-03-05 / 2026/3/5 / 2026 年 3 月 5 日
+	ymdPattern = regexp.MustCompile(`^(\d{4})\s*[.\-/年]\s*(\d{1,2})\s*[.\-/月]\s*(\d{1,2})\s*日?$`)
+	// 2026.03 / 2026-03 / 2026 年 3 月
+	ymPattern = regexp.MustCompile(`^(\d{4})\s*[.\-/年]\s*(\d{1,2})\s*月?$`)
+	// 5 Mar, 2026 / Mar 5, 2026 / March 5 2026
+	dmyPattern = regexp.MustCompile(`(?i)^(\d{1,2})\s+([a-z]+)\.?,?\s+(\d{4})$`)
+	mdyPattern = regexp.MustCompile(`(?i)^([a-z]+)\.?\s+(\d{1,2}),?\s+(\d{4})$`)
+	// March 2026 / Mar, 2026
+	myPattern = regexp.MustCompile(`(?i)^([a-z]+)\.?,?\s+(\d{4})$`)
+	// Q3 2026 / 2026 Q3 / 2026 年第 3 季度 / 2026 年第三季度
+	quarterPattern   = regexp.MustCompile(`(?i)^q([1-4])\s*,?\s*(\d{4})$`)
+	yearQuarPattern  = regexp.MustCompile(`(?i)^(\d{4})\s*,?\s*q([1-4])$`)
+	zhQuarterPattern = regexp.MustCompile(`^(\d{4})\s*年?\s*第\s*([1-4一二三四])\s*季度$`)
+	// 2026 / 2026 年
+	yearPattern = regexp.MustCompile(`^(\d{4})\s*年?$`)
+
+	zhDigits = map[string]int{"一": 1, "二": 2, "三": 3, "四": 4}
+)
+
+// ParseReleaseDate 解析发行日期文本, 返回 [Start, End) 区间与精度
+// 支持后台录入的 YYYY.MM.DD 与 Steam 中英文商店的日期写法, 无法解析时精度为 unknown
+func ParseReleaseDate(raw string) (res models.ReleaseDateModel) {
+	text := strings.TrimSpace(raw)
+	res.Precision = PrecisionUnknown
+
+	switch {
+	case ymdPattern.MatchString(text):
+		m := ymdPattern.FindStringSubmatch(text)
+		res = dayOf(atoi(m[1]), atoi(m[2]), atoi(m[3]))
+	case dmyPattern.MatchString(text):
+		m := dmyPattern.FindStringSubmatch(text)
+		res = dayOf(atoi(m[3]), monthOf(m[2]), atoi(m[1]))
+	case mdyPattern.MatchString(text):
+		m := mdyPattern.FindStringSubmatch(text)
+		res = dayOf(atoi(m[3]), monthOf(m[1]), atoi(m[2]))
+	case ymPattern.MatchString(text):
+		m := ymPattern.FindStringSubmatch(text)
+		res = rangeOf(atoi(m[1]), atoi(m[2]), 1, PrecisionMonth)
+	case myPattern.MatchString(text):
+		m := myPattern.FindStringSubmatch(text)
+		res = rangeOf(atoi(m[2]), monthOf(m[1]), 1, PrecisionMonth)
+	case quarterPattern.MatchString(text):
+		m := quarterPattern.FindStringSubmatch(text)
+		res = rangeOf(atoi(m[2]), atoi(m[1])*3-2, 3, PrecisionQuarter)
+	case yearQuarPattern.MatchString(text):
+		m := yearQuarPattern.FindStringSubmatch(text)
+		res = rangeOf(atoi(m[1]), atoi(m[2])*3-2, 3, PrecisionQuarter)
+	case zhQuarterPattern.MatchString(text):
+		m := zhQuarterPattern.FindStringSubmatch(text)
+		quarter, ok := zhDigits[m[2]]
+		if !ok {
+			quarter = atoi(m[2])
+		}
+		res = rangeOf(atoi(m[1]), quarter*3-2, 3, PrecisionQuarter)
+	case yearPattern.MatchString(text):
+		m := yearPattern.FindStringSubmatch(text)
+		res = rangeOf(atoi(m[1]), 1, 12, PrecisionYear)
+	}
+	return res
+}
+
+// dayOf 精确到天的日期, 日期不合法时精度为 unknown
+func dayOf(year int, month int, day int) models.ReleaseDateModel {
+	start := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
+	if month < 1 || month > 12 || day < 1 || start.Day() != day {
+		return models.ReleaseDateModel{Precision: PrecisionUnknown}
+	}
+	return models.ReleaseDateModel{Start: start, End: start.AddDate(0, 0, 1), Precision: PrecisionDay}
+}
+
+// rangeOf 从 month 月起共 months 个月的区间
+func rangeOf(year int, month int, months int, precision string) models.ReleaseDateModel {
+	if month < 1 || month > 12 {
+		return models.ReleaseDateModel{Precision: PrecisionUnknown}
+	}
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	return models.ReleaseDateModel{Start: start, End: start.AddDate(0, months, 0), Precision: precision}
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// monthOf 解析英文月份全称或缩写, 无法识别时返回 0
+func monthOf(name string) int {
+	name = strings.ToLower(name)
+	if len(name) < 3 {
+		return 0
+	}
+	for m := time.January; m <= time.December; m++ {
+		full := strings.ToLower(m.String())
+		if name == full || name == full[:3] || name == "sept" && m == time.September {
+			return int(m)
+		}
+	}
+	return 0
+}
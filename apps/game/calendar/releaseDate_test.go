@@ -0,0 +1,66 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReleaseDate(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.Local) }
+	cases := []struct {
+		name      string
+		input     string
+		precision string
+		start     time.Time
+		end       time.Time
+	}{
+		// 精确到天
+		{"后台录入", "2026.03.05", PrecisionDay, day(2026, 3, 5), day(2026, 3, 6)},
+		{"短横线", "2026-03-05", PrecisionDay, day(2026, 3, 5), day(2026, 3, 6)},
+		{"斜线不补零", "2026/3/5", PrecisionDay, day(2026, 3, 5), day(2026, 3, 6)},
+		{"年月日", "2026 年 3 月 5 日", PrecisionDay, day(2026, 3, 5), day(2026, 3, 6)},
+		{"年月日无空格", "2026年12月31日", PrecisionDay, day(2026, 12, 31), day(2027, 1, 1)},
+		{"英文月日年", "Mar 5, 2026", PrecisionDay, day(2026, 3, 5), day(2026, 3, 6)},
+		{"英文日月年", "5 Mar, 2026", PrecisionDay, day(2026, 3, 5), day(2026, 3, 6)},
+		{"英文全称", "September 30 2026", PrecisionDay, day(2026, 9, 30), day(2026, 10, 1)},
+		{"Sept 缩写", "Sept. 1, 2026", PrecisionDay, day(2026, 9, 1), day(2026, 9, 2)},
+		{"闰日", "2028.02.29", PrecisionDay, day(2028, 2, 29), day(2028, 3, 1)},
+		{"首尾空白", "  2026.03.05\t", PrecisionDay, day(2026, 3, 5), day(2026, 3, 6)},
+
+		// 月份与年份
+		{"年月", "2026.03", PrecisionMonth, day(2026, 3, 1), day(2026, 4, 1)},
+		{"中文年月", "2026 年 12 月", PrecisionMonth, day(2026, 12, 1), day(2027, 1, 1)},
+		{"英文月年", "March 2026", PrecisionMonth, day(2026, 3, 1), day(2026, 4, 1)},
+		{"英文月年逗号", "Mar, 2026", PrecisionMonth, day(2026, 3, 1), day(2026, 4, 1)},
+		{"年份", "2026", PrecisionYear, day(2026, 1, 1), day(2027, 1, 1)},
+		{"中文年份", "2026 年", PrecisionYear, day(2026, 1, 1), day(2027, 1, 1)},
+
+		// 季度
+		{"Q3 在前", "Q3 2026", PrecisionQuarter, day(2026, 7, 1), day(2026, 10, 1)},
+		{"Q4 在后", "2026 q4", PrecisionQuarter, day(2026, 10, 1), day(2027, 1, 1)},
+		{"第三季度", "2026 年第三季度", PrecisionQuarter, day(2026, 7, 1), day(2026, 10, 1)},
+		{"第 1 季度", "2026年第 1 季度", PrecisionQuarter, day(2026, 1, 1), day(2026, 4, 1)},
+
+		// 无法解析或日期不合法
+		{"2 月 30 日", "2026.02.30", PrecisionUnknown, time.Time{}, time.Time{}},
+		{"非闰年 2 月 29 日", "Feb 29, 2025", PrecisionUnknown, time.Time{}, time.Time{}},
+		{"4 月 31 日", "31 Apr, 2026", PrecisionUnknown, time.Time{}, time.Time{}},
+		{"13 月", "2026.13.01", PrecisionUnknown, time.Time{}, time.Time{}},
+		{"0 日", "2026.03.00", PrecisionUnknown, time.Time{}, time.Time{}},
+		{"13 月无日", "2026-13", PrecisionUnknown, time.Time{}, time.Time{}},
+		{"未知月份", "Foo 5, 2026", PrecisionUnknown, time.Time{}, time.Time{}},
+		{"Q5", "Q5 2026", PrecisionUnknown, time.Time{}, time.Time{}},
+		{"即将推出", "即将推出", PrecisionUnknown, time.Time{}, time.Time{}},
+		{"To be announced", "To be announced", PrecisionUnknown, time.Time{}, time.Time{}},
+		{"空字符串", "", PrecisionUnknown, time.Time{}, time.Time{}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := ParseReleaseDate(c.input)
+			if got.Precision != c.precision || !got.Start.Equal(c.start) || !got.End.Equal(c.end) {
+				t.Fatalf("ParseReleaseDate(%q) = %s [%v, %v), want %s [%v, %v)",
+					c.input, got.Precision, got.Start, got.End, c.precision, c.start, c.end)
+			}
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package controller
 
 import (
+	"github.com/GoFurry/gofurry-game-backend/apps/game/models"
 	"github.com/GoFurry/gofurry-game-backend/apps/game/service"
+	rs "github.com/GoFurry/gofurry-game-backend/apps/recommend/service"
 	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/abstract"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -86,6 +89,76 @@ func (api *gameApi) GetUpdateNews(c *fiber.Ctx) error {
 	return common.NewResponse(c).SuccessWithData(data)
 }
 
+// @Summary 获取标签树
+// @Schemes
+// @Description 按父标签组织的标签树, 含子树内去重后的游戏数, 并列出父标签缺失或成环的标签
+// @Tags Game
+// @Accept json
+// @Produce json
+// @Param lang query string false "语言 zh/en, 默认 zh"
+// @Success 200 {object} models.TagTreeVo
+// @Router /api/game/tag/tree [Get]
+func (api *gameApi) GetTagTree(c *fiber.Ctx) error {
+	lang := c.Query("lang", "zh")
+	data, err := service.GetGameTagService().GetTagTree(lang)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 获取相关标签
+// @Schemes
+// @Description 按共现程度获取与标签相关的标签, 共现统计每小时更新
+// @Tags Game
+// @Accept json
+// @Produce json
+// @Param id query string true "标签id"
+// @Param lang query string false "语言 zh/en, 默认 zh"
+// @Param limit query string false "数量, 默认 10, 最多 50"
+// @Param metric query string false "排序方式 jaccard/npmi, 默认 jaccard"
+// @Success 200 {object} []rm.TagRelatedVo
+// @Router /api/game/tag/related [Get]
+func (api *gameApi) GetRelatedTags(c *fiber.Ctx) error {
+	lang := c.Query("lang", "zh")
+	data, err := rs.GetTagGraphService().GetRelatedTags(c.Query("id"), lang, c.Query("limit"), c.Query("metric"))
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 获取标签共现图
+// @Schemes
+// @Description 标签共现图, 节点为标签, 边权重为 Jaccard 系数, format=graphml 时返回 GraphML
+// @Tags Game
+// @Accept json
+// @Produce json,xml
+// @Param lang query string false "语言 zh/en, 默认 zh"
+// @Param format query string false "格式 json/graphml, 默认 json"
+// @Success 200 {object} rm.TagGraphVo
+// @Router /api/game/tag/graph [Get]
+func (api *gameApi) GetTagGraph(c *fiber.Ctx) error {
+	lang := c.Query("lang", "zh")
+	if c.Query("format") == "graphml" {
+		data, err := rs.GetTagGraphService().GetTagGraphML(lang)
+		if err != nil {
+			return common.NewResponse(c).Error(err.GetMsg())
+		}
+		c.Set(fiber.HeaderContentType, "application/graphml+xml; charset=utf-8")
+		return c.SendString(data)
+	}
+
+	data, err := rs.GetTagGraphService().GetTagGraph(lang)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
 // @Summary 获取标签列表
 // @Schemes
 // @Description 获取标签列表
@@ -163,3 +236,281 @@ func (api *gameApi) GetGameCreator(c *fiber.Ctx) error {
 
 	return common.NewResponse(c).SuccessWithData(data)
 }
+
+// @Summary 获取游戏内容详情
+// @Schemes
+// @Description 获取游戏ID对应的内容详情, 缺失语言时回退到另一种语言
+// @Tags Game
+// @Accept json
+// @Produce json
+// @Param id query string true "游戏id"
+// @Param lang query string true "语言"
+// @Success 200 {object} models.GameIntroVo
+// @Router /api/game/info/intro [Get]
+func (api *gameApi) GetGameIntro(c *fiber.Ctx) error {
+	id := c.Query("id", "0")
+	lang := c.Query("lang", "zh")
+	data, err := service.GetGameIntroService().GetGameIntro(id, lang)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 批量获取游戏内容详情
+// @Schemes
+// @Description 批量获取游戏内容详情, 缺失语言时回退到另一种语言
+// @Tags Game
+// @Accept json
+// @Produce json
+// @Param ids query string true "游戏id, 逗号分隔"
+// @Param lang query string true "语言"
+// @Success 200 {object} []models.GameIntroVo
+// @Router /api/game/info/intro/batch [Get]
+func (api *gameApi) BatchGetGameIntro(c *fiber.Ctx) error {
+	ids := c.Query("ids")
+	lang := c.Query("lang", "zh")
+	data, err := service.GetGameIntroService().BatchGetGameIntro(ids, lang)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 保存游戏内容详情
+// @Schemes
+// @Description 保存游戏内容详情, HTML 按白名单过滤后入库
+// @Tags Game
+// @Accept json
+// @Produce json
+// @Param body body models.GameIntroSaveRequest true "请求body"
+// @Success 200 {object} common.ResultData
+// @Router /api/game/info/intro [Post]
+func (api *gameApi) SaveGameIntro(c *fiber.Ctx) error {
+	req := models.GameIntroSaveRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	err := service.GetGameIntroService().SaveGameIntro(req)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).Success()
+}
+
+// @Summary 删除游戏内容详情
+// @Schemes
+// @Description 删除游戏ID对应的所有语言的内容详情
+// @Tags Game
+// @Accept json
+// @Produce json
+// @Param id query string true "游戏id"
+// @Success 200 {object} common.ResultData
+// @Router /api/game/info/intro [Delete]
+func (api *gameApi) DeleteGameIntro(c *fiber.Ctx) error {
+	id := c.Query("id", "0")
+	err := service.GetGameIntroService().DeleteGameIntro(id)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).Success()
+}
+
+// @Summary 获取游戏价格历史
+// @Schemes
+// @Description 获取游戏在指定地区的价格走势、史低史高与是否史低
+// @Tags Game
+// @Accept json
+// @Produce json
+// @Param id query string true "游戏id"
+// @Param region query string false "地区代码, 默认 cn"
+// @Success 200 {object} models.PriceHistoryVo
+// @Router /api/game/price/history [Get]
+func (api *gameApi) GetPriceHistory(c *fiber.Ctx) error {
+	id := c.Query("id", "0")
+	region := c.Query("region", models.PriceRegionChina)
+	data, err := service.GetGamePriceService().GetPriceHistory(id, region)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 获取游戏各地区价格统计
+// @Schemes
+// @Description 获取游戏在各地区的当前价格、史低史高与是否史低
+// @Tags Game
+// @Accept json
+// @Produce json
+// @Param id query string true "游戏id"
+// @Success 200 {object} []models.PriceStatVo
+// @Router /api/game/price/stat [Get]
+func (api *gameApi) GetPriceStat(c *fiber.Ctx) error {
+	id := c.Query("id", "0")
+	data, err := service.GetGamePriceService().GetPriceStat(id)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 获取游戏在线人数走势
+// @Schemes
+// @Description 按时间桶返回在线人数的最低、平均、最高值, 30d/all 按天汇总
+// @Tags Game
+// @Accept json
+// @Produce json
+// @Param id query string true "游戏id"
+// @Param range query string false "时间范围 24h/7d/30d/all, 默认 24h"
+// @Success 200 {object} models.PlayerCountHistoryVo
+// @Router /api/game/online/history [Get]
+func (api *gameApi) GetOnlineHistory(c *fiber.Ctx) error {
+	id := c.Query("id", "0")
+	rangeKey := c.Query("range", "24h")
+	data, err := service.GetGameOnlineService().GetOnlineHistory(id, rangeKey)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 获取在线人数增长最快的游戏
+// @Schemes
+// @Description 比较最近窗口与上一个等长窗口的平均在线人数, 按增长率排序
+// @Tags Game
+// @Accept json
+// @Produce json
+// @Param window query string false "窗口 6h/24h/7d, 默认 24h"
+// @Param num query string false "请求数量, 默认 10, 最多 50"
+// @Success 200 {object} []models.PlayerTrendingVo
+// @Router /api/game/online/trending [Get]
+func (api *gameApi) GetOnlineTrending(c *fiber.Ctx) error {
+	window := c.Query("window", "24h")
+	num := c.Query("num", "10")
+	data, err := service.GetGameOnlineService().GetOnlineTrending(window, num)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 分页获取游戏更新公告
+// @Schemes
+// @Description 按发布时间倒序分页获取单个游戏的更新公告, 摘要为纯文本
+// @Tags Game
+// @Accept json
+// @Produce json
+// @Param id query string true "游戏id"
+// @Param lang query string false "语言 zh/en, 默认 zh"
+// @Param page query string false "页码, 默认 1"
+// @Param size query string false "每页数量, 默认 10, 最多 50"
+// @Success 200 {object} models.PageResponse
+// @Router /api/game/news [Get]
+func (api *gameApi) PageGameNews(c *fiber.Ctx) error {
+	id := c.Query("id", "0")
+	lang := c.Query("lang", "zh")
+	data, err := service.GetGameNewsService().PageGameNews(id, lang, c.Query("page"), c.Query("size"))
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 获取更新公告详情
+// @Schemes
+// @Description 获取更新公告全文, BBCode/Markdown 转换为 HTML 后按白名单过滤
+// @Tags Game
+// @Accept json
+// @Produce json
+// @Param id query string true "公告id"
+// @Success 200 {object} models.NewsDetailVo
+// @Router /api/game/news/detail [Get]
+func (api *gameApi) GetNewsDetail(c *fiber.Ctx) error {
+	id := c.Query("id", "0")
+	data, err := service.GetGameNewsService().GetNewsDetail(id)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 分页获取全站更新公告
+// @Schemes
+// @Description 按发布时间倒序分页获取所有游戏的更新公告, 可按游戏与日期范围过滤
+// @Tags Game
+// @Accept json
+// @Produce json
+// @Param lang query string false "语言 zh/en, 默认 zh"
+// @Param game_id query string false "游戏id, 多个以逗号分隔"
+// @Param from query string false "开始日期 YYYY-MM-DD"
+// @Param to query string false "结束日期 YYYY-MM-DD, 包含当天"
+// @Param page query string false "页码, 默认 1"
+// @Param size query string false "每页数量, 默认 10, 最多 50"
+// @Success 200 {object} models.PageResponse
+// @Router /api/game/news/feed [Get]
+func (api *gameApi) PageNewsFeed(c *fiber.Ctx) error {
+	lang := c.Query("lang", "zh")
+	data, err := service.GetGameNewsService().PageNewsFeed(lang, c.Query("game_id"), c.Query("from"), c.Query("to"),
+		c.Query("page"), c.Query("size"))
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 获取发售日历
+// @Schemes
+// @Description 按发售日分组获取区间内发售的游戏, 只精确到月/季度/年的发售与日期待定的游戏单独列出
+// @Tags Game
+// @Accept json
+// @Produce json
+// @Param from query string false "开始日期 YYYY-MM-DD, 默认当天"
+// @Param to query string false "结束日期 YYYY-MM-DD, 包含当天, 默认开始日期后 90 天"
+// @Param lang query string false "语言 zh/en, 默认 zh"
+// @Param tag query string false "标签id"
+// @Success 200 {object} models.CalendarVo
+// @Router /api/game/calendar [Get]
+func (api *gameApi) GetCalendar(c *fiber.Ctx) error {
+	lang := c.Query("lang", "zh")
+	data, err := service.GetGameCalendarService().GetCalendar(c.Query("from"), c.Query("to"), lang, c.Query("tag"))
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 导出发售日历
+// @Schemes
+// @Description 导出近 30 天至未来一年精确到天的发售为 iCalendar, 可在日历应用中订阅
+// @Tags Game
+// @Produce plain
+// @Param lang query string false "语言 zh/en, 默认 zh"
+// @Param tag query string false "标签id"
+// @Success 200 {string} string
+// @Router /api/game/calendar.ics [Get]
+func (api *gameApi) GetCalendarICS(c *fiber.Ctx) error {
+	lang := c.Query("lang", "zh")
+	data, err := service.GetGameCalendarService().GetCalendarICS(lang, c.Query("tag"))
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `inline; filename="gofurry-calendar.ics"`)
+	return c.SendString(data)
+}
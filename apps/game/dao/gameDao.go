@@ -3,6 +3,7 @@ package dao
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/GoFurry/gofurry-game-backend/apps/game/models"
 	gm "github.com/GoFurry/gofurry-game-backend/apps/recommend/models"
@@ -23,7 +24,7 @@ type gameDao struct{ abstract.Dao }
 func GetGameDao() *gameDao { return newGameDao }
 
 func (dao gameDao) GetGame(id int64) (res models.GfgGame, err common.GFError) {
-	db := dao.Gm.Table(models.TableNameGfgGame).Where("id = ?", id)
+	db := dao.Gm.Table(models.TableNameGfgGame).Where("id = ? AND deleted IS NOT TRUE", id)
 	db.Take(&res)
 	if dbErr := db.Error; dbErr != nil {
 		return res, common.NewDaoError(dbErr.Error())
@@ -42,7 +43,7 @@ func (dao gameDao) GetGameRecord(id int64, lang string) (res models.GfgGameRecor
 
 func (dao gameDao) GetGameNews(id int64, lang string) (res []models.GfgGameNews, err common.GFError) {
 	db := dao.Gm.Table(models.TableNameGfgGameNews).Where("game_id = ? AND lang=?", id, lang)
-	db.Order("index ASC")
+	db.Order("post_time DESC, index ASC")
 	db.Find(&res)
 	if dbErr := db.Error; dbErr != nil {
 		return res, common.NewDaoError(dbErr.Error())
@@ -60,7 +61,7 @@ func (dao gameDao) GetGameTags(id int64, lang string) (res []models.TagVo, err c
 	}
 
 	db.Joins("JOIN gfg_tag t ON tm.tag_id = t.id")
-	db.Where("tm.game_id = ?", id)
+	db.Where("tm.game_id = ? AND tm.deleted IS NOT TRUE AND t.deleted IS NOT TRUE", id)
 	db.Order("t.id ASC")
 
 	if dbErr := db.Find(&res).Error; dbErr != nil {
@@ -115,7 +116,7 @@ func (dao gameDao) GetGameComment(id int64) (res models.GameRemarkVo, err common
 }
 
 func (dao gameDao) GetGameList(num int) (res []models.GfgGame, err common.GFError) {
-	db := dao.Gm.Table(models.TableNameGfgGame)
+	db := dao.Gm.Table(models.TableNameGfgGame).Where("deleted IS NOT TRUE")
 	db.Order("weight ASC").Limit(num)
 	db.Find(&res)
 	if dbErr := db.Error; dbErr != nil {
@@ -125,7 +126,7 @@ func (dao gameDao) GetGameList(num int) (res []models.GfgGame, err common.GFErro
 }
 
 func (dao gameDao) GetByNum(randomInt int) (res models.GfgGame, err common.GFError) {
-	db := dao.Gm.Table(models.TableNameGfgGame).Order("id DESC")
+	db := dao.Gm.Table(models.TableNameGfgGame).Where("deleted IS NOT TRUE").Order("id DESC")
 	db.Offset(randomInt).Limit(1)
 	db.Take(&res)
 	if dbErr := db.Error; dbErr != nil {
@@ -134,8 +135,16 @@ func (dao gameDao) GetByNum(randomInt int) (res models.GfgGame, err common.GFErr
 	return res, nil
 }
 
+func (dao gameDao) CountGame() (res int64, err common.GFError) {
+	db := dao.Gm.Table(models.TableNameGfgGame).Where("deleted IS NOT TRUE").Count(&res)
+	if dbErr := db.Error; dbErr != nil {
+		return res, common.NewDaoError(dbErr.Error())
+	}
+	return res, nil
+}
+
 func (dao gameDao) GetLatestGame(num int) (res []int64, err common.GFError) {
-	db := dao.Gm.Table(models.TableNameGfgGame).Select("id").Order("release_date DESC").Limit(num)
+	db := dao.Gm.Table(models.TableNameGfgGame).Select("id").Where("deleted IS NOT TRUE").Order("release_date DESC").Limit(num)
 	db.Find(&res)
 	if dbErr := db.Error; dbErr != nil {
 		return res, common.NewDaoError(dbErr.Error())
@@ -144,7 +153,7 @@ func (dao gameDao) GetLatestGame(num int) (res []int64, err common.GFError) {
 }
 
 func (dao gameDao) GetRecentGame(num int) (res []int64, err common.GFError) {
-	db := dao.Gm.Table(models.TableNameGfgGame).Select("id").Order("create_time DESC").Limit(num)
+	db := dao.Gm.Table(models.TableNameGfgGame).Select("id").Where("deleted IS NOT TRUE").Order("create_time DESC").Limit(num)
 	db.Find(&res)
 	if dbErr := db.Error; dbErr != nil {
 		return res, common.NewDaoError(dbErr.Error())
@@ -152,9 +161,32 @@ func (dao gameDao) GetRecentGame(num int) (res []int64, err common.GFError) {
 	return res, nil
 }
 
+// GetGameByIDs 按 ID 批量获取未删除的游戏, 结果顺序与 ids 一致
+func (dao gameDao) GetGameByIDs(ids []int64) (res []models.GfgGame, err common.GFError) {
+	if len(ids) == 0 {
+		return res, nil
+	}
+	var records []models.GfgGame
+	db := dao.Gm.Table(models.TableNameGfgGame).Where("id IN ? AND deleted IS NOT TRUE", ids)
+	if dbErr := db.Find(&records).Error; dbErr != nil {
+		return res, common.NewDaoError(dbErr.Error())
+	}
+	byID := make(map[int64]models.GfgGame, len(records))
+	for _, v := range records {
+		byID[v.ID] = v
+	}
+	for _, id := range ids {
+		if v, ok := byID[id]; ok {
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
+
 func (dao gameDao) GetFreeGame(num int) (res []int64, err common.GFError) {
 	db := dao.Gm.Table(models.TableNameGfgGameRecord).Select("game_id")
-	db.Where("lang=? AND initial=? AND final=?", "en", 0, 0).Limit(num)
+	db.Where("lang=? AND initial=? AND final=?", "en", 0, 0)
+	db.Where("game_id IN (?)", dao.Gm.Table(models.TableNameGfgGame).Select("id").Where("deleted IS NOT TRUE")).Limit(num)
 	db.Find(&res)
 	if dbErr := db.Error; dbErr != nil {
 		return res, common.NewDaoError(dbErr.Error())
@@ -189,6 +221,8 @@ func (dao gameDao) GetPlayerPeak(num int) (res []models.PlayerTopCountVo, err co
 			EXTRACT(EPOCH FROM latest_count.collect_time)::INT8 AS collect_time,
 			COALESCE(` + gameTable + `.header, '') AS header
 		`).
+		// 排除已删除的游戏
+		Where(gameTable + ".deleted IS NOT TRUE").
 		// 按game_id分组
 		Group(countTable + ".game_id, " + gameTable + ".name, " + gameTable + ".header, latest_count.count_recent, latest_count.collect_time").
 		// 按峰值降序排序
@@ -218,13 +252,20 @@ func (dao gameDao) GetTopPrice(num int) (res []models.TopPriceVo, err common.GFE
 
 	// 获取zh区记录
 	zhSubQuery := dao.Gm.Table(recordTable).
-		Select("game_id, final AS china_price").
+		Select("game_id, final AS china_price, discount AS china_discount").
 		Where("lang = ?", "zh")
 
+	// 各地区历史最低价
+	globalLowSubQuery := dao.lowestPriceSubQuery(models.PriceRegionGlobal)
+	chinaLowSubQuery := dao.lowestPriceSubQuery(models.PriceRegionChina)
+
 	// 关联en区、zh区、游戏表
 	db := dao.Gm.Table("(?) AS en_data", enSubQuery).
 		// 左关联zh区数据
 		Joins("LEFT JOIN (?) AS zh_data ON en_data.game_id = zh_data.game_id", zhSubQuery).
+		// 左关联史低数据
+		Joins("LEFT JOIN (?) AS global_low ON en_data.game_id = global_low.game_id", globalLowSubQuery).
+		Joins("LEFT JOIN (?) AS china_low ON en_data.game_id = china_low.game_id", chinaLowSubQuery).
 		// 左关联游戏表获取名称和封面图
 		Joins("LEFT JOIN " + gameTable + " ON en_data.game_id = " + gameTable + ".id").
 		Select(`
@@ -233,8 +274,12 @@ func (dao gameDao) GetTopPrice(num int) (res []models.TopPriceVo, err common.GFE
 			en_data.global_price,
 			COALESCE(zh_data.china_price, 0) AS china_price,
 			en_data.discount,
-			COALESCE(` + gameTable + `.header, '') AS header 
+			COALESCE(` + gameTable + `.header, '') AS header,
+			COALESCE(en_data.discount > 0 AND en_data.global_price <= global_low.low, false) AS global_historical_low,
+			COALESCE(zh_data.china_discount > 0 AND zh_data.china_price <= china_low.low, false) AS china_historical_low
 		`).
+		// 排除已删除的游戏
+		Where(gameTable + ".deleted IS NOT TRUE").
 		// 按全球区价格降序排序
 		Order("en_data.global_price DESC").
 		// 限制返回条数
@@ -260,6 +305,7 @@ func (dao gameDao) GetUpdateNews(num int, lang string) (res []models.UpdateNewsM
 		Joins("LEFT JOIN "+gameTable+" ON "+newsTable+".game_id = "+gameTable+".id").
 		Select(`
 			CAST(`+newsTable+`.game_id AS VARCHAR) AS id,
+			CAST(`+newsTable+`.id AS VARCHAR) AS news_id,
 			COALESCE(`+gameTable+`.name, '未知游戏') AS name,
 			`+newsTable+`.post_time,
 			`+newsTable+`.headline,
@@ -268,7 +314,7 @@ func (dao gameDao) GetUpdateNews(num int, lang string) (res []models.UpdateNewsM
 			`+newsTable+`.url,
 			SUBSTRING(`+newsTable+`.content, 1, 200) AS content
 		`).
-		Where("lang = ?", lang).
+		Where("lang = ? AND "+gameTable+".deleted IS NOT TRUE", lang).
 		Order(newsTable + ".post_time DESC").
 		Limit(num)
 
@@ -283,10 +329,99 @@ func (dao gameDao) GetUpdateNews(num int, lang string) (res []models.UpdateNewsM
 	return res, nil
 }
 
+// PageNews 分页查询更新公告, 按发布时间倒序
+func (dao gameDao) PageNews(query models.NewsQuery) (total int64, res []models.NewsModel, err common.GFError) {
+	newsTable := models.TableNameGfgGameNews
+	gameTable := models.TableNameGfgGame
+
+	nameField := gameTable + ".name"
+	if query.Lang == "en" {
+		nameField = gameTable + ".name_en"
+	}
+	db := dao.Gm.Table(newsTable).
+		Joins("JOIN "+gameTable+" ON "+newsTable+".game_id = "+gameTable+".id").
+		Where(newsTable+".lang = ? AND "+gameTable+".deleted IS NOT TRUE", query.Lang)
+	if len(query.GameIDs) > 0 {
+		db = db.Where(newsTable+".game_id IN ?", query.GameIDs)
+	}
+	if !query.From.IsZero() {
+		db = db.Where(newsTable+".post_time >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		db = db.Where(newsTable+".post_time < ?", query.To)
+	}
+
+	if dbErr := db.Count(&total).Error; dbErr != nil {
+		return 0, res, common.NewDaoError("统计更新公告失败: " + dbErr.Error())
+	}
+	db = db.Select(newsTable + ".*, " + nameField + " AS name, " + gameTable + ".header").
+		Order(newsTable + ".post_time DESC, " + newsTable + ".id DESC").
+		Offset((query.PageNum - 1) * query.PageSize).
+		Limit(query.PageSize)
+	if dbErr := db.Find(&res).Error; dbErr != nil {
+		return 0, res, common.NewDaoError("获取更新公告失败: " + dbErr.Error())
+	}
+	return total, res, nil
+}
+
+// GetNews 获取单条更新公告, 游戏名称取公告语言对应的名称
+func (dao gameDao) GetNews(id int64) (res models.NewsModel, err common.GFError) {
+	newsTable := models.TableNameGfgGameNews
+	gameTable := models.TableNameGfgGame
+
+	db := dao.Gm.Table(newsTable).
+		Joins("JOIN "+gameTable+" ON "+newsTable+".game_id = "+gameTable+".id").
+		Select(newsTable+".*, CASE WHEN "+newsTable+".lang = 'en' THEN "+gameTable+".name_en ELSE "+gameTable+".name END AS name, "+gameTable+".header").
+		Where(newsTable+".id = ? AND "+gameTable+".deleted IS NOT TRUE", id)
+	if dbErr := db.Take(&res).Error; dbErr != nil {
+		if errors.Is(dbErr, gorm.ErrRecordNotFound) {
+			return res, common.NewDaoError("更新公告不存在")
+		}
+		return res, common.NewDaoError(dbErr.Error())
+	}
+	return res, nil
+}
+
+// GetTagGames 获取未删除标签与未删除游戏之间的映射
+func (dao gameDao) GetTagGames() (res []models.TagGameModel, err common.GFError) {
+	db := dao.Gm.Table(gm.TableNameGfgTagMap).Select("DISTINCT tag_id, game_id").
+		Where("deleted IS NOT TRUE").
+		Where("game_id IN (?)", dao.Gm.Table(models.TableNameGfgGame).Select("id").Where("deleted IS NOT TRUE"))
+	if dbErr := db.Find(&res).Error; dbErr != nil {
+		return res, common.NewDaoError(dbErr.Error())
+	}
+	return res, nil
+}
+
+// GetCalendarGames 获取未删除游戏的发行日期与对应语言商店记录的发售状态, tagID 为 0 时不过滤标签
+func (dao gameDao) GetCalendarGames(lang string, tagID int64) (res []models.CalendarModel, err common.GFError) {
+	gameTable := models.TableNameGfgGame
+	recordTable := models.TableNameGfgGameRecord
+	name, info := "name", "info"
+	if lang == "en" {
+		name, info = "name_en", "info_en"
+	}
+
+	db := dao.Gm.Table(gameTable).
+		Joins("LEFT JOIN "+recordTable+" ON "+recordTable+".game_id = "+gameTable+".id AND "+recordTable+".lang = ?", lang).
+		Select(gameTable + ".id, " + gameTable + "." + name + " AS name, " + gameTable + ".header, " + gameTable + "." + info + " AS info, " +
+			gameTable + ".release_date, " + gameTable + ".update_time, " +
+			"COALESCE(" + recordTable + ".release_date, '') AS store_date, COALESCE(" + recordTable + ".coming_soon, false) AS coming_soon").
+		Where(gameTable + ".deleted IS NOT TRUE")
+	if tagID != 0 {
+		db = db.Where(gameTable+".id IN (?)", dao.Gm.Table("gfg_tag_map").Select("game_id").Where("tag_id = ? AND deleted IS NOT TRUE", tagID))
+	}
+	if dbErr := db.Order(gameTable + ".id").Find(&res).Error; dbErr != nil {
+		return res, common.NewDaoError(dbErr.Error())
+	}
+	return res, nil
+}
+
 func (dao gameDao) GetTagList(lang string) (res []models.TagModelVo, err common.GFError) {
 	var countSubQuery *gorm.DB
 	countSubQuery = dao.Gm.Table(gm.TableNameGfgTagMap).
 		Select("tag_id, COUNT(*) as game_count").
+		Where("deleted IS NOT TRUE").
 		Group("tag_id")
 
 	nameField := "gfg_tag.name AS name"
@@ -301,7 +436,7 @@ func (dao gameDao) GetTagList(lang string) (res []models.TagModelVo, err common.
 			nameField,
 			"CAST(gfg_tag.prefix AS VARCHAR) AS prefix",
 			"COALESCE(tag_count.game_count, 0) AS game_count",
-		).Order("game_count DESC")
+		).Where("gfg_tag.deleted IS NOT TRUE").Order("game_count DESC")
 
 	if dbErr := db.Find(&res).Error; dbErr != nil {
 		return res, common.NewDaoError("获取标签记录失败: " + dbErr.Error())
@@ -309,3 +444,112 @@ func (dao gameDao) GetTagList(lang string) (res []models.TagModelVo, err common.
 
 	return res, nil
 }
+
+// lowestPriceSubQuery 某地区每个游戏的历史最低价
+func (dao gameDao) lowestPriceSubQuery(region string) *gorm.DB {
+	return dao.Gm.Table(models.TableNameGfgGamePriceHistory).
+		Select("game_id, MIN(final) AS low").
+		Where("region = ?", region).
+		Group("game_id")
+}
+
+// GetPriceHistory 获取游戏的价格历史, region 为空时返回所有地区, 按时间升序
+func (dao gameDao) GetPriceHistory(id int64, region string) (res []models.GfgGamePriceHistory, err common.GFError) {
+	db := dao.Gm.Table(models.TableNameGfgGamePriceHistory).Where("game_id = ?", id)
+	if region != "" {
+		db = db.Where("region = ?", region)
+	}
+	if dbErr := db.Order("region, create_time ASC").Find(&res).Error; dbErr != nil {
+		return res, common.NewDaoError(dbErr.Error())
+	}
+	return res, nil
+}
+
+// RollupPlayerCount 将 from 当天起的在线人数采样按天汇总, 已存在的日期重新计算
+func (dao gameDao) RollupPlayerCount(from time.Time) (rows int64, err common.GFError) {
+	db := dao.Gm.Exec(`
+		INSERT INTO `+models.TableNameGfgGamePlayerCountDaily+` (game_id, day, count_min, count_avg, count_max, samples, update_time)
+		SELECT game_id, create_time::date, MIN(count), ROUND(AVG(count))::INT8, MAX(count), COUNT(*), LOCALTIMESTAMP(0)
+		FROM `+models.TableNameGfgGamePlayerCount+`
+		WHERE create_time >= ?::date
+		GROUP BY game_id, create_time::date
+		ON CONFLICT (game_id, day) DO UPDATE SET
+			count_min = EXCLUDED.count_min,
+			count_avg = EXCLUDED.count_avg,
+			count_max = EXCLUDED.count_max,
+			samples = EXCLUDED.samples,
+			update_time = EXCLUDED.update_time
+	`, from.Format("2006-01-02"))
+	if dbErr := db.Error; dbErr != nil {
+		return 0, common.NewDaoError("汇总在线人数失败: " + dbErr.Error())
+	}
+	return db.RowsAffected, nil
+}
+
+// GetPlayerCountBuckets 按 bucket 长度聚合 from 之后的在线人数采样, 按时间升序
+func (dao gameDao) GetPlayerCountBuckets(id int64, from time.Time, bucket time.Duration) (res []models.PlayerCountBucketVo, err common.GFError) {
+	seconds := int64(bucket / time.Second)
+	// create_time 不带时区, 以 UTC 换算纪元秒再换算回来, 保证桶边界不受会话时区影响
+	db := dao.Gm.Table(models.TableNameGfgGamePlayerCount).
+		Select(`
+			to_timestamp(FLOOR(EXTRACT(EPOCH FROM create_time) / ?) * ?) AT TIME ZONE 'UTC' AS time,
+			MIN(count) AS min,
+			ROUND(AVG(count))::INT8 AS avg,
+			MAX(count) AS max
+		`, seconds, seconds).
+		Where("game_id = ? AND create_time >= ?", id, from).
+		Group("1").
+		Order("1")
+	if dbErr := db.Find(&res).Error; dbErr != nil {
+		return res, common.NewDaoError(dbErr.Error())
+	}
+	return res, nil
+}
+
+// GetPlayerCountDaily 获取 from 当天起的在线人数日汇总, from 为零值时返回全部, 按日期升序
+func (dao gameDao) GetPlayerCountDaily(id int64, from time.Time) (res []models.PlayerCountBucketVo, err common.GFError) {
+	db := dao.Gm.Table(models.TableNameGfgGamePlayerCountDaily).
+		Select("day AS time, count_min AS min, count_avg AS avg, count_max AS max").
+		Where("game_id = ?", id)
+	if !from.IsZero() {
+		db = db.Where("day >= ?::date", from.Format("2006-01-02"))
+	}
+	if dbErr := db.Order("day").Find(&res).Error; dbErr != nil {
+		return res, common.NewDaoError(dbErr.Error())
+	}
+	return res, nil
+}
+
+// GetPlayerTrending 比较最近一个窗口与上一个窗口的平均在线人数, 按增长率降序
+// 上一窗口平均人数低于 minBase 的游戏不参与排名, 避免小基数带来的夸张增长
+func (dao gameDao) GetPlayerTrending(window time.Duration, minBase int64, num int) (res []models.PlayerTrendingVo, err common.GFError) {
+	countTable := models.TableNameGfgGamePlayerCount
+	gameTable := models.TableNameGfgGame
+	now := time.Now()
+
+	avgSubQuery := func(from, to time.Time) *gorm.DB {
+		return dao.Gm.Table(countTable).
+			Select("game_id, AVG(count) AS count_avg").
+			Where("create_time >= ? AND create_time < ?", from, to).
+			Group("game_id")
+	}
+
+	db := dao.Gm.Table("(?) AS recent", avgSubQuery(now.Add(-window), now)).
+		Joins("JOIN (?) AS previous ON recent.game_id = previous.game_id", avgSubQuery(now.Add(-2*window), now.Add(-window))).
+		Joins("JOIN "+gameTable+" ON recent.game_id = "+gameTable+".id").
+		Select(`
+			CAST(recent.game_id AS VARCHAR) AS id,
+			`+gameTable+`.name AS name,
+			COALESCE(`+gameTable+`.header, '') AS header,
+			ROUND(recent.count_avg)::INT8 AS count_recent,
+			ROUND(previous.count_avg)::INT8 AS count_previous,
+			(recent.count_avg - previous.count_avg) / previous.count_avg AS growth
+		`).
+		Where(gameTable+".deleted IS NOT TRUE AND previous.count_avg >= ?", minBase).
+		Order("growth DESC").
+		Limit(num)
+	if dbErr := db.Find(&res).Error; dbErr != nil {
+		return res, common.NewDaoError("获取在线人数趋势失败: " + dbErr.Error())
+	}
+	return res, nil
+}
@@ -28,6 +28,7 @@ type GfgGame struct {
 	Header      string       `gorm:"column:header;type:character varying(255);not null;comment:游戏封面图" json:"header"`                   // 游戏封面图
 	Links       *string      `gorm:"column:links;type:json;comment:三方网站链接" json:"links"`                                               // 三方网站链接
 	Weight      int64        `gorm:"column:weight;type:bigint;not null;comment:权重" json:"weight"`                                      // 权重
+	Deleted     bool         `gorm:"column:deleted;type:boolean;comment:软删除" json:"deleted"`                                           // 软删除
 }
 
 // TableName GfgGame's table name
@@ -69,6 +70,7 @@ type GfgGameRecord struct {
 	Initial     int64  `gorm:"column:initial;type:bigint;not null;comment:游戏价格" json:"initial"`                         // 游戏价格
 	Final       int64  `gorm:"column:final;type:bigint;not null;comment:当前价格" json:"final"`                             // 当前价格
 	Discount    int64  `gorm:"column:discount;type:bigint;not null;comment:折扣百分比" json:"discount"`                      // 折扣百分比
+	ComingSoon  bool   `gorm:"column:coming_soon;type:boolean;not null;comment:是否尚未发售" json:"comingSoon"`               // 是否尚未发售
 }
 
 // TableName GfgGameRecord's table name
@@ -98,6 +100,49 @@ func (*GfgGamePlayerCount) TableName() string {
 	return TableNameGfgGamePlayerCount
 }
 
+const TableNameGfgGamePlayerCountDaily = "gfg_game_player_count_daily"
+
+// GfgGamePlayerCountDaily mapped from table <gfg_game_player_count_daily>
+type GfgGamePlayerCountDaily struct {
+	GameID     int64        `gorm:"column:game_id;type:bigint;primaryKey;comment:游戏表ID" json:"gameId,string"`                         // 游戏表ID
+	Day        cm.LocalTime `gorm:"column:day;type:date;primaryKey;comment:统计日期" json:"day"`                                          // 统计日期
+	CountMin   int64        `gorm:"column:count_min;type:bigint;not null;comment:当日最低在线人数" json:"countMin"`                           // 当日最低在线人数
+	CountAvg   int64        `gorm:"column:count_avg;type:bigint;not null;comment:当日平均在线人数" json:"countAvg"`                           // 当日平均在线人数
+	CountMax   int64        `gorm:"column:count_max;type:bigint;not null;comment:当日最高在线人数" json:"countMax"`                           // 当日最高在线人数
+	Samples    int64        `gorm:"column:samples;type:bigint;not null;comment:当日采样次数" json:"samples"`                                // 当日采样次数
+	UpdateTime cm.LocalTime `gorm:"column:update_time;type:int;type:unsigned;not null;autoUpdateTime;comment:更新时间" json:"updateTime"` // 更新时间
+}
+
+// TableName GfgGamePlayerCountDaily's table name
+func (*GfgGamePlayerCountDaily) TableName() string {
+	return TableNameGfgGamePlayerCountDaily
+}
+
+// PlayerCountBucketVo 一个时间桶内的在线人数
+type PlayerCountBucketVo struct {
+	Time cm.LocalTime `json:"time"` // 时间桶起点
+	Min  int64        `json:"min"`
+	Avg  int64        `json:"avg"`
+	Max  int64        `json:"max"`
+}
+
+type PlayerCountHistoryVo struct {
+	ID     string                `json:"id"`
+	Range  string                `json:"range"`
+	Bucket int64                 `json:"bucket"` // 时间桶长度(秒)
+	Points []PlayerCountBucketVo `json:"points"`
+}
+
+// PlayerTrendingVo 窗口内平均在线人数与上一窗口相比的增长
+type PlayerTrendingVo struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Header        string  `json:"header"`
+	CountRecent   int64   `json:"count_recent"`   // 当前窗口平均在线人数
+	CountPrevious int64   `json:"count_previous"` // 上一窗口平均在线人数
+	Growth        float64 `json:"growth"`         // 增长率, 0.5 表示增长 50%
+}
+
 type PlayerTopCountVo struct {
 	ID          string `json:"id"`
 	Name        string `json:"name"`
@@ -108,12 +153,65 @@ type PlayerTopCountVo struct {
 }
 
 type TopPriceVo struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	GlobalPrice int64  `json:"global_price"`
-	ChinaPrice  int64  `json:"china_price"`
-	Discount    int64  `json:"discount"`
-	Header      string `json:"header"`
+	ID                  string `json:"id"`
+	Name                string `json:"name"`
+	GlobalPrice         int64  `json:"global_price"`
+	ChinaPrice          int64  `json:"china_price"`
+	Discount            int64  `json:"discount"`
+	Header              string `json:"header"`
+	GlobalHistoricalLow bool   `json:"global_historical_low"` // 全球区当前为史低
+	ChinaHistoricalLow  bool   `json:"china_historical_low"`  // 国区当前为史低
+}
+
+const TableNameGfgGamePriceHistory = "gfg_game_price_history"
+
+// 价格地区, 与 Steam cc 参数一致
+const (
+	PriceRegionChina  = "cn" // 国区, 对应 zh 记录
+	PriceRegionGlobal = "us" // 全球区, 对应 en 记录
+)
+
+// GfgGamePriceHistory mapped from table <gfg_game_price_history>
+type GfgGamePriceHistory struct {
+	ID         int64        `gorm:"column:id;type:bigint;primaryKey;comment:价格历史表ID" json:"id"`                                       // 价格历史表ID
+	GameID     int64        `gorm:"column:game_id;type:bigint;not null;comment:游戏表ID" json:"gameId,string"`                           // 游戏表ID
+	Region     string       `gorm:"column:region;type:character varying(10);not null;comment:地区代码" json:"region"`                     // 地区代码
+	Currency   string       `gorm:"column:currency;type:character varying(10);not null;comment:币种" json:"currency"`                   // 币种
+	Initial    int64        `gorm:"column:initial;type:bigint;not null;comment:原价(分)" json:"initial"`                                 // 原价(分)
+	Final      int64        `gorm:"column:final;type:bigint;not null;comment:实际价格(分)" json:"final"`                                   // 实际价格(分)
+	Discount   int64        `gorm:"column:discount;type:bigint;not null;comment:折扣百分比" json:"discount"`                               // 折扣百分比
+	CreateTime cm.LocalTime `gorm:"column:create_time;type:int;type:unsigned;not null;autoCreateTime;comment:采集时间" json:"createTime"` // 采集时间
+}
+
+// TableName GfgGamePriceHistory's table name
+func (*GfgGamePriceHistory) TableName() string {
+	return TableNameGfgGamePriceHistory
+}
+
+type PricePointVo struct {
+	Initial  int64        `json:"initial"`
+	Final    int64        `json:"final"`
+	Discount int64        `json:"discount"`
+	Time     cm.LocalTime `json:"time"`
+}
+
+// PriceStatVo 单个地区的价格统计
+type PriceStatVo struct {
+	Region        string       `json:"region"`
+	Currency      string       `json:"currency"`
+	Current       int64        `json:"current"`
+	Discount      int64        `json:"discount"`
+	Low           int64        `json:"low"`
+	LowTime       cm.LocalTime `json:"low_time"` // 最近一次达到史低的时间
+	High          int64        `json:"high"`
+	HighTime      cm.LocalTime `json:"high_time"`
+	HistoricalLow bool         `json:"historical_low"` // 正在打折且当前价格不高于史低
+}
+
+type PriceHistoryVo struct {
+	ID     string         `json:"id"`
+	Stat   PriceStatVo    `json:"stat"`
+	Points []PricePointVo `json:"points"`
 }
 
 type GameMainPanelVo struct {
@@ -123,6 +221,7 @@ type GameMainPanelVo struct {
 
 type UpdateNewsModels struct {
 	ID       string       `json:"id"`
+	NewsID   string       `json:"news_id"`
 	Name     string       `json:"name"`
 	PostTime cm.LocalTime `json:"post_time"`
 	Headline string       `json:"headline"`
@@ -152,6 +251,7 @@ type GfgGameNews struct {
 	URL        string       `gorm:"column:url;type:character varying(255);not null;comment:更新公告原始地址" json:"url"`                      // 更新公告原始地址
 	Total      int64        `gorm:"column:total;type:bigint;not null;comment:公告总数" json:"total"`                                      // 公告总数
 	Lang       string       `gorm:"column:lang;type:character varying(30);not null;comment:记录的语言" json:"lang"`                        // 记录的语言
+	GID        string       `gorm:"column:gid;type:character varying(30);not null;comment:Steam 公告 gid" json:"-"`                     // Steam 公告 gid
 }
 
 // TableName GfgGameNews's table name
@@ -166,6 +266,33 @@ type TagModelVo struct {
 	GameCount int    `json:"game_count"`
 }
 
+// TagGameModel 标签与未删除游戏的映射
+type TagGameModel struct {
+	TagID  int64 `gorm:"column:tag_id"`
+	GameID int64 `gorm:"column:game_id"`
+}
+
+type TagNodeVo struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	Prefix     string      `json:"prefix"`
+	GameCount  int         `json:"game_count"`  // 直接关联的游戏数
+	TotalCount int         `json:"total_count"` // 子树内去重后的游戏数
+	Children   []TagNodeVo `json:"children"`
+}
+
+type TagIssueVo struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Prefix string `json:"prefix"`
+}
+
+type TagTreeVo struct {
+	Tree    []TagNodeVo    `json:"tree"`
+	Orphans []TagIssueVo   `json:"orphans"` // 父标签不存在, 已作为根节点展示
+	Cycles  [][]TagIssueVo `json:"cycles"`  // 父子关系成环, 环内 ID 最小的标签已作为根节点展示
+}
+
 type GameBaseInfoVo struct {
 	Name       string       `json:"name"`
 	Info       string       `json:"info"`
@@ -210,6 +337,84 @@ type NewsVo struct {
 	URL      string       `json:"url"`
 }
 
+// NewsQuery 更新公告分页查询, GameIDs 为空时查询全部游戏
+type NewsQuery struct {
+	cm.PageReq
+	GameIDs []int64
+	Lang    string
+	From    time.Time // 发布时间下限, 零值表示不限
+	To      time.Time // 发布时间上限(不含), 零值表示不限
+}
+
+// NewsModel 更新公告及所属游戏
+type NewsModel struct {
+	GfgGameNews
+	Name   string `gorm:"column:name"`
+	Header string `gorm:"column:header"`
+}
+
+type NewsListVo struct {
+	ID       string       `json:"id"`
+	GameID   string       `json:"game_id"`
+	Name     string       `json:"name"`
+	Header   string       `json:"header"`
+	Headline string       `json:"headline"`
+	Summary  string       `json:"summary"` // 纯文本摘要
+	PostTime cm.LocalTime `json:"post_time"`
+	Author   string       `json:"author"`
+	URL      string       `json:"url"`
+}
+
+type NewsDetailVo struct {
+	NewsListVo
+	Lang    string `json:"lang"`
+	Content string `json:"content"` // 过滤后的 HTML
+}
+
+// ReleaseDateModel 解析后的发行日期, 区间为 [Start, End)
+type ReleaseDateModel struct {
+	Start     time.Time
+	End       time.Time
+	Precision string
+}
+
+// CalendarModel 发售日历使用的游戏及对应语言的商店记录
+type CalendarModel struct {
+	ID          int64        `gorm:"column:id"`
+	Name        string       `gorm:"column:name"`
+	Header      string       `gorm:"column:header"`
+	Info        string       `gorm:"column:info"`
+	ReleaseDate string       `gorm:"column:release_date"` // 后台录入的发行日期
+	StoreDate   string       `gorm:"column:store_date"`   // 商店显示的发行日期
+	ComingSoon  bool         `gorm:"column:coming_soon"`
+	UpdateTime  cm.LocalTime `gorm:"column:update_time"`
+}
+
+type CalendarGameVo struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Header      string `json:"header"`
+	Info        string `json:"info"`
+	ReleaseDate string `json:"release_date"` // 原始日期文本
+	Precision   string `json:"precision"`    // day/month/quarter/year/unknown
+	Start       string `json:"start"`        // 区间首日 YYYY-MM-DD, unknown 时为空
+	End         string `json:"end"`          // 区间末日 YYYY-MM-DD, 包含当天
+	ComingSoon  bool   `json:"coming_soon"`
+}
+
+type CalendarDayVo struct {
+	Date  string           `json:"date"`
+	Games []CalendarGameVo `json:"games"`
+}
+
+type CalendarVo struct {
+	From        string           `json:"from"`
+	To          string           `json:"to"`
+	Days        []CalendarDayVo  `json:"days"`        // 精确到天的发售, 按日期升序
+	Fuzzy       []CalendarGameVo `json:"fuzzy"`       // 只精确到月/季度/年且与查询区间重叠的发售
+	Unscheduled []CalendarGameVo `json:"unscheduled"` // 即将推出但日期待定
+}
+
 type TagVo struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
@@ -247,10 +452,18 @@ func (GameIntro) TableName() string {
 }
 
 type GameIntroVo struct {
+	ID         string       `json:"id"`
+	Lang       string       `json:"lang"` // 实际返回的语言, 缺失时回退到另一种语言
 	Content    string       `json:"content"`
 	UpdateTime cm.LocalTime `json:"update_time"`
 }
 
+type GameIntroSaveRequest struct {
+	ID      string `json:"id" validate:"required,number" label:"游戏ID"`
+	Lang    string `json:"lang" validate:"required,oneof=zh en" label:"语言"`
+	Content string `json:"content" validate:"required,max=1048576" label:"内容"`
+}
+
 const TableNameGfgGameCreator = "gfg_game_creator"
 
 // GfgGameCreator mapped from table <gfg_game_creator>
@@ -369,6 +582,7 @@ type GameSaveModel struct {
 	HeaderImage         string               `json:"header_image"`
 	ShortDescription    string               `json:"short_description"`
 	Date                string               `json:"date"`
+	Release             SteamAppRelease      `json:"release"`
 	Platforms           string               `json:"platforms"`
 	RequiredAge         string               `json:"required_age"`
 	Website             string               `json:"website"`
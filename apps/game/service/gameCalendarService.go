@@ -0,0 +1,206 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/game/calendar"
+	"github.com/GoFurry/gofurry-game-backend/apps/game/dao"
+	"github.com/GoFurry/gofurry-game-backend/apps/game/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+	"github.com/GoFurry/gofurry-game-backend/roof/env"
+)
+
+type gameCalendarService struct{}
+
+var gameCalendarSingleton = new(gameCalendarService)
+
+func GetGameCalendarService() *gameCalendarService { return gameCalendarSingleton }
+
+const (
+	calendarDefaultDays = 90  // 未传 to 时的查询天数
+	calendarMaxDays     = 366 // 单次查询的最大天数
+	// iCalendar 导出的区间, 相对当天
+	icsPastDays   = 30
+	icsFutureDays = 365
+)
+
+// 获取发售日历, from/to 为 YYYY-MM-DD 且均包含当天, tag 为标签 ID
+func (s gameCalendarService) GetCalendar(from string, to string, lang string, tag string) (res models.CalendarVo, err common.GFError) {
+	if lang != "zh" && lang != "en" {
+		return res, common.NewServiceError("lang 仅支持 zh/en")
+	}
+	start := today()
+	if from != "" {
+		if start, err = parseNewsDay(from); err != nil {
+			return
+		}
+	}
+	end := start.AddDate(0, 0, calendarDefaultDays)
+	if to != "" {
+		if end, err = parseNewsDay(to); err != nil {
+			return
+		}
+		end = end.AddDate(0, 0, 1)
+	}
+	if !start.Before(end) {
+		return res, common.NewServiceError("开始日期不能晚于结束日期")
+	}
+	if end.After(start.AddDate(0, 0, calendarMaxDays)) {
+		return res, common.NewServiceError(fmt.Sprintf("查询区间不能超过 %d 天", calendarMaxDays))
+	}
+	tagID, err := parseTagID(tag)
+	if err != nil {
+		return
+	}
+
+	games, err := dao.GetGameDao().GetCalendarGames(lang, tagID)
+	if err != nil {
+		return
+	}
+	res.From = start.Format(time.DateOnly)
+	res.To = end.AddDate(0, 0, -1).Format(time.DateOnly)
+	res.Days, res.Fuzzy, res.Unscheduled = []models.CalendarDayVo{}, []models.CalendarGameVo{}, []models.CalendarGameVo{}
+
+	now := today()
+	byDay := map[string][]models.CalendarGameVo{}
+	for _, v := range games {
+		release, raw := resolveRelease(v)
+		switch {
+		case release.Precision == calendar.PrecisionUnknown:
+			if v.ComingSoon {
+				res.Unscheduled = append(res.Unscheduled, calendarGameVo(v, raw, release))
+			}
+		case !release.End.After(start) || !release.Start.Before(end):
+			// 与查询区间不重叠
+		case release.Precision == calendar.PrecisionDay:
+			day := release.Start.Format(time.DateOnly)
+			byDay[day] = append(byDay[day], calendarGameVo(v, raw, release))
+		case release.End.After(now):
+			// 模糊日期只展示尚未结束的区间
+			res.Fuzzy = append(res.Fuzzy, calendarGameVo(v, raw, release))
+		}
+	}
+
+	for day, list := range byDay {
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+		res.Days = append(res.Days, models.CalendarDayVo{Date: day, Games: list})
+	}
+	sort.Slice(res.Days, func(i, j int) bool { return res.Days[i].Date < res.Days[j].Date })
+	sort.SliceStable(res.Fuzzy, func(i, j int) bool {
+		if res.Fuzzy[i].Start != res.Fuzzy[j].Start {
+			return res.Fuzzy[i].Start < res.Fuzzy[j].Start
+		}
+		return res.Fuzzy[i].End < res.Fuzzy[j].End
+	})
+	return res, nil
+}
+
+// 导出 iCalendar, 只包含精确到天的发售, 每个游戏一个全天事件
+func (s gameCalendarService) GetCalendarICS(lang string, tag string) (res string, err common.GFError) {
+	if lang != "zh" && lang != "en" {
+		return res, common.NewServiceError("lang 仅支持 zh/en")
+	}
+	tagID, err := parseTagID(tag)
+	if err != nil {
+		return
+	}
+	games, err := dao.GetGameDao().GetCalendarGames(lang, tagID)
+	if err != nil {
+		return
+	}
+
+	site := env.GetServerConfig().Site
+	title := site.Title + " 发售日历"
+	if lang == "en" {
+		title = site.TitleEn + " Release Calendar"
+	}
+	host := "localhost"
+	if u, parseErr := url.Parse(site.URL); parseErr == nil && u.Host != "" {
+		host = u.Hostname()
+	}
+
+	var b strings.Builder
+	calendar.WriteLine(&b, "BEGIN:VCALENDAR")
+	calendar.WriteLine(&b, "VERSION:2.0")
+	calendar.WriteLine(&b, "PRODID:-//GoFurry//Game Release Calendar//"+strings.ToUpper(lang))
+	calendar.WriteLine(&b, "CALSCALE:GREGORIAN")
+	calendar.WriteLine(&b, "METHOD:PUBLISH")
+	calendar.WriteLine(&b, "X-WR-CALNAME:"+calendar.EscapeText(title))
+	calendar.WriteLine(&b, "REFRESH-INTERVAL;VALUE=DURATION:PT12H")
+	calendar.WriteLine(&b, "X-PUBLISHED-TTL:PT12H")
+
+	now := today()
+	from, to := now.AddDate(0, 0, -icsPastDays), now.AddDate(0, 0, icsFutureDays)
+	for _, v := range games {
+		release, _ := resolveRelease(v)
+		if release.Precision != calendar.PrecisionDay || release.Start.Before(from) || !release.Start.Before(to) {
+			continue
+		}
+		id := util.Int642String(v.ID)
+		calendar.WriteLine(&b, "BEGIN:VEVENT")
+		calendar.WriteLine(&b, "UID:game-"+id+"@"+host)
+		calendar.WriteLine(&b, "DTSTAMP:"+v.UpdateTime.Time().UTC().Format("20060102T150405Z"))
+		calendar.WriteLine(&b, "DTSTART;VALUE=DATE:"+release.Start.Format("20060102"))
+		calendar.WriteLine(&b, "DTEND;VALUE=DATE:"+release.End.Format("20060102"))
+		calendar.WriteLine(&b, "SUMMARY:"+calendar.EscapeText(v.Name))
+		if v.Info != "" {
+			calendar.WriteLine(&b, "DESCRIPTION:"+calendar.EscapeText(v.Info))
+		}
+		if site.GamePath != "" {
+			calendar.WriteLine(&b, "URL:"+site.PageURL(site.GamePath, lang, id))
+		}
+		calendar.WriteLine(&b, "TRANSP:TRANSPARENT")
+		calendar.WriteLine(&b, "END:VEVENT")
+	}
+	calendar.WriteLine(&b, "END:VCALENDAR")
+	return b.String(), nil
+}
+
+// resolveRelease 未发售时以商店日期为准, 否则优先使用后台录入的日期
+func resolveRelease(v models.CalendarModel) (models.ReleaseDateModel, string) {
+	if v.ComingSoon && v.StoreDate != "" {
+		return calendar.ParseReleaseDate(v.StoreDate), v.StoreDate
+	}
+	if release := calendar.ParseReleaseDate(v.ReleaseDate); release.Precision != calendar.PrecisionUnknown || v.StoreDate == "" {
+		return release, v.ReleaseDate
+	}
+	return calendar.ParseReleaseDate(v.StoreDate), v.StoreDate
+}
+
+func calendarGameVo(v models.CalendarModel, raw string, release models.ReleaseDateModel) models.CalendarGameVo {
+	vo := models.CalendarGameVo{
+		ID:          util.Int642String(v.ID),
+		Name:        v.Name,
+		Header:      v.Header,
+		Info:        v.Info,
+		ReleaseDate: raw,
+		Precision:   release.Precision,
+		ComingSoon:  v.ComingSoon,
+	}
+	if release.Precision != calendar.PrecisionUnknown {
+		vo.Start = release.Start.Format(time.DateOnly)
+		vo.End = release.End.AddDate(0, 0, -1).Format(time.DateOnly)
+	}
+	return vo
+}
+
+func parseTagID(tag string) (int64, common.GFError) {
+	if tag == "" {
+		return 0, nil
+	}
+	tagID, parseErr := util.String2Int64(tag)
+	if parseErr != nil {
+		return 0, common.NewServiceError("Tag ID 转换有误")
+	}
+	return tagID, nil
+}
+
+func today() time.Time {
+	y, m, d := time.Now().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/game/dao"
+	"github.com/GoFurry/gofurry-game-backend/apps/game/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	cm "github.com/GoFurry/gofurry-game-backend/common/models"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+)
+
+type gameIntroService struct {
+	introDao *dao.GameIntroDao
+}
+
+var gameIntroSingleton = &gameIntroService{introDao: dao.NewGameIntroDao()}
+
+func GetGameIntroService() *gameIntroService { return gameIntroSingleton }
+
+const (
+	introQueryTimeout = 5 * time.Second // MongoDB 操作超时时间
+	introBatchMaxNum  = 50              // 批量查询上限
+)
+
+// 获取单个游戏的内容详情, 缺失语言时回退到另一种语言
+func (s gameIntroService) GetGameIntro(id string, lang string) (res models.GameIntroVo, err common.GFError) {
+	intID, parseErr := util.String2Int64(id)
+	if parseErr != nil {
+		return res, common.NewServiceError("Game ID 转换有误")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), introQueryTimeout)
+	defer cancel()
+
+	lang = normalizeIntroLang(lang)
+	for _, l := range []string{lang, fallbackIntroLang(lang)} {
+		intro, err := s.introDao.GetByGameIDAndLang(ctx, intID, l)
+		if err != nil {
+			log.Error("GetGameIntro err: ", err.GetMsg())
+			return res, common.NewServiceError("获取游戏内容详情失败")
+		}
+		if intro.GameID != 0 {
+			return toGameIntroVo(&intro), nil
+		}
+	}
+	return res, common.NewServiceError("游戏内容详情不存在")
+}
+
+// 批量获取游戏内容详情, ids 以逗号分隔
+func (s gameIntroService) BatchGetGameIntro(ids string, lang string) (res []models.GameIntroVo, err common.GFError) {
+	var gameIDs []int64
+	seen := make(map[int64]struct{})
+	for _, v := range strings.Split(ids, ",") {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		intID, parseErr := util.String2Int64(v)
+		if parseErr != nil {
+			return nil, common.NewServiceError("Game ID 转换有误: " + v)
+		}
+		if _, ok := seen[intID]; ok {
+			continue
+		}
+		seen[intID] = struct{}{}
+		gameIDs = append(gameIDs, intID)
+	}
+	if len(gameIDs) == 0 {
+		return nil, common.NewServiceError("Game ID 不能为空")
+	}
+	if len(gameIDs) > introBatchMaxNum {
+		return nil, common.NewServiceError("单次最多查询 " + util.Int2String(introBatchMaxNum) + " 条")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), introQueryTimeout)
+	defer cancel()
+
+	lang = normalizeIntroLang(lang)
+	introMap, err := s.introDao.BatchGetByGameIDs(ctx, gameIDs, lang)
+	if err != nil {
+		log.Error("BatchGetGameIntro err: ", err.GetMsg())
+		return nil, common.NewServiceError("批量获取游戏内容详情失败")
+	}
+
+	// 缺失的记录用另一种语言补齐
+	var missing []int64
+	for _, id := range gameIDs {
+		if _, ok := introMap[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		fallbackMap, err := s.introDao.BatchGetByGameIDs(ctx, missing, fallbackIntroLang(lang))
+		if err != nil {
+			log.Error("BatchGetGameIntro fallback err: ", err.GetMsg())
+			return nil, common.NewServiceError("批量获取游戏内容详情失败")
+		}
+		for k, v := range fallbackMap {
+			introMap[k] = v
+		}
+	}
+
+	// 按入参顺序返回
+	res = make([]models.GameIntroVo, 0, len(introMap))
+	for _, id := range gameIDs {
+		if intro, ok := introMap[id]; ok {
+			res = append(res, toGameIntroVo(intro))
+		}
+	}
+	return res, nil
+}
+
+// 保存游戏内容详情, 入库前按白名单过滤 HTML
+func (s gameIntroService) SaveGameIntro(req models.GameIntroSaveRequest) common.GFError {
+	intID, parseErr := util.String2Int64(req.ID)
+	if parseErr != nil {
+		return common.NewServiceError("Game ID 转换有误")
+	}
+	if _, err := dao.GetGameDao().GetGame(intID); err != nil {
+		return common.NewServiceError("游戏不存在")
+	}
+
+	content := strings.TrimSpace(util.SanitizeHTML(req.Content))
+	if content == "" {
+		return common.NewServiceError("过滤后的内容为空")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), introQueryTimeout)
+	defer cancel()
+
+	// 保留原有的创建时间
+	intro := models.GameIntro{GameID: intID, Lang: req.Lang, Content: content}
+	old, err := s.introDao.GetByGameIDAndLang(ctx, intID, req.Lang)
+	if err != nil {
+		return err
+	}
+	if old.GameID != 0 {
+		intro.ID = old.ID
+		intro.CreateTime = old.CreateTime
+	}
+	return s.introDao.SaveOrUpdate(ctx, &intro)
+}
+
+// 删除游戏所有语言的内容详情
+func (s gameIntroService) DeleteGameIntro(id string) common.GFError {
+	intID, parseErr := util.String2Int64(id)
+	if parseErr != nil {
+		return common.NewServiceError("Game ID 转换有误")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), introQueryTimeout)
+	defer cancel()
+	return s.introDao.DeleteByGameID(ctx, intID)
+}
+
+func toGameIntroVo(intro *models.GameIntro) models.GameIntroVo {
+	return models.GameIntroVo{
+		ID:   util.Int642String(intro.GameID),
+		Lang: intro.Lang,
+		// 历史数据可能未经过滤, 输出前再过滤一次
+		Content:    util.SanitizeHTML(intro.Content),
+		UpdateTime: cm.LocalTime(intro.UpdateTime),
+	}
+}
+
+func normalizeIntroLang(lang string) string {
+	if lang == "en" {
+		return "en"
+	}
+	return "zh"
+}
+
+func fallbackIntroLang(lang string) string {
+	if lang == "en" {
+		return "zh"
+	}
+	return "en"
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/game/dao"
+	"github.com/GoFurry/gofurry-game-backend/apps/game/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	cm "github.com/GoFurry/gofurry-game-backend/common/models"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+)
+
+type gameNewsService struct{}
+
+var gameNewsSingleton = new(gameNewsService)
+
+func GetGameNewsService() *gameNewsService { return gameNewsSingleton }
+
+const (
+	newsMaxPageSize = 50
+	newsSummaryLen  = 200
+	// Steam 社区公告中图片地址的占位符
+	steamClanImage    = "{STEAM_CLAN_IMAGE}"
+	steamClanImageURL = "https://clan.cloudflare.steamstatic.com/images"
+)
+
+var (
+	// Steam 社区公告使用 BBCode, 其余来源多为 HTML
+	bbCodePattern = regexp.MustCompile(`(?i)\[(/?)(b|i|u|s|h[1-6]|url|img|list|olist|\*|quote|code|table|tr|td|th|spoiler|strike|noparse|hr|p|center|previewyoutube)([=\]])`)
+	htmlPattern   = regexp.MustCompile(`</?[a-zA-Z][a-zA-Z0-9]*[\s/>]`)
+	tagPattern    = regexp.MustCompile(`<[^>]*>|\[/?[a-zA-Z*][^\]]*\]`)
+	spacePattern  = regexp.MustCompile(`\s+`)
+)
+
+// 分页获取单个游戏的更新公告
+func (s gameNewsService) PageGameNews(id string, lang string, page string, size string) (res cm.PageResponse, err common.GFError) {
+	intID, parseErr := util.String2Int64(id)
+	if parseErr != nil {
+		return res, common.NewServiceError("Game ID 转换有误")
+	}
+	query, err := newsPageQuery(lang, page, size)
+	if err != nil {
+		return res, err
+	}
+	query.GameIDs = []int64{intID}
+	return s.pageNews(query)
+}
+
+// 分页获取所有游戏的更新公告, gameIDs 为逗号分隔的游戏 ID, from/to 为 YYYY-MM-DD 且均包含当天
+func (s gameNewsService) PageNewsFeed(lang string, gameIDs string, from string, to string, page string, size string) (res cm.PageResponse, err common.GFError) {
+	query, err := newsPageQuery(lang, page, size)
+	if err != nil {
+		return res, err
+	}
+	for _, v := range strings.Split(gameIDs, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		intID, parseErr := util.String2Int64(v)
+		if parseErr != nil {
+			return res, common.NewServiceError("Game ID 转换有误")
+		}
+		query.GameIDs = append(query.GameIDs, intID)
+	}
+	if from != "" {
+		if query.From, err = parseNewsDay(from); err != nil {
+			return res, err
+		}
+	}
+	if to != "" {
+		if query.To, err = parseNewsDay(to); err != nil {
+			return res, err
+		}
+		query.To = query.To.AddDate(0, 0, 1)
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return res, common.NewServiceError("开始日期不能晚于结束日期")
+	}
+	return s.pageNews(query)
+}
+
+// 获取单条更新公告, 内容转换为过滤后的 HTML
+func (s gameNewsService) GetNewsDetail(id string) (res models.NewsDetailVo, err common.GFError) {
+	intID, parseErr := util.String2Int64(id)
+	if parseErr != nil {
+		return res, common.NewServiceError("公告 ID 转换有误")
+	}
+	news, err := dao.GetGameDao().GetNews(intID)
+	if err != nil {
+		return res, err
+	}
+	content := RenderNewsContent(news.Content)
+	res.NewsListVo = newsListVo(news, content)
+	res.Lang = news.Lang
+	res.Content = content
+	return res, nil
+}
+
+func (s gameNewsService) pageNews(query models.NewsQuery) (res cm.PageResponse, err common.GFError) {
+	total, news, err := dao.GetGameDao().PageNews(query)
+	if err != nil {
+		return res, err
+	}
+	list := make([]models.NewsListVo, len(news))
+	for i, v := range news {
+		list[i] = newsListVo(v, RenderNewsContent(v.Content))
+	}
+	res.Total = total
+	res.Data = list
+	return res, nil
+}
+
+// newsPageQuery 校验语言与分页参数
+func newsPageQuery(lang string, page string, size string) (query models.NewsQuery, err common.GFError) {
+	if lang != "zh" && lang != "en" {
+		return query, common.NewServiceError("lang 仅支持 zh/en")
+	}
+	query.Lang = lang
+	if query.PageNum, err = parsePageParam(page); err != nil {
+		return
+	}
+	if query.PageSize, err = parsePageParam(size); err != nil {
+		return
+	}
+	query.InitPageIfAbsent()
+	query.PageSize = min(query.PageSize, newsMaxPageSize)
+	return query, nil
+}
+
+// parsePageParam 空值视为未传
+func parsePageParam(v string) (int, common.GFError) {
+	if v == "" {
+		return 0, nil
+	}
+	n, parseErr := util.String2Int(v)
+	if parseErr != nil {
+		return 0, common.NewServiceError("分页参数有误")
+	}
+	return n, nil
+}
+
+func parseNewsDay(v string) (time.Time, common.GFError) {
+	day, parseErr := time.ParseInLocation(common.TIME_FORMAT_DAY, v, time.Local)
+	if parseErr != nil {
+		return day, common.NewServiceError("日期格式应为 YYYY-MM-DD")
+	}
+	return day, nil
+}
+
+func newsListVo(news models.NewsModel, content string) models.NewsListVo {
+	return models.NewsListVo{
+		ID:       util.Int642String(news.ID),
+		GameID:   util.Int642String(news.GameID),
+		Name:     news.Name,
+		Header:   news.Header,
+		Headline: news.Headline,
+		Summary:  NewsSummary(content, newsSummaryLen),
+		PostTime: news.PostTime,
+		Author:   news.Author,
+		URL:      news.URL,
+	}
+}
+
+// RenderNewsContent 将公告内容按来源格式转换为 HTML 并按白名单过滤
+// BBCode 与 HTML 按标签识别, 都不匹配时按 Markdown 处理
+func RenderNewsContent(content string) string {
+	content = strings.ReplaceAll(content, steamClanImage, steamClanImageURL)
+	switch {
+	case bbCodePattern.MatchString(content):
+		content = util.BBCodeToHTML(content)
+	case htmlPattern.MatchString(content):
+		// 已是 HTML, 直接过滤
+	default:
+		rendered, err := util.MarkdownToHTML(content)
+		if err != nil {
+			log.Warn("RenderNewsContent markdown err: ", err)
+			rendered = "<p>" + html.EscapeString(content) + "</p>"
+		}
+		content = rendered
+	}
+	return util.SanitizeHTML(content)
+}
+
+// NewsSummary 去除标签后截取前 n 个字符作为纯文本摘要
+func NewsSummary(content string, n int) string {
+	text := tagPattern.ReplaceAllString(content, " ")
+	text = strings.TrimSpace(spacePattern.ReplaceAllString(html.UnescapeString(text), " "))
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	return string([]rune(text)[:n]) + "..."
+}
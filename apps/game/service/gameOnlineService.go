@@ -0,0 +1,123 @@
+package service
+
+import (
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/game/dao"
+	"github.com/GoFurry/gofurry-game-backend/apps/game/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	cs "github.com/GoFurry/gofurry-game-backend/common/service"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+	"github.com/bytedance/sonic"
+)
+
+type gameOnlineService struct{}
+
+var gameOnlineSingleton = new(gameOnlineService)
+
+func GetGameOnlineService() *gameOnlineService { return gameOnlineSingleton }
+
+// onlineRange 走势查询范围, daily 为 true 时读取日汇总表
+type onlineRange struct {
+	span   time.Duration // 为 0 表示全部
+	bucket time.Duration
+	daily  bool
+}
+
+var onlineRanges = map[string]onlineRange{
+	"24h": {span: 24 * time.Hour, bucket: time.Hour},
+	"7d":  {span: 7 * 24 * time.Hour, bucket: 6 * time.Hour},
+	"30d": {span: 30 * 24 * time.Hour, bucket: 24 * time.Hour, daily: true},
+	"all": {bucket: 24 * time.Hour, daily: true},
+}
+
+// 趋势窗口, 与上一个等长窗口比较
+var TrendingWindows = map[string]time.Duration{
+	"6h":  6 * time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+const (
+	TrendingCacheNum     = 50 // 每个窗口缓存的条数
+	trendingMinBase      = 10 // 上一窗口平均在线人数下限
+	trendingCachePrefix  = "game-online:trending-"
+	trendingCacheTimeout = 30 * time.Minute
+)
+
+// 获取游戏在线人数走势, 24h/7d 由原始采样聚合, 30d/all 读取日汇总
+func (s gameOnlineService) GetOnlineHistory(id string, rangeKey string) (res models.PlayerCountHistoryVo, err common.GFError) {
+	intID, parseErr := util.String2Int64(id)
+	if parseErr != nil {
+		return res, common.NewServiceError("Game ID 转换有误")
+	}
+	r, ok := onlineRanges[rangeKey]
+	if !ok {
+		return res, common.NewServiceError("range 仅支持 24h/7d/30d/all")
+	}
+
+	var from time.Time
+	if r.span > 0 {
+		from = time.Now().Add(-r.span)
+	}
+	var points []models.PlayerCountBucketVo
+	if r.daily {
+		points, err = dao.GetGameDao().GetPlayerCountDaily(intID, from)
+	} else {
+		points, err = dao.GetGameDao().GetPlayerCountBuckets(intID, from, r.bucket)
+	}
+	if err != nil {
+		return res, err
+	}
+
+	res.ID = id
+	res.Range = rangeKey
+	res.Bucket = int64(r.bucket / time.Second)
+	res.Points = points
+	if res.Points == nil {
+		res.Points = make([]models.PlayerCountBucketVo, 0)
+	}
+	return res, nil
+}
+
+// 获取在线人数增长最快的游戏, 优先读取定时任务写入的缓存
+func (s gameOnlineService) GetOnlineTrending(windowKey string, num string) (res []models.PlayerTrendingVo, err common.GFError) {
+	intNum, parseErr := util.String2Int(num)
+	if parseErr != nil || intNum <= 0 {
+		return res, common.NewServiceError("入参转换错误")
+	}
+	if _, ok := TrendingWindows[windowKey]; !ok {
+		return res, common.NewServiceError("window 仅支持 6h/24h/7d")
+	}
+	intNum = min(intNum, TrendingCacheNum)
+
+	jsonStr, cacheErr := cs.GetString(trendingCachePrefix + windowKey)
+	if cacheErr == nil && sonic.Unmarshal([]byte(jsonStr), &res) == nil {
+		return res[:min(intNum, len(res))], nil
+	}
+
+	res, err = s.UpdateTrendingCache(windowKey)
+	if err != nil {
+		return
+	}
+	return res[:min(intNum, len(res))], nil
+}
+
+// UpdateTrendingCache 重新计算某个窗口的趋势排名并写入缓存
+func (s gameOnlineService) UpdateTrendingCache(windowKey string) (res []models.PlayerTrendingVo, err common.GFError) {
+	window, ok := TrendingWindows[windowKey]
+	if !ok {
+		return res, common.NewServiceError("window 仅支持 6h/24h/7d")
+	}
+	res, err = dao.GetGameDao().GetPlayerTrending(window, trendingMinBase, TrendingCacheNum)
+	if err != nil {
+		return
+	}
+	if res == nil {
+		res = make([]models.PlayerTrendingVo, 0)
+	}
+	if jsonRecord, jsonErr := sonic.Marshal(res); jsonErr == nil {
+		cs.SetExpire(trendingCachePrefix+windowKey, string(jsonRecord), trendingCacheTimeout)
+	}
+	return res, nil
+}
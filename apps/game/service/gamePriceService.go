@@ -0,0 +1,101 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/game/dao"
+	"github.com/GoFurry/gofurry-game-backend/apps/game/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+)
+
+type gamePriceService struct{}
+
+var gamePriceSingleton = new(gamePriceService)
+
+func GetGamePriceService() *gamePriceService { return gamePriceSingleton }
+
+// 地区代码为两位小写字母, 与 Steam cc 参数一致
+var regionPattern = regexp.MustCompile(`^[a-z]{2}$`)
+
+// 获取单个地区的价格走势与史低史高
+func (s gamePriceService) GetPriceHistory(id string, region string) (res models.PriceHistoryVo, err common.GFError) {
+	intID, parseErr := util.String2Int64(id)
+	if parseErr != nil {
+		return res, common.NewServiceError("Game ID 转换有误")
+	}
+	region = strings.ToLower(strings.TrimSpace(region))
+	if region == "" {
+		region = models.PriceRegionChina
+	}
+	if !regionPattern.MatchString(region) {
+		return res, common.NewServiceError("地区代码有误")
+	}
+
+	history, err := dao.GetGameDao().GetPriceHistory(intID, region)
+	if err != nil {
+		return res, err
+	}
+	if len(history) == 0 {
+		return res, common.NewServiceError("暂无该地区的价格记录")
+	}
+
+	res.ID = id
+	res.Stat = priceStat(history)
+	res.Points = make([]models.PricePointVo, len(history))
+	for i, v := range history {
+		res.Points[i] = models.PricePointVo{Initial: v.Initial, Final: v.Final, Discount: v.Discount, Time: v.CreateTime}
+	}
+	return res, nil
+}
+
+// 获取游戏在各地区的当前价格、史低史高与是否史低
+func (s gamePriceService) GetPriceStat(id string) (res []models.PriceStatVo, err common.GFError) {
+	intID, parseErr := util.String2Int64(id)
+	if parseErr != nil {
+		return res, common.NewServiceError("Game ID 转换有误")
+	}
+	history, err := dao.GetGameDao().GetPriceHistory(intID, "")
+	if err != nil {
+		return res, err
+	}
+
+	// 记录已按地区、时间排序, 按地区切分
+	res = make([]models.PriceStatVo, 0)
+	for begin := 0; begin < len(history); {
+		end := begin
+		for end < len(history) && history[end].Region == history[begin].Region {
+			end++
+		}
+		res = append(res, priceStat(history[begin:end]))
+		begin = end
+	}
+	return res, nil
+}
+
+// priceStat 统计单个地区按时间升序的价格记录, 最后一条为当前价格
+// 只有正在打折且当前价格不高于史低时才视为史低, 避免从未打折的游戏始终被标记
+func priceStat(history []models.GfgGamePriceHistory) models.PriceStatVo {
+	current := history[len(history)-1]
+	stat := models.PriceStatVo{
+		Region:   current.Region,
+		Currency: current.Currency,
+		Current:  current.Final,
+		Discount: current.Discount,
+		Low:      current.Final,
+		LowTime:  current.CreateTime,
+		High:     current.Final,
+		HighTime: current.CreateTime,
+	}
+	for _, v := range history {
+		if v.Final <= stat.Low {
+			stat.Low, stat.LowTime = v.Final, v.CreateTime
+		}
+		if v.Final >= stat.High {
+			stat.High, stat.HighTime = v.Final, v.CreateTime
+		}
+	}
+	stat.HistoricalLow = current.Discount > 0 && current.Final <= stat.Low
+	return stat
+}
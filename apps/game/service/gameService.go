@@ -16,6 +16,22 @@ var gameSingleton = new(gameService)
 
 func GetGameService() *gameService { return gameSingleton }
 
+// Redis 定义
+const (
+	redisGameInfoPrefix = "game-info:" // 主页分组 game-info:latest 等, 由定时任务写入
+	redisGameCreatorKey = "game-creator:list"
+)
+
+// InvalidateInfoCache 清除主页分组缓存
+func (s gameService) InvalidateInfoCache() common.GFError {
+	return cs.DelByPrefix(redisGameInfoPrefix)
+}
+
+// InvalidateCreatorCache 清除相关作者缓存
+func (s gameService) InvalidateCreatorCache() common.GFError {
+	return cs.Del(redisGameCreatorKey)
+}
+
 // 查询 weight 前 num 条游戏记录
 func (s gameService) GetGameList(num string, lang string) (gameVo []models.GameRespVo, err common.GFError) {
 	intNum, parseErr := util.String2Int(num)
@@ -79,7 +95,7 @@ func (s gameService) GetGameList(num string, lang string) (gameVo []models.GameR
 }
 
 func (s gameService) GetGameMainList() (res models.GameMainInfoVo, err common.GFError) {
-	jsonStr, err := cs.GetString("game-info:latest")
+	jsonStr, err := cs.GetString(redisGameInfoPrefix + "latest")
 	if err != nil {
 		return res, err
 	}
@@ -88,7 +104,7 @@ func (s gameService) GetGameMainList() (res models.GameMainInfoVo, err common.GF
 		return res, common.NewServiceError(err.GetMsg())
 	}
 
-	jsonStr, err = cs.GetString("game-info:recent")
+	jsonStr, err = cs.GetString(redisGameInfoPrefix + "recent")
 	if err != nil {
 		return res, err
 	}
@@ -97,7 +113,7 @@ func (s gameService) GetGameMainList() (res models.GameMainInfoVo, err common.GF
 		return res, common.NewServiceError(err.GetMsg())
 	}
 
-	jsonStr, err = cs.GetString("game-info:hot")
+	jsonStr, err = cs.GetString(redisGameInfoPrefix + "hot")
 	if err != nil {
 		return res, err
 	}
@@ -106,7 +122,7 @@ func (s gameService) GetGameMainList() (res models.GameMainInfoVo, err common.GF
 		return res, common.NewServiceError(err.GetMsg())
 	}
 
-	jsonStr, err = cs.GetString("game-info:free")
+	jsonStr, err = cs.GetString(redisGameInfoPrefix + "free")
 	if err != nil {
 		return res, err
 	}
@@ -305,7 +321,7 @@ func (s gameService) GetGameRemark(id string) (res models.GameRemarkVo, err comm
 }
 
 func (s gameService) GetGameCreator(lang string) (res []models.CreatorVo, err common.GFError) {
-	record, err := cs.GetString("game-creator:list")
+	record, err := cs.GetString(redisGameCreatorKey)
 	if err != nil {
 		return res, err
 	}
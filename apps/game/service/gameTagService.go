@@ -0,0 +1,201 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/game/dao"
+	"github.com/GoFurry/gofurry-game-backend/apps/game/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+)
+
+type gameTagService struct{}
+
+var gameTagSingleton = new(gameTagService)
+
+func GetGameTagService() *gameTagService { return gameTagSingleton }
+
+// 根标签的 prefix
+const rootTagPrefix int64 = -1
+
+// tagNode 构建标签树使用的节点
+type tagNode struct {
+	id       int64
+	name     string
+	prefix   int64
+	games    []int64
+	children []int64
+}
+
+// 获取标签树, 子树游戏数按游戏去重
+// 父标签不存在或父子关系成环时不会死循环, 相关标签作为根节点展示并在结果中列出
+func (s gameTagService) GetTagTree(lang string) (res models.TagTreeVo, err common.GFError) {
+	tags, err := dao.GetGameDao().GetTagList(lang)
+	if err != nil {
+		return
+	}
+	tagGames, err := dao.GetGameDao().GetTagGames()
+	if err != nil {
+		return
+	}
+
+	nodes := make(map[int64]*tagNode, len(tags))
+	ids := make([]int64, 0, len(tags))
+	for _, v := range tags {
+		id, parseErr := util.String2Int64(v.ID)
+		prefix, prefixErr := util.String2Int64(v.Prefix)
+		if parseErr != nil || prefixErr != nil {
+			continue
+		}
+		nodes[id] = &tagNode{id: id, name: v.Name, prefix: prefix}
+		ids = append(ids, id)
+	}
+	for _, v := range tagGames {
+		if node, ok := nodes[v.TagID]; ok {
+			node.games = append(node.games, v.GameID)
+		}
+	}
+
+	res.Tree, res.Orphans, res.Cycles = []models.TagNodeVo{}, []models.TagIssueVo{}, [][]models.TagIssueVo{}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	var roots []int64
+	for _, id := range ids {
+		node := nodes[id]
+		switch parent, ok := nodes[node.prefix]; {
+		case node.prefix == rootTagPrefix:
+			roots = append(roots, id)
+		case !ok:
+			roots = append(roots, id)
+			res.Orphans = append(res.Orphans, tagIssueVo(node))
+		default:
+			parent.children = append(parent.children, id)
+		}
+	}
+
+	// 从根节点无法到达的标签必然在环上或挂在环下
+	visited := make(map[int64]bool, len(nodes))
+	for _, id := range roots {
+		markTagSubtree(nodes, id, visited)
+	}
+	for _, id := range ids {
+		if visited[id] {
+			continue
+		}
+		cycle := findTagCycle(nodes, id, visited)
+		if len(cycle) == 0 {
+			continue
+		}
+		// 环内 ID 最小的标签断开与父标签的关系
+		head := cycle[0]
+		for _, v := range cycle {
+			if v < head {
+				head = v
+			}
+		}
+		parent := nodes[nodes[head].prefix]
+		parent.children = removeTagID(parent.children, head)
+		roots = append(roots, head)
+		markTagSubtree(nodes, head, visited)
+
+		issue := make([]models.TagIssueVo, len(cycle))
+		for i, v := range cycle {
+			issue[i] = tagIssueVo(nodes[v])
+		}
+		res.Cycles = append(res.Cycles, issue)
+	}
+	if len(res.Orphans) > 0 || len(res.Cycles) > 0 {
+		log.Warnf("GetTagTree 发现 %d 个父标签不存在的标签, %d 个环", len(res.Orphans), len(res.Cycles))
+	}
+
+	for _, id := range roots {
+		vo, _ := buildTagNodeVo(nodes, id)
+		res.Tree = append(res.Tree, vo)
+	}
+	sortTagNodes(res.Tree)
+	return res, nil
+}
+
+// markTagSubtree 标记 id 及其所有子孙为已访问
+func markTagSubtree(nodes map[int64]*tagNode, id int64, visited map[int64]bool) {
+	stack := []int64{id}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[cur] {
+			continue
+		}
+		visited[cur] = true
+		stack = append(stack, nodes[cur].children...)
+	}
+}
+
+// findTagCycle 沿父标签向上查找, 返回遇到的环, 到达已访问的标签时返回空
+func findTagCycle(nodes map[int64]*tagNode, id int64, visited map[int64]bool) []int64 {
+	index := map[int64]int{}
+	var path []int64
+	for cur := id; !visited[cur]; cur = nodes[cur].prefix {
+		if i, ok := index[cur]; ok {
+			return path[i:]
+		}
+		index[cur] = len(path)
+		path = append(path, cur)
+	}
+	return nil
+}
+
+// buildTagNodeVo 后序遍历生成节点, 同时返回子树内的游戏集合
+func buildTagNodeVo(nodes map[int64]*tagNode, id int64) (models.TagNodeVo, map[int64]struct{}) {
+	node := nodes[id]
+	games := make(map[int64]struct{}, len(node.games))
+	for _, v := range node.games {
+		games[v] = struct{}{}
+	}
+	vo := models.TagNodeVo{
+		ID:        util.Int642String(node.id),
+		Name:      node.name,
+		Prefix:    util.Int642String(node.prefix),
+		GameCount: len(games),
+		Children:  make([]models.TagNodeVo, 0, len(node.children)),
+	}
+	for _, child := range node.children {
+		childVo, childGames := buildTagNodeVo(nodes, child)
+		vo.Children = append(vo.Children, childVo)
+		for v := range childGames {
+			games[v] = struct{}{}
+		}
+	}
+	vo.TotalCount = len(games)
+	return vo, games
+}
+
+// sortTagNodes 按子树游戏数降序, 相同时按名称排序
+func sortTagNodes(list []models.TagNodeVo) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].TotalCount != list[j].TotalCount {
+			return list[i].TotalCount > list[j].TotalCount
+		}
+		return list[i].Name < list[j].Name
+	})
+	for _, v := range list {
+		sortTagNodes(v.Children)
+	}
+}
+
+func removeTagID(list []int64, id int64) []int64 {
+	res := list[:0]
+	for _, v := range list {
+		if v != id {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func tagIssueVo(node *tagNode) models.TagIssueVo {
+	return models.TagIssueVo{
+		ID:     util.Int642String(node.id),
+		Name:   node.name,
+		Prefix: util.Int642String(node.prefix),
+	}
+}
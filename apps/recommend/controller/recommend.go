@@ -1,8 +1,10 @@
 package controller
 
 import (
+	"github.com/GoFurry/gofurry-game-backend/apps/recommend/models"
 	"github.com/GoFurry/gofurry-game-backend/apps/recommend/service"
 	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/abstract"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -39,15 +41,100 @@ func (api *recommendApi) GetRandomGameID(c *fiber.Ctx) error {
 // @Produce json
 // @Param id query string true "初始id"
 // @Param lang query string true "语言"
+// @Param limit query int false "返回数量, 默认 8, 最大 30"
+// @Param diversity query number false "多样性 0-1, 默认 0.3"
+// @Param seed query string false "随机探索种子, 相同 seed 结果相同"
 // @Success 200 {object} []models.GameRecommendVo
 // @Router /api/recommend/game/CBF [Get]
 func (api *recommendApi) RecommendByCBF(c *fiber.Ctx) error {
 	id := c.Query("id", "-1")
 	lang := c.Query("lang", "zh")
-	data, err := service.GetRecommendService().RecommendByCBF(id, lang)
+	data, err := service.GetRecommendService().RecommendByCBF(id, lang, recommendQuery(c))
 	if err != nil {
 		return common.NewResponse(c).Error(err.GetMsg())
 	}
 
 	return common.NewResponse(c).SuccessWithData(data)
 }
+
+// @Summary CF 返回游戏记录列表
+// @Schemes
+// @Description 基于匿名评分的协同过滤, 评分不足时回退到 CBF
+// @Tags Recommend
+// @Accept json
+// @Produce json
+// @Param id query string true "初始id"
+// @Param lang query string true "语言"
+// @Param limit query int false "返回数量, 默认 8, 最大 30"
+// @Param diversity query number false "多样性 0-1, 默认 0.3"
+// @Param seed query string false "随机探索种子, 相同 seed 结果相同"
+// @Success 200 {object} []models.GameRecommendVo
+// @Router /api/recommend/game/CF [Get]
+func (api *recommendApi) RecommendByCF(c *fiber.Ctx) error {
+	id := c.Query("id", "-1")
+	lang := c.Query("lang", "zh")
+	data, err := service.GetRecommendService().RecommendByCF(id, lang, recommendQuery(c))
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 混合推荐返回游戏记录列表
+// @Schemes
+// @Description 按配置的权重混合 CF 与 CBF 的相似度
+// @Tags Recommend
+// @Accept json
+// @Produce json
+// @Param id query string true "初始id"
+// @Param lang query string true "语言"
+// @Param limit query int false "返回数量, 默认 8, 最大 30"
+// @Param diversity query number false "多样性 0-1, 默认 0.3"
+// @Param seed query string false "随机探索种子, 相同 seed 结果相同"
+// @Success 200 {object} []models.GameRecommendVo
+// @Router /api/recommend/game/hybrid [Get]
+func (api *recommendApi) RecommendByHybrid(c *fiber.Ctx) error {
+	id := c.Query("id", "-1")
+	lang := c.Query("lang", "zh")
+	data, err := service.GetRecommendService().RecommendByHybrid(id, lang, recommendQuery(c))
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 按一组游戏推荐
+// @Schemes
+// @Description 由多个游戏的标签构建偏好向量, 可指定权重与不喜欢的游戏, 结果排除输入的游戏并附带推荐理由
+// @Tags Recommend
+// @Accept json
+// @Produce json
+// @Param body body models.ProfileRecommendRequest true "请求body"
+// @Success 200 {object} []models.ProfileRecommendVo
+// @Router /api/recommend/game/profile [Post]
+func (api *recommendApi) RecommendByProfile(c *fiber.Ctx) error {
+	req := models.ProfileRecommendRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	data, err := service.GetRecommendService().RecommendByProfile(req)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// recommendQuery 读取推荐结果的重排参数
+func recommendQuery(c *fiber.Ctx) models.RecommendQuery {
+	return models.RecommendQuery{
+		Limit:     c.Query("limit"),
+		Diversity: c.Query("diversity"),
+		Seed:      c.Query("seed"),
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	gm "github.com/GoFurry/gofurry-game-backend/apps/game/models"
 	"github.com/GoFurry/gofurry-game-backend/apps/recommend/models"
+	rm "github.com/GoFurry/gofurry-game-backend/apps/review/models"
 	"github.com/GoFurry/gofurry-game-backend/common"
 	"github.com/GoFurry/gofurry-game-backend/common/abstract"
 	"gorm.io/gorm"
@@ -21,7 +22,10 @@ type recommendDao struct{ abstract.Dao }
 func GetRecommendDao() *recommendDao { return newRecommendDao }
 
 func (dao recommendDao) GetTagMappingList() (res []models.GfgTagMap, gfError common.GFError) {
-	db := dao.Gm.Table(models.TableNameGfgTagMap).Find(&res)
+	db := dao.Gm.Table(models.TableNameGfgTagMap).
+		Where("deleted IS NOT TRUE").
+		Where("game_id IN (?)", dao.Gm.Table(gm.TableNameGfgGame).Select("id").Where("deleted IS NOT TRUE")).
+		Find(&res)
 	if err := db.Error; err != nil {
 		return res, common.NewDaoError(err.Error())
 	}
@@ -29,7 +33,19 @@ func (dao recommendDao) GetTagMappingList() (res []models.GfgTagMap, gfError com
 }
 
 func (dao recommendDao) GetTagList() (res []models.GfgTag, gfError common.GFError) {
-	db := dao.Gm.Table(models.TableNameGfgTag).Find(&res)
+	db := dao.Gm.Table(models.TableNameGfgTag).Where("deleted IS NOT TRUE").Find(&res)
+	if err := db.Error; err != nil {
+		return res, common.NewDaoError(err.Error())
+	}
+	return res, nil
+}
+
+// GetReviewScores 未删除游戏的评分, 评分人以 ip 与名称区分
+func (dao recommendDao) GetReviewScores() (res []models.ReviewScoreModel, gfError common.GFError) {
+	db := dao.Gm.Table(rm.TableNameGfgGameComment).
+		Select("game_id, ip || '|' || COALESCE(name, '') AS rater, score").
+		Where("game_id IN (?)", dao.Gm.Table(gm.TableNameGfgGame).Select("id").Where("deleted IS NOT TRUE")).
+		Find(&res)
 	if err := db.Error; err != nil {
 		return res, common.NewDaoError(err.Error())
 	}
@@ -37,7 +53,7 @@ func (dao recommendDao) GetTagList() (res []models.GfgTag, gfError common.GFErro
 }
 
 func (dao recommendDao) GetRecommend(gameIDs []int64, lang string) (res []models.GameTemp, gfError common.GFError) {
-	db := dao.Gm.Table(gm.TableNameGfgGame).Where("id IN ?", gameIDs)
+	db := dao.Gm.Table(gm.TableNameGfgGame).Where("id IN ? AND deleted IS NOT TRUE", gameIDs)
 
 	if err := db.Find(&res).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
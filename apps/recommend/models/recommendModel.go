@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/xml"
+
 	cm "github.com/GoFurry/gofurry-game-backend/common/models"
 )
 
@@ -14,8 +16,10 @@ type GfgTag struct {
 	Info       string       `gorm:"column:info;type:character varying(255);not null;comment:标签简介" json:"info"`                        // 标签简介
 	InfoEn     string       `gorm:"column:info_en;type:character varying(255);not null;comment:标签英文简介" json:"infoEn"`                 // 标签英文简介
 	Prefix     int64        `gorm:"column:prefix;type:bigint;not null;comment:父标签 没有为-1" json:"prefix"`                               // 父标签 没有为-1
+	Weight     float64      `gorm:"column:weight;type:double precision;not null;default:1;comment:推荐权重" json:"weight"`                // 推荐权重
 	CreateTime cm.LocalTime `gorm:"column:create_time;type:int;type:unsigned;not null;autoCreateTime;comment:创建时间" json:"createTime"` // 创建时间
 	UpdateTime cm.LocalTime `gorm:"column:update_time;type:int;type:unsigned;not null;autoUpdateTime;comment:修改时间" json:"updateTime"` // 修改时间
+	Deleted    bool         `gorm:"column:deleted;type:boolean;comment:软删除" json:"deleted"`                                           // 软删除
 }
 
 // TableName GfgTag's table name
@@ -30,8 +34,10 @@ type GfgTagMap struct {
 	ID         int64        `gorm:"column:id;type:bigint;primaryKey;comment:游戏标签映射表id" json:"id"`                                     // 游戏标签映射表id
 	GameID     int64        `gorm:"column:game_id;type:bigint;not null;comment:游戏id" json:"gameId,string"`                            // 游戏id
 	TagID      int64        `gorm:"column:tag_id;type:bigint;not null;comment:标签id" json:"tagId,string"`                              // 标签id
+	Weight     float64      `gorm:"column:weight;type:double precision;not null;default:1;comment:该游戏下的标签权重" json:"weight"`           // 该游戏下的标签权重
 	CreateTime cm.LocalTime `gorm:"column:create_time;type:int;type:unsigned;not null;autoCreateTime;comment:创建时间" json:"createTime"` // 创建时间
 	UpdateTime cm.LocalTime `gorm:"column:update_time;type:int;type:unsigned;not null;autoUpdateTime;comment:修改时间" json:"updateTime"` // 修改时间
+	Deleted    bool         `gorm:"column:deleted;type:boolean;comment:软删除" json:"deleted"`                                           // 软删除
 }
 
 // TableName GfgTagMap's table name
@@ -39,12 +45,47 @@ func (*GfgTagMap) TableName() string {
 	return TableNameGfgTagMap
 }
 
+// TagMappingModel 游戏关联的标签及映射权重, 缓存于 recommend:tag-mapping
+type TagMappingModel struct {
+	TagID  int64   `json:"t"`
+	Weight float64 `json:"w"`
+}
+
+// ContentSimilarities Tag 为标签维度索引, Weight 为对应维度的权重
 type ContentSimilarities struct {
 	ID         int64
 	Tag        []float64
+	Weight     []float64
 	Similarity float64
 }
 
+// CBFNeighborModel 预计算的相似游戏, 缓存于 recommend:cbf-index
+type CBFNeighborModel struct {
+	ID         int64   `json:"i"`
+	Similarity float64 `json:"s"`
+}
+
+// CBFFeatureModel 游戏的标签向量, 缓存于 recommend:cbf-features
+// 维度为标签 ID 而不是向量下标, 不同时间写入的向量之间可以直接比较
+type CBFFeatureModel struct {
+	Tag    []int64   `json:"t"`
+	Weight []float64 `json:"w"`
+}
+
+// ReviewScoreModel 评分人对游戏的评分
+type ReviewScoreModel struct {
+	GameID int64   `gorm:"column:game_id"`
+	Rater  string  `gorm:"column:rater"`
+	Score  float64 `gorm:"column:score"`
+}
+
+// CFModel 基于评分的物品相似度, Neighbors 按游戏缓存于 recommend:cf-index, 其余字段缓存于 recommend:cf-index-state
+type CFModel struct {
+	Neighbors  map[int64][]CBFNeighborModel `json:"neighbors,omitempty"`
+	Raters     int                          `json:"raters"`
+	UpdateTime cm.LocalTime                 `json:"updateTime"`
+}
+
 // 按 Similarity 排序
 type BySimilarity []ContentSimilarities
 
@@ -60,6 +101,46 @@ type GameRecommendVo struct {
 	Info       string  `json:"info"`
 	Similarity float64 `json:"similarity"`
 	Appid      string  `json:"appid"`
+	Source     string  `json:"source,omitempty"` // 推荐来源 cbf/cf/hybrid
+}
+
+// RecommendQuery 推荐结果的重排参数, 均为可选
+type RecommendQuery struct {
+	Limit     string // 返回数量, 默认 8, 最大 30
+	Diversity string // 0-1, 越大结果越分散, 默认 0.3
+	Seed      string // 传入时开启随机探索, 相同 seed 结果相同
+}
+
+// ProfileRecommendRequest 按一组游戏推荐, 用于前端的收藏列表
+type ProfileRecommendRequest struct {
+	Seeds     []ProfileSeedRequest `json:"seeds" validate:"required,min=1,max=50,dive" label:"游戏列表"`
+	Negatives []string             `json:"negatives" validate:"max=50,dive,number" label:"不喜欢的游戏"`
+	Lang      string               `json:"lang" validate:"omitempty,oneof=zh en" label:"语言"`
+	Limit     int                  `json:"limit" validate:"omitempty,min=1,max=30" label:"数量"`
+}
+
+// ProfileSeedRequest 游戏及其在偏好中的权重, 权重不传时为 1
+type ProfileSeedRequest struct {
+	ID     string  `json:"id" validate:"required,number" label:"游戏ID"`
+	Weight float64 `json:"weight" validate:"omitempty,gt=0,lte=10" label:"权重"`
+}
+
+// ProfileRecommendVo 按一组游戏推荐的结果
+type ProfileRecommendVo struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Info    string            `json:"info"`
+	Appid   string            `json:"appid"`
+	Score   float64           `json:"score"`
+	Reasons []ProfileReasonVo `json:"reasons"` // 贡献最大的共同标签
+}
+
+// ProfileReasonVo 推荐理由, Contribution 为该标签占得分的比例
+type ProfileReasonVo struct {
+	TagID        string   `json:"tag_id"`
+	Name         string   `json:"name"`
+	Contribution float64  `json:"contribution"`
+	Seeds        []string `json:"seeds"` // 输入中带有该标签的游戏
 }
 
 type GameTemp struct {
@@ -70,3 +151,85 @@ type GameTemp struct {
 	InfoEn string `gorm:"column:info_en"`
 	Appid  string `gorm:"column:appid"`
 }
+
+// TagCooccurrenceModel 标签共现统计, 整体缓存到 Redis
+type TagCooccurrenceModel struct {
+	Games      int            `json:"games"`       // 至少关联一个标签的游戏数
+	Counts     map[int64]int  `json:"counts"`      // 标签关联的游戏数
+	Edges      []TagEdgeModel `json:"edges"`       // 共现过的标签对, Source < Target
+	UpdateTime cm.LocalTime   `json:"update_time"` // 统计时间
+}
+
+type TagEdgeModel struct {
+	Source  int64   `json:"source"`
+	Target  int64   `json:"target"`
+	Count   int     `json:"count"`   // 同时关联两个标签的游戏数
+	Jaccard float64 `json:"jaccard"` // 交集 / 并集
+	NPMI    float64 `json:"npmi"`    // 归一化点互信息 [-1, 1]
+}
+
+type TagRelatedVo struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	GameCount int     `json:"game_count"`
+	CoCount   int     `json:"co_count"`
+	Jaccard   float64 `json:"jaccard"`
+	NPMI      float64 `json:"npmi"`
+}
+
+type TagGraphVo struct {
+	Nodes      []TagGraphNodeVo `json:"nodes"`
+	Edges      []TagGraphEdgeVo `json:"edges"`
+	UpdateTime cm.LocalTime     `json:"update_time"`
+}
+
+type TagGraphNodeVo struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	GameCount int    `json:"game_count"`
+}
+
+type TagGraphEdgeVo struct {
+	Source string  `json:"source"`
+	Target string  `json:"target"`
+	Weight float64 `json:"weight"` // Jaccard
+	Count  int     `json:"count"`
+	NPMI   float64 `json:"npmi"`
+}
+
+// GraphML 1.0
+type GraphML struct {
+	XMLName xml.Name     `xml:"http://graphml.graphdrawing.org/xmlns graphml"`
+	Keys    []GraphMLKey `xml:"key"`
+	Graph   GraphMLGraph `xml:"graph"`
+}
+
+type GraphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type GraphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []GraphMLNode `xml:"node"`
+	Edges       []GraphMLEdge `xml:"edge"`
+}
+
+type GraphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []GraphMLData `xml:"data"`
+}
+
+type GraphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []GraphMLData `xml:"data"`
+}
+
+type GraphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
@@ -0,0 +1,393 @@
+package service
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/recommend/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	cs "github.com/GoFurry/gofurry-game-backend/common/service"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+	"github.com/bytedance/sonic"
+)
+
+// Redis 定义, 除 redisCBFDimsKey 外均为 hash, field 为游戏 ID
+const (
+	redisCBFIndexKey   = "recommend:cbf-index"       // 相似游戏列表
+	redisCBFStateKey   = "recommend:cbf-index-state" // 构建索引时的标签映射指纹
+	redisCBFFeatureKey = "recommend:cbf-features"    // 标签向量, 重排时计算候选之间的相似度
+	redisCBFDimsKey    = "recommend:cbf-index-dims"  // 上次全量构建时的维度权重 map[tagID]weight
+)
+
+const (
+	// 每个游戏预计算的相似游戏数量, 覆盖最大返回数量下重排所需的候选集
+	cbfIndexTopK = rerankMaxLimit * rerankCandidateFactor
+	// 维度权重(标签权重 * IDF)相对上次全量构建的变化超过该比例时全量重建
+	cbfDimTolerance = 0.05
+)
+
+// 避免定时任务与后台修改触发的重建并发执行
+var cbfIndexMutex sync.Mutex
+
+// UpdateCBFIndex 预计算每个游戏的 top-K 相似游戏
+// 只重新计算标签映射发生变化的游戏, 其余游戏在原列表上修补, 无法保证结果准确时才整体重算该游戏
+// IDF 随任意映射变化而漂移, 不计入单个游戏的指纹; 累计漂移超过 cbfDimTolerance 或变化游戏过半时全量重建
+func (s recommendService) UpdateCBFIndex() common.GFError {
+	cbfIndexMutex.Lock()
+	defer cbfIndexMutex.Unlock()
+
+	tagMapping, tagWeights, err := getTagToMap()
+	if err != nil {
+		return err
+	}
+	features, indexToTagID, dimWeights := contentFeatures(tagMapping, tagWeights)
+	fingerprints := make(map[string]string, len(features))
+	for id := range features {
+		fingerprints[util.Int642String(id)] = mappingFingerprint(tagMapping[id])
+	}
+	dims := make(map[string]float64, len(dimWeights))
+	for idx, weight := range dimWeights {
+		dims[util.Int642String(indexToTagID[idx])] = weight
+	}
+
+	// 读取上次构建的状态, 读取失败或维度权重漂移时全量构建
+	oldState, stateErr := cs.HGetAll(redisCBFStateKey)
+	oldIndex, indexErr := cs.HGetAll(redisCBFIndexKey)
+	full := stateErr != nil || indexErr != nil || cbfDimsDrifted(dims)
+
+	// 映射变化、新增或缺少索引的游戏需要整体重算
+	changed := map[int64]struct{}{}
+	for key, fingerprint := range fingerprints {
+		_, indexed := oldIndex[key]
+		if full || oldState[key] != fingerprint || !indexed {
+			id, _ := util.String2Int64(key)
+			changed[id] = struct{}{}
+		}
+	}
+	// 修补需要记录变化游戏与所有游戏的相似度, 变化游戏过半时直接全量重算
+	if len(changed)*2 > len(features) {
+		full = true
+		for id := range features {
+			changed[id] = struct{}{}
+		}
+	}
+	// 已删除或不再关联标签的游戏
+	var removed []string
+	for key := range oldState {
+		if _, ok := fingerprints[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	// 缺少标签向量的游戏只补写向量, 不需要重算相似游戏
+	featureIDs, removedFeatures := staleCBFFeatures(fingerprints)
+	if len(changed) == 0 && len(removed) == 0 {
+		return saveCBFFeatures(features, indexToTagID, featureIDs, removedFeatures)
+	}
+
+	all := make([]models.ContentSimilarities, 0, len(features))
+	for _, feature := range features {
+		all = append(all, feature)
+	}
+
+	neighbors := make(map[int64][]models.CBFNeighborModel, len(changed))
+	patched, rebuilt := 0, 0
+	if full {
+		for id := range features {
+			neighbors[id] = topCBFNeighbors(cbfSimilarities(id, features, all))
+		}
+	} else {
+		// 整体重算变化的游戏, 同时记录它们与其他游戏的相似度用于修补
+		changedSims := make(map[int64]map[int64]float64, len(features))
+		for id := range changed {
+			sims := cbfSimilarities(id, features, all)
+			for _, v := range sims {
+				if changedSims[v.ID] == nil {
+					changedSims[v.ID] = map[int64]float64{}
+				}
+				changedSims[v.ID][id] = v.Similarity
+			}
+			neighbors[id] = topCBFNeighbors(sims)
+		}
+
+		// 其余游戏只需替换与变化游戏之间的相似度
+		for id := range features {
+			if _, ok := changed[id]; ok {
+				continue
+			}
+			key := util.Int642String(id)
+			var old []models.CBFNeighborModel
+			if sonic.Unmarshal([]byte(oldIndex[key]), &old) != nil {
+				neighbors[id] = topCBFNeighbors(cbfSimilarities(id, features, all))
+				rebuilt++
+				continue
+			}
+			list, ok := patchCBFNeighbors(old, changed, removed, changedSims[id])
+			if !ok {
+				list = topCBFNeighbors(cbfSimilarities(id, features, all))
+				rebuilt++
+			}
+			if !ok || !sameCBFNeighbors(old, list) {
+				neighbors[id] = list
+				patched++
+			}
+		}
+	}
+
+	// 先写索引与标签向量再写状态, 写入失败时下次任务会重新计算
+	indexMap := make(map[string]string, len(neighbors))
+	for id, list := range neighbors {
+		jsonRecord, jsonErr := sonic.Marshal(list)
+		if jsonErr != nil {
+			return common.NewServiceError("相似度索引序列化失败: " + jsonErr.Error())
+		}
+		indexMap[util.Int642String(id)] = string(jsonRecord)
+	}
+	if len(indexMap) > 0 {
+		if err = cs.HSetMap(redisCBFIndexKey, indexMap); err != nil {
+			return err
+		}
+	}
+	if len(removed) > 0 {
+		cs.HDel(redisCBFIndexKey, removed...)
+		cs.HDel(redisCBFStateKey, removed...)
+	}
+	for id := range changed {
+		featureIDs[id] = struct{}{}
+	}
+	if err = saveCBFFeatures(features, indexToTagID, featureIDs, removedFeatures); err != nil {
+		return err
+	}
+	stateMap := make(map[string]string, len(changed))
+	for id := range changed {
+		key := util.Int642String(id)
+		stateMap[key] = fingerprints[key]
+	}
+	if len(stateMap) > 0 {
+		if err = cs.HSetMap(redisCBFStateKey, stateMap); err != nil {
+			return err
+		}
+	}
+	if full {
+		// 状态写入后再记录维度权重, 之前失败时下次任务仍会全量构建
+		jsonRecord, jsonErr := sonic.Marshal(dims)
+		if jsonErr != nil {
+			return common.NewServiceError("维度权重序列化失败: " + jsonErr.Error())
+		}
+		if err = cs.Set(redisCBFDimsKey, string(jsonRecord)); err != nil {
+			return err
+		}
+	}
+	log.Infof("UpdateCBFIndex 游戏 %d, 全量 %t, 重算 %d, 修补 %d, 回退重算 %d, 移除 %d",
+		len(features), full, len(changed), patched, rebuilt, len(removed))
+	return nil
+}
+
+// cbfDimsDrifted 与上次全量构建时的维度权重比较, 标签增减或任一权重相对变化超过 cbfDimTolerance 时返回 true
+func cbfDimsDrifted(dims map[string]float64) bool {
+	jsonStr, err := cs.GetString(redisCBFDimsKey)
+	if err != nil || jsonStr == "" {
+		return true
+	}
+	var old map[string]float64
+	if sonic.Unmarshal([]byte(jsonStr), &old) != nil || len(old) != len(dims) {
+		return true
+	}
+	for tagID, weight := range dims {
+		prev, ok := old[tagID]
+		if !ok || math.Abs(weight-prev) > cbfDimTolerance*math.Abs(prev) {
+			return true
+		}
+	}
+	return false
+}
+
+// staleCBFFeatures 返回缺少标签向量的游戏与需要移除的向量
+func staleCBFFeatures(fingerprints map[string]string) (map[int64]struct{}, []string) {
+	stale := map[int64]struct{}{}
+	existing := map[string]struct{}{}
+	if keys, err := cs.HKeys(redisCBFFeatureKey); err == nil {
+		for _, key := range keys {
+			existing[key] = struct{}{}
+		}
+	}
+	for key := range fingerprints {
+		if _, ok := existing[key]; !ok {
+			id, _ := util.String2Int64(key)
+			stale[id] = struct{}{}
+		}
+	}
+	var removed []string
+	for key := range existing {
+		if _, ok := fingerprints[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	return stale, removed
+}
+
+// saveCBFFeatures 写入 ids 中游戏的标签向量, 并移除 removed 中的向量
+func saveCBFFeatures(features map[int64]models.ContentSimilarities, indexToTagID []int64, ids map[int64]struct{}, removed []string) common.GFError {
+	featureMap := make(map[string]string, len(ids))
+	for id := range ids {
+		jsonRecord, jsonErr := sonic.Marshal(toCBFFeature(features[id], indexToTagID))
+		if jsonErr != nil {
+			return common.NewServiceError("标签向量序列化失败: " + jsonErr.Error())
+		}
+		featureMap[util.Int642String(id)] = string(jsonRecord)
+	}
+	if len(featureMap) > 0 {
+		if err := cs.HSetMap(redisCBFFeatureKey, featureMap); err != nil {
+			return err
+		}
+	}
+	if len(removed) > 0 {
+		cs.HDel(redisCBFFeatureKey, removed...)
+	}
+	return nil
+}
+
+// toCBFFeature 将维度下标转换为标签 ID
+func toCBFFeature(feature models.ContentSimilarities, indexToTagID []int64) models.CBFFeatureModel {
+	record := models.CBFFeatureModel{Tag: make([]int64, len(feature.Tag)), Weight: feature.Weight}
+	for i, idx := range feature.Tag {
+		record.Tag[i] = indexToTagID[int(idx)]
+	}
+	return record
+}
+
+// loadCBFFeatures 读取游戏的标签向量, 缺少向量的游戏不在结果中
+func loadCBFFeatures(ids []int64) (map[int64]models.CBFFeatureModel, common.GFError) {
+	fields := make([]string, len(ids))
+	for i, id := range ids {
+		fields[i] = util.Int642String(id)
+	}
+	values, err := cs.HMGet(redisCBFFeatureKey, fields...)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]models.CBFFeatureModel, len(ids))
+	for i, v := range values {
+		jsonStr, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var feature models.CBFFeatureModel
+		if sonic.Unmarshal([]byte(jsonStr), &feature) == nil {
+			res[ids[i]] = feature
+		}
+	}
+	return res, nil
+}
+
+// loadCBFNeighbors 读取预计算的相似游戏, 未命中时返回 false
+func loadCBFNeighbors(id int64) ([]models.ContentSimilarities, bool) {
+	jsonStr, err := cs.HGet(redisCBFIndexKey, util.Int642String(id))
+	if err != nil || jsonStr == "" {
+		return nil, false
+	}
+	var list []models.CBFNeighborModel
+	if sonic.Unmarshal([]byte(jsonStr), &list) != nil {
+		return nil, false
+	}
+	res := make([]models.ContentSimilarities, len(list))
+	for i, v := range list {
+		res[i] = models.ContentSimilarities{ID: v.ID, Similarity: v.Similarity}
+	}
+	return res, true
+}
+
+// cbfSimilarities 计算游戏与其他所有游戏的相似度, 按相似度降序
+func cbfSimilarities(id int64, features map[int64]models.ContentSimilarities, all []models.ContentSimilarities) []models.ContentSimilarities {
+	target := features[id]
+	if len(target.Tag) == 0 {
+		return nil
+	}
+	res := execSimilarity(target, all)
+	for i, v := range res {
+		if v.ID == id {
+			return append(res[:i], res[i+1:]...)
+		}
+	}
+	return res
+}
+
+// topCBFNeighbors 保留相似度最高的前 K 个游戏
+func topCBFNeighbors(sims []models.ContentSimilarities) []models.CBFNeighborModel {
+	res := make([]models.CBFNeighborModel, 0, min(len(sims), cbfIndexTopK))
+	for _, v := range sims {
+		res = append(res, models.CBFNeighborModel{ID: v.ID, Similarity: v.Similarity})
+		if len(res) >= cbfIndexTopK {
+			break
+		}
+	}
+	return res
+}
+
+// patchCBFNeighbors 用变化游戏的新相似度修补原列表
+// 原列表已满时, 未入选的游戏相似度不高于原列表末位; 修补后第 K 位低于该值说明可能有遗漏, 返回 false
+func patchCBFNeighbors(old []models.CBFNeighborModel, changed map[int64]struct{}, removed []string, sims map[int64]float64) ([]models.CBFNeighborModel, bool) {
+	removedSet := make(map[int64]struct{}, len(removed))
+	for _, key := range removed {
+		id, _ := util.String2Int64(key)
+		removedSet[id] = struct{}{}
+	}
+
+	list := make([]models.CBFNeighborModel, 0, len(old)+len(sims))
+	for _, v := range old {
+		_, isChanged := changed[v.ID]
+		_, isRemoved := removedSet[v.ID]
+		if !isChanged && !isRemoved {
+			list = append(list, v)
+		}
+	}
+	for id, sim := range sims {
+		list = append(list, models.CBFNeighborModel{ID: id, Similarity: sim})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Similarity != list[j].Similarity {
+			return list[i].Similarity > list[j].Similarity
+		}
+		return list[i].ID < list[j].ID
+	})
+
+	if len(old) >= cbfIndexTopK {
+		floor := old[len(old)-1].Similarity
+		if len(list) < cbfIndexTopK || list[cbfIndexTopK-1].Similarity < floor {
+			return nil, false
+		}
+	}
+	if len(list) > cbfIndexTopK {
+		list = list[:cbfIndexTopK]
+	}
+	return list, true
+}
+
+func sameCBFNeighbors(a []models.CBFNeighborModel, b []models.CBFNeighborModel) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// mappingFingerprint 按标签 ID 与映射权重生成指纹, 与映射顺序无关
+// 只包含游戏自身的映射, 其他游戏变化导致的 IDF 漂移由 cbfDimsDrifted 统一处理
+func mappingFingerprint(tags []models.TagMappingModel) string {
+	parts := make([]string, len(tags))
+	for i, tag := range tags {
+		parts[i] = util.Int642String(tag.TagID) + ":" + strconv.FormatFloat(tag.Weight, 'g', -1, 64)
+	}
+	sort.Strings(parts)
+	h := fnv.New64a()
+	h.Write([]byte(strings.Join(parts, ",")))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
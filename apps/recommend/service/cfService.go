@@ -0,0 +1,352 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/recommend/dao"
+	"github.com/GoFurry/gofurry-game-backend/apps/recommend/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	cm "github.com/GoFurry/gofurry-game-backend/common/models"
+	cs "github.com/GoFurry/gofurry-game-backend/common/service"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+	"github.com/GoFurry/gofurry-game-backend/roof/env"
+	"github.com/bytedance/sonic"
+)
+
+// Redis 定义
+const (
+	redisCFIndexKey = "recommend:cf-index"       // hash, field 为游戏 ID, 值为相似游戏列表
+	redisCFStateKey = "recommend:cf-index-state" // 模型概要, 存在时说明索引完整, 缺少 field 的游戏没有相似游戏
+	cfModelTimeout  = 3 * time.Hour              // 由每小时任务刷新
+)
+
+// 缓存未命中时只计算一次, 也避免与定时任务并发写入
+var cfModelMutex sync.Mutex
+
+const (
+	// 每个游戏保留的相似游戏数量, 与 cbfIndexTopK 一致以便重排
+	cfTopK = cbfIndexTopK
+	// 未配置时的默认值
+	defaultCFMinCommon = 2
+)
+
+// 推荐来源
+const (
+	SourceCBF    = "cbf"
+	SourceCF     = "cf"
+	SourceHybrid = "hybrid"
+)
+
+// RecommendByCF Collaborative Filter 返回评分模式与物品A最相似的物品
+// 没有足够评分的游戏回退到基于内容的推荐
+func (s recommendService) RecommendByCF(id string, lang string, query models.RecommendQuery) ([]models.GameRecommendVo, common.GFError) {
+	intID, parseErr := util.String2Int64(id)
+	if parseErr != nil {
+		return nil, common.NewServiceError(parseErr.Error())
+	}
+	opts, err := parseRecommendQuery(intID, query)
+	if err != nil {
+		return nil, err
+	}
+	return calcWithTimeout(id, func() ([]models.GameRecommendVo, common.GFError) {
+		similarities, err := getCFSimilarities(intID)
+		if err != nil {
+			return nil, err
+		}
+		source := SourceCF
+		if len(similarities) == 0 {
+			// 冷启动
+			source = SourceCBF
+			if similarities, err = getCBFSimilarities(intID); err != nil {
+				return nil, err
+			}
+		}
+		res, err := buildRecommendResult(intID, similarities, lang, opts)
+		for i := range res {
+			res[i].Source = source
+		}
+		return res, err
+	})
+}
+
+// RecommendByHybrid 按配置的权重混合 CF 与 CBF 的相似度
+// 只有一种来源有结果时直接使用该来源, 因此没有标签或没有评分的游戏也能得到推荐
+func (s recommendService) RecommendByHybrid(id string, lang string, query models.RecommendQuery) ([]models.GameRecommendVo, common.GFError) {
+	intID, parseErr := util.String2Int64(id)
+	if parseErr != nil {
+		return nil, common.NewServiceError(parseErr.Error())
+	}
+	opts, err := parseRecommendQuery(intID, query)
+	if err != nil {
+		return nil, err
+	}
+	return calcWithTimeout(id, func() ([]models.GameRecommendVo, common.GFError) {
+		cf, err := getCFSimilarities(intID)
+		if err != nil {
+			return nil, err
+		}
+		cbf, cbfErr := getCBFSimilarities(intID)
+		if cbfErr != nil && len(cf) == 0 {
+			return nil, cbfErr
+		}
+
+		similarities, sources := blendSimilarities(cf, cbf)
+		res, err := buildRecommendResult(intID, similarities, lang, opts)
+		for i := range res {
+			gameID, _ := util.String2Int64(res[i].ID)
+			res[i].Source = sources[gameID]
+		}
+		return res, err
+	})
+}
+
+// UpdateCFModelCache 重新计算基于评分的物品相似度并写入缓存
+func (s recommendService) UpdateCFModelCache() common.GFError {
+	cfModelMutex.Lock()
+	defer cfModelMutex.Unlock()
+	model, err := buildCFModel()
+	if err != nil {
+		return err
+	}
+	saveCFModel(model)
+	return nil
+}
+
+// blendSimilarities 加权混合两种相似度, 返回按相似度降序的结果与每个游戏的来源
+func blendSimilarities(cf []models.ContentSimilarities, cbf []models.ContentSimilarities) ([]models.ContentSimilarities, map[int64]string) {
+	conf := env.GetServerConfig().Recommend
+	cfWeight, cbfWeight := conf.CFWeight, conf.CBFWeight
+	if cfWeight+cbfWeight <= 0 {
+		cfWeight, cbfWeight = 1, 1
+	}
+	// 目标游戏缺少某种来源时, 该来源不参与加权
+	if len(cf) == 0 {
+		cfWeight = 0
+	}
+	if len(cbf) == 0 {
+		cbfWeight = 0
+	}
+	total := cfWeight + cbfWeight
+
+	scores := map[int64]float64{}
+	sources := map[int64]string{}
+	for _, v := range cf {
+		scores[v.ID] += v.Similarity * cfWeight / total
+		sources[v.ID] = SourceCF
+	}
+	for _, v := range cbf {
+		scores[v.ID] += v.Similarity * cbfWeight / total
+		if sources[v.ID] == SourceCF {
+			sources[v.ID] = SourceHybrid
+		} else {
+			sources[v.ID] = SourceCBF
+		}
+	}
+
+	res := make([]models.ContentSimilarities, 0, len(scores))
+	for id, score := range scores {
+		res = append(res, models.ContentSimilarities{ID: id, Similarity: score})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Similarity != res[j].Similarity {
+			return res[i].Similarity > res[j].Similarity
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res, sources
+}
+
+// getCBFSimilarities 优先读取预计算的索引, 未命中时现场计算
+func getCBFSimilarities(id int64) ([]models.ContentSimilarities, common.GFError) {
+	if neighbors, ok := loadCBFNeighbors(id); ok {
+		return neighbors, nil
+	}
+	return processContentBasedFilter(id)
+}
+
+// getCFSimilarities 获取游戏基于评分的相似游戏, 评分不足时为空
+// 只读取该游戏的 field, 索引未构建时现场计算整个模型
+func getCFSimilarities(id int64) ([]models.ContentSimilarities, common.GFError) {
+	if neighbors, ok := loadCFNeighbors(id); ok {
+		return neighbors, nil
+	}
+
+	cfModelMutex.Lock()
+	defer cfModelMutex.Unlock()
+	if neighbors, ok := loadCFNeighbors(id); ok {
+		return neighbors, nil
+	}
+	model, err := buildCFModel()
+	if err != nil {
+		return nil, err
+	}
+	saveCFModel(model)
+	return toSimilarities(model.Neighbors[id]), nil
+}
+
+// loadCFNeighbors 读取缓存的相似游戏, 索引未构建时返回 false
+func loadCFNeighbors(id int64) ([]models.ContentSimilarities, bool) {
+	if jsonStr, err := cs.HGet(redisCFIndexKey, util.Int642String(id)); err == nil && jsonStr != "" {
+		var list []models.CBFNeighborModel
+		if sonic.Unmarshal([]byte(jsonStr), &list) == nil {
+			return toSimilarities(list), true
+		}
+	}
+	// 索引完整时缺少 field 说明该游戏评分不足
+	state, err := cs.GetString(redisCFStateKey)
+	if err != nil || state == "" {
+		return nil, false
+	}
+	return []models.ContentSimilarities{}, true
+}
+
+func toSimilarities(list []models.CBFNeighborModel) []models.ContentSimilarities {
+	res := make([]models.ContentSimilarities, len(list))
+	for i, v := range list {
+		res[i] = models.ContentSimilarities{ID: v.ID, Similarity: v.Similarity}
+	}
+	return res
+}
+
+// buildCFModel 以调整余弦相似度计算游戏之间的相似度
+// 每个评分先减去评分人的平均分, 只统计同时评价过两个游戏的评分人
+func buildCFModel() (res models.CFModel, err common.GFError) {
+	records, err := dao.GetRecommendDao().GetReviewScores()
+	if err != nil {
+		return res, common.NewServiceError("获取评分记录失败: " + err.GetMsg())
+	}
+	conf := env.GetServerConfig().Recommend
+	minCommon := conf.CFMinCommon
+	if minCommon <= 0 {
+		minCommon = defaultCFMinCommon
+	}
+
+	// 同一评分人多次评价同一游戏时取平均分
+	type scoreSum struct {
+		sum   float64
+		count int
+	}
+	raters := map[string]map[int64]*scoreSum{}
+	for _, v := range records {
+		games := raters[v.Rater]
+		if games == nil {
+			games = map[int64]*scoreSum{}
+			raters[v.Rater] = games
+		}
+		if games[v.GameID] == nil {
+			games[v.GameID] = &scoreSum{}
+		}
+		games[v.GameID].sum += v.Score
+		games[v.GameID].count++
+	}
+
+	type gamePair struct{ a, b int64 }
+	type pairSum struct {
+		dot, normA, normB float64
+		common            int
+	}
+	pairs := map[gamePair]*pairSum{}
+	for _, games := range raters {
+		// 只评价过一个游戏的评分人对相似度没有贡献
+		if len(games) < 2 {
+			continue
+		}
+		res.Raters++
+		ids := make([]int64, 0, len(games))
+		mean := 0.0
+		for id, v := range games {
+			ids = append(ids, id)
+			mean += v.sum / float64(v.count)
+		}
+		mean /= float64(len(games))
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+		for i, a := range ids {
+			devA := games[a].sum/float64(games[a].count) - mean
+			for _, b := range ids[i+1:] {
+				devB := games[b].sum/float64(games[b].count) - mean
+				p := pairs[gamePair{a, b}]
+				if p == nil {
+					p = &pairSum{}
+					pairs[gamePair{a, b}] = p
+				}
+				p.dot += devA * devB
+				p.normA += devA * devA
+				p.normB += devB * devB
+				p.common++
+			}
+		}
+	}
+
+	res.Neighbors = map[int64][]models.CBFNeighborModel{}
+	for pair, p := range pairs {
+		if p.common < minCommon || p.normA == 0 || p.normB == 0 {
+			continue
+		}
+		sim := p.dot / math.Sqrt(p.normA*p.normB)
+		// 共同评分人数少时相似度不可靠, 按 n/(n+shrinkage) 收缩
+		sim *= float64(p.common) / (float64(p.common) + conf.CFShrinkage)
+		if sim <= 0 {
+			continue
+		}
+		sim = round4(sim)
+		res.Neighbors[pair.a] = append(res.Neighbors[pair.a], models.CBFNeighborModel{ID: pair.b, Similarity: sim})
+		res.Neighbors[pair.b] = append(res.Neighbors[pair.b], models.CBFNeighborModel{ID: pair.a, Similarity: sim})
+	}
+	for id, list := range res.Neighbors {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Similarity != list[j].Similarity {
+				return list[i].Similarity > list[j].Similarity
+			}
+			return list[i].ID < list[j].ID
+		})
+		if len(list) > cfTopK {
+			res.Neighbors[id] = list[:cfTopK]
+		}
+	}
+	res.UpdateTime = cm.LocalTime(time.Now())
+	return res, nil
+}
+
+// saveCFModel 先写入各游戏的相似游戏并移除已不存在的游戏, 最后写入概要
+func saveCFModel(model models.CFModel) {
+	indexMap := make(map[string]string, len(model.Neighbors))
+	for id, list := range model.Neighbors {
+		jsonRecord, jsonErr := sonic.Marshal(list)
+		if jsonErr != nil {
+			log.Error("cfModel 序列化失败: " + jsonErr.Error())
+			return
+		}
+		indexMap[util.Int642String(id)] = string(jsonRecord)
+	}
+	if len(indexMap) > 0 {
+		if err := cs.HSetMap(redisCFIndexKey, indexMap); err != nil {
+			log.Error("cfModel 缓存写入失败: " + err.GetMsg())
+			return
+		}
+	}
+	if keys, err := cs.HKeys(redisCFIndexKey); err == nil {
+		var removed []string
+		for _, key := range keys {
+			if _, ok := indexMap[key]; !ok {
+				removed = append(removed, key)
+			}
+		}
+		if len(removed) > 0 {
+			cs.HDel(redisCFIndexKey, removed...)
+		}
+	}
+
+	state, jsonErr := sonic.Marshal(models.CFModel{Raters: model.Raters, UpdateTime: model.UpdateTime})
+	if jsonErr != nil {
+		log.Error("cfModel 序列化失败: " + jsonErr.Error())
+		return
+	}
+	if err := cs.SetExpire(redisCFStateKey, string(state), cfModelTimeout); err != nil {
+		log.Error("cfModel 缓存写入失败: " + err.GetMsg())
+	}
+}
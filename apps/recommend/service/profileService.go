@@ -0,0 +1,215 @@
+package service
+
+import (
+	"math"
+	"sort"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/recommend/dao"
+	"github.com/GoFurry/gofurry-game-backend/apps/recommend/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+)
+
+const (
+	profileDefaultLimit = 8
+	// 不喜欢的游戏在偏好向量中的权重
+	profileNegativeWeight = 0.5
+	// 每个推荐结果展示的理由数
+	profileReasonLimit = 3
+)
+
+// RecommendByProfile 按一组游戏推荐
+// 每个游戏的特征归一化后按权重累加为偏好向量, 不喜欢的游戏按 profileNegativeWeight 扣减, 再与其他游戏计算余弦相似度
+func (s recommendService) RecommendByProfile(req models.ProfileRecommendRequest) ([]models.ProfileRecommendVo, common.GFError) {
+	seeds := map[int64]float64{}
+	var seedOrder []int64
+	for _, v := range req.Seeds {
+		id, parseErr := util.String2Int64(v.ID)
+		if parseErr != nil {
+			return nil, common.NewServiceError("游戏 ID 转换有误: " + v.ID)
+		}
+		if _, ok := seeds[id]; !ok {
+			seedOrder = append(seedOrder, id)
+		}
+		seeds[id] += normalizeWeight(v.Weight)
+	}
+	negatives := map[int64]struct{}{}
+	for _, v := range req.Negatives {
+		id, parseErr := util.String2Int64(v)
+		if parseErr != nil {
+			return nil, common.NewServiceError("游戏 ID 转换有误: " + v)
+		}
+		if _, ok := seeds[id]; ok {
+			return nil, common.NewServiceError("游戏不能同时出现在喜欢与不喜欢的列表中: " + v)
+		}
+		negatives[id] = struct{}{}
+	}
+	lang := req.Lang
+	if lang == "" {
+		lang = "zh"
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = profileDefaultLimit
+	}
+
+	return calcWithTimeout("profile", func() ([]models.ProfileRecommendVo, common.GFError) {
+		features, indexToTagID, err := buildContentFeatures()
+		if err != nil {
+			return nil, err
+		}
+
+		// 构建偏好向量 map[维度索引]值, 同时记录带有各维度标签的输入游戏
+		profile := map[int]float64{}
+		seedsByDim := map[int][]int64{}
+		valid := 0
+		for _, id := range seedOrder {
+			feature, ok := features[id]
+			if !ok || len(feature.Tag) == 0 {
+				continue
+			}
+			valid++
+			norm := magnitude(feature.Weight)
+			for i, idx := range feature.Tag {
+				dim := int(idx)
+				profile[dim] += seeds[id] * feature.Weight[i] / norm
+				seedsByDim[dim] = append(seedsByDim[dim], id)
+			}
+		}
+		if valid == 0 {
+			return nil, common.NewServiceError("所选游戏均不存在或未关联标签")
+		}
+		for id := range negatives {
+			feature, ok := features[id]
+			if !ok || len(feature.Tag) == 0 {
+				continue
+			}
+			norm := magnitude(feature.Weight)
+			for i, idx := range feature.Tag {
+				profile[int(idx)] -= profileNegativeWeight * feature.Weight[i] / norm
+			}
+		}
+		profileNorm := 0.0
+		for _, v := range profile {
+			profileNorm += v * v
+		}
+		profileNorm = math.Sqrt(profileNorm)
+		if profileNorm == 0 {
+			return []models.ProfileRecommendVo{}, nil
+		}
+
+		// 为其他游戏打分, 排除输入的游戏
+		type scored struct {
+			id            int64
+			score         float64
+			contributions map[int]float64
+		}
+		var candidates []scored
+		for id, feature := range features {
+			_, isSeed := seeds[id]
+			_, isNegative := negatives[id]
+			if isSeed || isNegative || len(feature.Tag) == 0 {
+				continue
+			}
+			norm := magnitude(feature.Weight) * profileNorm
+			item := scored{id: id, contributions: map[int]float64{}}
+			for i, idx := range feature.Tag {
+				value := profile[int(idx)] * feature.Weight[i] / norm
+				if value == 0 {
+					continue
+				}
+				item.score += value
+				if value > 0 {
+					item.contributions[int(idx)] = value
+				}
+			}
+			if item.score > 0 {
+				candidates = append(candidates, item)
+			}
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			if candidates[i].score != candidates[j].score {
+				return candidates[i].score > candidates[j].score
+			}
+			return candidates[i].id < candidates[j].id
+		})
+		if len(candidates) > limit {
+			candidates = candidates[:limit]
+		}
+		if len(candidates) == 0 {
+			return []models.ProfileRecommendVo{}, nil
+		}
+
+		gameIDs := make([]int64, len(candidates))
+		for i, v := range candidates {
+			gameIDs[i] = v.id
+		}
+		gameList, err := dao.GetRecommendDao().GetRecommend(gameIDs, lang)
+		if err != nil {
+			return nil, common.NewServiceError(err.GetMsg())
+		}
+		games := make(map[int64]models.GameTemp, len(gameList))
+		for _, v := range gameList {
+			games[v.ID] = v
+		}
+		tagNames, err := getTagNames(lang)
+		if err != nil {
+			return nil, err
+		}
+
+		res := make([]models.ProfileRecommendVo, 0, len(candidates))
+		for _, v := range candidates {
+			game, ok := games[v.id]
+			if !ok {
+				continue
+			}
+			vo := models.ProfileRecommendVo{
+				ID:      util.Int642String(game.ID),
+				Name:    game.NameZh,
+				Info:    game.InfoZh,
+				Appid:   game.Appid,
+				Score:   round4(v.score),
+				Reasons: profileReasons(v.contributions, v.score, indexToTagID, tagNames, seedsByDim),
+			}
+			if lang == "en" {
+				vo.Name = game.NameEn
+				vo.Info = game.InfoEn
+			}
+			res = append(res, vo)
+		}
+		return res, nil
+	})
+}
+
+// profileReasons 取贡献最大的几个共同标签作为推荐理由
+func profileReasons(contributions map[int]float64, score float64, indexToTagID []int64, tagNames map[int64]string, seedsByDim map[int][]int64) []models.ProfileReasonVo {
+	dims := make([]int, 0, len(contributions))
+	for dim := range contributions {
+		dims = append(dims, dim)
+	}
+	sort.Slice(dims, func(i, j int) bool {
+		if contributions[dims[i]] != contributions[dims[j]] {
+			return contributions[dims[i]] > contributions[dims[j]]
+		}
+		return dims[i] < dims[j]
+	})
+	if len(dims) > profileReasonLimit {
+		dims = dims[:profileReasonLimit]
+	}
+
+	res := make([]models.ProfileReasonVo, 0, len(dims))
+	for _, dim := range dims {
+		tagID := indexToTagID[dim]
+		reason := models.ProfileReasonVo{
+			TagID:        util.Int642String(tagID),
+			Name:         tagNames[tagID],
+			Contribution: round4(contributions[dim] / score),
+			Seeds:        make([]string, 0, len(seedsByDim[dim])),
+		}
+		for _, id := range seedsByDim[dim] {
+			reason.Seeds = append(reason.Seeds, util.Int642String(id))
+		}
+		res = append(res, reason)
+	}
+	return res
+}
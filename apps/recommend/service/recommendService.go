@@ -19,7 +19,6 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	gd "github.com/GoFurry/gofurry-game-backend/apps/game/dao"
-	gm "github.com/GoFurry/gofurry-game-backend/apps/game/models"
 )
 
 type recommendService struct{}
@@ -31,10 +30,22 @@ func GetRecommendService() *recommendService { return recommendSingleton }
 // Redis 定义
 const (
 	redisTagMappingKey = "recommend:tag-mapping"
-	redisTagIDsKey     = "recommend:tag-ids"
+	redisTagWeightsKey = "recommend:tag-weights"
 	cacheExpireTime    = 1 * time.Hour // 缓存过期时间
 )
 
+// InvalidateTagCache 清除标签映射、权重与共现统计缓存, 下次使用时重新读取
+// 相似游戏索引由 UpdateCBFIndex 增量更新, 不在此清除
+func (s recommendService) InvalidateTagCache() common.GFError {
+	return cs.Del(redisTagMappingKey, redisTagWeightsKey, redisTagCooccurrenceKey)
+}
+
+// InvalidateCache 清除推荐模块的全部缓存, 包括预计算的相似游戏索引, 用于整体替换数据后
+func (s recommendService) InvalidateCache() common.GFError {
+	return cs.Del(redisTagMappingKey, redisTagWeightsKey, redisTagCooccurrenceKey,
+		redisCBFIndexKey, redisCBFStateKey, redisCBFFeatureKey, redisCBFDimsKey, redisCFIndexKey, redisCFStateKey)
+}
+
 const (
 	// 推荐计算的超时时间
 	recommendCalcTimeout = 3 * time.Second
@@ -49,7 +60,7 @@ var once sync.Once
 
 // 随机获取一个 GameID
 func (s recommendService) GetRandomGameID() (string, common.GFError) {
-	count, err := gd.GetGameDao().Count(gm.GfgGame{})
+	count, err := gd.GetGameDao().CountGame()
 	if err != nil {
 		return "", common.NewServiceError("统计数量出错")
 	}
@@ -71,19 +82,35 @@ func (s recommendService) GetRandomGameID() (string, common.GFError) {
 }
 
 // RecommendByCBF Content-based Filter 返回物品A的余弦相似度最高的物品
-func (s recommendService) RecommendByCBF(id string, lang string) (gameListVo []models.GameRecommendVo, err common.GFError) {
+func (s recommendService) RecommendByCBF(id string, lang string, query models.RecommendQuery) (gameListVo []models.GameRecommendVo, err common.GFError) {
 	intID, parseErr := util.String2Int64(id)
 	if parseErr != nil {
 		return nil, common.NewServiceError(parseErr.Error())
 	}
+	opts, err := parseRecommendQuery(intID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// 优先使用预计算的相似度索引, 未命中时现场计算
+	if neighbors, ok := loadCBFNeighbors(intID); ok {
+		return buildRecommendResult(intID, neighbors, lang, opts)
+	}
 
+	return calcWithTimeout(id, func() ([]models.GameRecommendVo, common.GFError) {
+		return getGameCBF(intID, lang, opts)
+	})
+}
+
+// calcWithTimeout 在任务池中执行推荐计算, 超过 recommendCalcTimeout 时返回超时
+func calcWithTimeout[T any](id string, calc func() (T, common.GFError)) (res T, err common.GFError) {
 	// 创建根上下文
 	rootCtx, rootCancel := context.WithTimeout(context.Background(), recommendCalcTimeout)
 	defer rootCancel()
 
 	// 用 errgroup 管理异步任务
 	g, ctx := errgroup.WithContext(rootCtx)
-	resultChan := make(chan []models.GameRecommendVo, 1)
+	resultChan := make(chan T, 1)
 	errChan := make(chan common.GFError, 1)
 
 	// 异步执行计算任务
@@ -102,7 +129,7 @@ func (s recommendService) RecommendByCBF(id string, lang string) (gameListVo []m
 		case <-ctx.Done(): // 任务还没开始就超时
 			return ctx.Err()
 		default:
-			res, e := getGameCBF(intID, lang)
+			res, e := calc()
 
 			if e != nil {
 				errChan <- e
@@ -117,7 +144,7 @@ func (s recommendService) RecommendByCBF(id string, lang string) (gameListVo []m
 	waitErr := g.Wait()
 	// 先判断是否是根上下文超时
 	if rootCtx.Err() == context.DeadlineExceeded {
-		return nil, common.NewServiceError(fmt.Sprintf("推荐计算超时(超时时间: %v)", recommendCalcTimeout))
+		return res, common.NewServiceError(fmt.Sprintf("推荐计算超时(超时时间: %v)", recommendCalcTimeout))
 	}
 
 	// 处理其他错误
@@ -125,52 +152,46 @@ func (s recommendService) RecommendByCBF(id string, lang string) (gameListVo []m
 		log.Error("推荐请求执行失败: id=%s, err=%v", id, waitErr)
 		select {
 		case e := <-errChan:
-			return nil, e
+			return res, e
 		default:
-			return nil, common.NewServiceError("推荐计算失败: " + waitErr.Error())
+			return res, common.NewServiceError("推荐计算失败: " + waitErr.Error())
 		}
 	}
 
 	// 读取结果时增加超时兜底
 	select {
-	case res := <-resultChan:
+	case res = <-resultChan:
 		return res, nil
 	case e := <-errChan:
-		return nil, e
+		return res, e
 	case <-rootCtx.Done():
-		return nil, common.NewServiceError("推荐计算超时")
+		return res, common.NewServiceError("推荐计算超时")
 	}
 }
 
 // CBF 获取一组推荐的游戏记录
-func getGameCBF(id int64, lang string) (recommendContent []models.GameRecommendVo, err common.GFError) {
+func getGameCBF(id int64, lang string, opts rerankOptions) (recommendContent []models.GameRecommendVo, err common.GFError) {
 	// 执行 CBF
 	similarities, err := processContentBasedFilter(id)
 	if err != nil {
 		return nil, err
 	}
+	return buildRecommendResult(id, similarities, lang, opts)
+}
 
-	// 从相似度结果生成推荐视图 前12随机选8
-	const topN = 8
-	const candidateN = 12
-
-	filtered := make([]models.ContentSimilarities, 0, candidateN)
+// 从按相似度降序的结果生成推荐视图, 经过多样性重排后保持重排的顺序
+func buildRecommendResult(id int64, similarities []models.ContentSimilarities, lang string, opts rerankOptions) (recommendContent []models.GameRecommendVo, err common.GFError) {
+	filtered := make([]models.ContentSimilarities, 0, len(similarities))
 	for _, sim := range similarities {
 		if sim.ID == id || sim.Similarity <= 0 {
 			continue
 		}
 		filtered = append(filtered, sim)
-		if len(filtered) >= candidateN {
-			break
-		}
 	}
 
-	// 打乱候选列表 增加多样性
-	//rand.Shuffle(len(filtered), func(i, j int) {
-	//	filtered[i], filtered[j] = filtered[j], filtered[i]
-	//})
-	if len(filtered) > topN {
-		filtered = filtered[:topN]
+	// 多样性重排
+	if filtered, err = rerank(filtered, opts); err != nil {
+		return nil, err
 	}
 
 	// 转换为 GameRecommendVo
@@ -189,7 +210,16 @@ func getGameCBF(id int64, lang string) (recommendContent []models.GameRecommendV
 		return nil, common.NewServiceError(err.GetMsg())
 	}
 
+	games := make(map[int64]models.GameTemp, len(gameList))
 	for _, game := range gameList {
+		games[game.ID] = game
+	}
+
+	for _, gameID := range gameIDs {
+		game, ok := games[gameID]
+		if !ok {
+			continue
+		}
 		vo := models.GameRecommendVo{
 			ID:         util.Int642String(game.ID),
 			Similarity: idToSimilarity[game.ID],
@@ -207,46 +237,76 @@ func getGameCBF(id int64, lang string) (recommendContent []models.GameRecommendV
 		recommendContent = append(recommendContent, vo)
 	}
 
-	// 排序
-	sort.Slice(recommendContent, func(i, j int) bool {
-		return recommendContent[i].Similarity > recommendContent[j].Similarity
-	})
-
 	return recommendContent, nil
 }
 
 // CBF 算法
 func processContentBasedFilter(gameID int64) ([]models.ContentSimilarities, common.GFError) {
-	// 获取标签映射和标签 ID 列表
-	tagMappingMap, tagIDs, err := getTagToMap()
+	// 提取所有游戏的特征
+	contentFeatures, _, err := buildContentFeatures()
 	if err != nil {
 		return nil, err
 	}
 
-	// 初始化标签 ID 到维度索引的映射
-	tagIDToIndex := buildTagIndexMap(tagIDs)
-
-	// 特征提取 - 独热编码
-	targetContent, contentFeatures := execFeature(tagMappingMap, tagIDToIndex, gameID)
-
 	// 校验目标游戏是否存在有效特征
-	if len(targetContent.Tag) == 0 {
+	targetContent, exists := contentFeatures[gameID]
+	if !exists {
 		// 游戏 ID 不在映射中
-		if _, exists := tagMappingMap[gameID]; !exists {
-			return nil, common.NewServiceError("目标游戏不存在或未关联标签")
-		}
+		return nil, common.NewServiceError("目标游戏不存在或未关联标签")
+	}
+	if len(targetContent.Tag) == 0 {
 		// 游戏存在但无标签
 		log.Warn("游戏ID=", gameID, "未关联任何标签，无法生成推荐")
 		return []models.ContentSimilarities{}, nil
 	}
 
+	others := make([]models.ContentSimilarities, 0, len(contentFeatures))
+	for id, feature := range contentFeatures {
+		if id != gameID {
+			others = append(others, feature)
+		}
+	}
+
 	// 计算相似度
-	similarities := execSimilarity(targetContent, contentFeatures)
+	similarities := execSimilarity(targetContent, others)
 	return similarities, nil
 }
 
-// 构建标签 ID到维度索引的映射 map[tagID]index
-func buildTagIndexMap(tagIDs []int64) map[int64]int {
+// 提取所有游戏的特征 map[gameID]特征, 同时返回维度索引对应的标签 ID
+func buildContentFeatures() (map[int64]models.ContentSimilarities, []int64, common.GFError) {
+	// 获取标签映射和标签权重
+	tagMappingMap, tagWeights, err := getTagToMap()
+	if err != nil {
+		return nil, nil, err
+	}
+	features, indexToTagID, _ := contentFeatures(tagMappingMap, tagWeights)
+	return features, indexToTagID, nil
+}
+
+// contentFeatures 由标签映射与标签权重生成特征, 同时返回维度对应的标签 ID 与维度权重
+func contentFeatures(tagMapping map[int64][]models.TagMappingModel, tagWeights map[int64]float64) (map[int64]models.ContentSimilarities, []int64, []float64) {
+	// 初始化标签 ID 到维度索引的映射
+	tagIDToIndex := buildTagIndexMap(tagWeights)
+	indexToTagID := make([]int64, len(tagIDToIndex))
+	for tagID, idx := range tagIDToIndex {
+		indexToTagID[idx] = tagID
+	}
+
+	// 维度权重 = 标签权重 * IDF, 出现在大多数游戏中的标签自动降权
+	dimWeights := buildDimWeights(tagMapping, tagWeights, tagIDToIndex)
+
+	// 特征提取 - 加权的稀疏向量
+	return execFeature(tagMapping, tagIDToIndex, dimWeights), indexToTagID, dimWeights
+}
+
+// 构建标签 ID到维度索引的映射 map[tagID]index, 按标签 ID 排序保证维度稳定
+func buildTagIndexMap(tagWeights map[int64]float64) map[int64]int {
+	tagIDs := make([]int64, 0, len(tagWeights))
+	for tagID := range tagWeights {
+		tagIDs = append(tagIDs, tagID)
+	}
+	sort.Slice(tagIDs, func(i, j int) bool { return tagIDs[i] < tagIDs[j] })
+
 	tagIDToIndex := make(map[int64]int, len(tagIDs))
 	for idx, tagID := range tagIDs {
 		tagIDToIndex[tagID] = idx
@@ -254,18 +314,51 @@ func buildTagIndexMap(tagIDs []int64) map[int64]int {
 	return tagIDToIndex
 }
 
-// 特征提取 - 独热编码
-func execFeature(tagMapping map[int64][]int64, tagIDToIndex map[int64]int, targetGameID int64) (models.ContentSimilarities, []models.ContentSimilarities) {
-	var targetContent models.ContentSimilarities
-	var contentFeatures []models.ContentSimilarities
+// 计算每个维度的权重, IDF 使用平滑公式 ln((1+N)/(1+df)) + 1
+func buildDimWeights(tagMapping map[int64][]models.TagMappingModel, tagWeights map[int64]float64, tagIDToIndex map[int64]int) []float64 {
+	df := make([]int, len(tagIDToIndex))
+	games := 0
+	for _, tags := range tagMapping {
+		seen := make(map[int]struct{}, len(tags))
+		for _, tag := range tags {
+			idx, ok := tagIDToIndex[tag.TagID]
+			if !ok {
+				continue
+			}
+			if _, exists := seen[idx]; exists {
+				continue
+			}
+			seen[idx] = struct{}{}
+			df[idx]++
+		}
+		if len(seen) > 0 {
+			games++
+		}
+	}
 
-	for gameID, tagIDs := range tagMapping {
-		// 构建独热特征
-		feature := make([]float64, 0, len(tagIDs))
+	dimWeights := make([]float64, len(tagIDToIndex))
+	for tagID, idx := range tagIDToIndex {
+		idf := math.Log(float64(1+games)/float64(1+df[idx])) + 1
+		dimWeights[idx] = normalizeWeight(tagWeights[tagID]) * idf
+	}
+	return dimWeights
+}
+
+// 特征提取 - 每个维度的值为 维度权重 * 映射权重
+func execFeature(tagMapping map[int64][]models.TagMappingModel, tagIDToIndex map[int64]int, dimWeights []float64) map[int64]models.ContentSimilarities {
+	contentFeatures := make(map[int64]models.ContentSimilarities, len(tagMapping))
+
+	for gameID, tags := range tagMapping {
+		// 构建加权特征
+		feature := models.ContentSimilarities{
+			ID:     gameID,
+			Tag:    make([]float64, 0, len(tags)),
+			Weight: make([]float64, 0, len(tags)),
+		}
 		seen := make(map[int]struct{}) // 去重标签
 
-		for _, tagID := range tagIDs {
-			idx, ok := tagIDToIndex[tagID]
+		for _, tag := range tags {
+			idx, ok := tagIDToIndex[tag.TagID]
 			if !ok {
 				continue // 忽略未注册的标签
 			}
@@ -273,24 +366,14 @@ func execFeature(tagMapping map[int64][]int64, tagIDToIndex map[int64]int, targe
 				continue // 跳过重复标签
 			}
 			seen[idx] = struct{}{}
-			feature = append(feature, float64(idx)) // 存储维度索引
+			feature.Tag = append(feature.Tag, float64(idx)) // 存储维度索引
+			feature.Weight = append(feature.Weight, dimWeights[idx]*normalizeWeight(tag.Weight))
 		}
 
-		// 区分目标游戏和其他游戏
-		if gameID == targetGameID {
-			targetContent = models.ContentSimilarities{
-				ID:  gameID,
-				Tag: feature,
-			}
-		} else {
-			contentFeatures = append(contentFeatures, models.ContentSimilarities{
-				ID:  gameID,
-				Tag: feature,
-			})
-		}
+		contentFeatures[gameID] = feature
 	}
 
-	return targetContent, contentFeatures
+	return contentFeatures
 }
 
 // 计算相似度
@@ -298,9 +381,13 @@ func execSimilarity(target models.ContentSimilarities, others []models.ContentSi
 	similarities := make([]models.ContentSimilarities, 0, len(others))
 
 	// 将目标特征转换为字典
-	targetSet := make(map[float64]struct{}, len(target.Tag))
-	for _, idx := range target.Tag {
-		targetSet[idx] = struct{}{}
+	targetSet := make(map[float64]float64, len(target.Tag))
+	for i, idx := range target.Tag {
+		targetSet[idx] = target.Weight[i]
+	}
+	magTarget := magnitude(target.Weight)
+	if magTarget == 0 {
+		return similarities
 	}
 
 	// 计算每个游戏与目标的相似度
@@ -310,18 +397,19 @@ func execSimilarity(target models.ContentSimilarities, others []models.ContentSi
 			continue
 		}
 
-		// 计算共同标签数量
-		commonCount := 0
-		for _, idx := range other.Tag {
-			if _, exists := targetSet[idx]; exists {
-				commonCount++
+		// 计算共同标签的加权点积
+		dot := 0.0
+		for i, idx := range other.Tag {
+			if weight, exists := targetSet[idx]; exists {
+				dot += weight * other.Weight[i]
 			}
 		}
+		if dot == 0 {
+			continue
+		}
 
-		// 计算余弦相似度 commonCount / (sqrt(len(target)) * sqrt(len(other)))
-		magTarget := math.Sqrt(float64(len(target.Tag)))
-		magOther := math.Sqrt(float64(len(other.Tag)))
-		sim := float64(commonCount) / (magTarget * magOther)
+		// 计算加权余弦相似度 dot / (|target| * |other|)
+		sim := dot / (magTarget * magnitude(other.Weight))
 
 		if sim > 0 {
 			similarities = append(similarities, models.ContentSimilarities{
@@ -339,13 +427,30 @@ func execSimilarity(target models.ContentSimilarities, others []models.ContentSi
 	return similarities
 }
 
-// 获取标签映射
-func getTagToMap() (tagMapping map[int64][]int64, tagIDs []int64, err common.GFError) {
+// 向量的模
+func magnitude(weights []float64) float64 {
+	sum := 0.0
+	for _, w := range weights {
+		sum += w * w
+	}
+	return math.Sqrt(sum)
+}
+
+// 权重未设置(非正数)时按 1 计算
+func normalizeWeight(weight float64) float64 {
+	if weight <= 0 {
+		return 1
+	}
+	return weight
+}
+
+// 获取标签映射 map[gameID][]标签及映射权重 与 标签权重 map[tagID]weight
+func getTagToMap() (tagMapping map[int64][]models.TagMappingModel, tagWeights map[int64]float64, err common.GFError) {
 	// Redis 读缓存
-	tagMapping, tagIDs, err = loadFromRedis()
-	if err == nil && tagMapping != nil && len(tagIDs) > 0 {
+	tagMapping, tagWeights, err = loadFromRedis()
+	if err == nil && tagMapping != nil && len(tagWeights) > 0 {
 		// 缓存命中，直接返回
-		return tagMapping, tagIDs, nil
+		return tagMapping, tagWeights, nil
 	}
 
 	// 缓存未命中
@@ -353,7 +458,7 @@ func getTagToMap() (tagMapping map[int64][]int64, tagIDs []int64, err common.GFE
 	if err != nil {
 		return nil, nil, common.NewServiceError("获取标签映射记录失败: " + err.GetMsg())
 	}
-	tagMapping = make(map[int64][]int64)
+	tagMapping = make(map[int64][]models.TagMappingModel)
 	for _, rec := range mappingRecords {
 		gameID := rec.GameID
 		tagID := rec.TagID
@@ -361,13 +466,13 @@ func getTagToMap() (tagMapping map[int64][]int64, tagIDs []int64, err common.GFE
 		tags := tagMapping[gameID]
 		exists := false
 		for _, t := range tags {
-			if t == tagID {
+			if t.TagID == tagID {
 				exists = true
 				break
 			}
 		}
 		if !exists {
-			tagMapping[gameID] = append(tags, tagID)
+			tagMapping[gameID] = append(tags, models.TagMappingModel{TagID: tagID, Weight: rec.Weight})
 		}
 	}
 
@@ -375,48 +480,48 @@ func getTagToMap() (tagMapping map[int64][]int64, tagIDs []int64, err common.GFE
 	if err != nil {
 		return nil, nil, common.NewServiceError("获取标签记录失败: " + err.GetMsg())
 	}
-	tagIDs = make([]int64, 0, len(tagRecords))
+	tagWeights = make(map[int64]float64, len(tagRecords))
 	for idx := range tagRecords {
-		tagIDs = append(tagIDs, tagRecords[idx].ID)
+		tagWeights[tagRecords[idx].ID] = tagRecords[idx].Weight
 	}
 
 	// 异步写入Redis缓存
-	go saveToRedis(tagMapping, tagIDs)
+	go saveToRedis(tagMapping, tagWeights)
 
-	return tagMapping, tagIDs, nil
+	return tagMapping, tagWeights, nil
 }
 
 // 从Redis加载缓存
-func loadFromRedis() (tagMapping map[int64][]int64, tagIDs []int64, err common.GFError) {
+func loadFromRedis() (tagMapping map[int64][]models.TagMappingModel, tagWeights map[int64]float64, err common.GFError) {
 	// 读取tagMapping
 	mappingStr, err := cs.GetString(redisTagMappingKey)
 	if err != nil || mappingStr == "" {
 		// 缓存不存在或读取失败
 		return nil, nil, nil
 	}
-	// 反序列化map[int64][]int64
-	tagMapping = make(map[int64][]int64)
+	// 反序列化map[int64][]TagMappingModel
+	tagMapping = make(map[int64][]models.TagMappingModel)
 	if err := sonic.Unmarshal([]byte(mappingStr), &tagMapping); err != nil {
 		log.Error("tagMapping反序列化失败: " + err.Error())
 		return nil, nil, common.NewServiceError("缓存数据格式错误")
 	}
 
-	// 读取tagIDs
-	idsStr, err := cs.GetString(redisTagIDsKey)
-	if err != nil || idsStr == "" {
+	// 读取tagWeights
+	weightsStr, err := cs.GetString(redisTagWeightsKey)
+	if err != nil || weightsStr == "" {
 		return nil, nil, nil
 	}
-	// 反序列化[]int64
-	if err := sonic.Unmarshal([]byte(idsStr), &tagIDs); err != nil {
-		log.Error("tagIDs反序列化失败: " + err.Error())
+	// 反序列化map[int64]float64
+	if err := sonic.Unmarshal([]byte(weightsStr), &tagWeights); err != nil {
+		log.Error("tagWeights反序列化失败: " + err.Error())
 		return nil, nil, common.NewServiceError("缓存数据格式错误")
 	}
 
-	return tagMapping, tagIDs, nil
+	return tagMapping, tagWeights, nil
 }
 
 // 保存数据到Redis
-func saveToRedis(tagMapping map[int64][]int64, tagIDs []int64) {
+func saveToRedis(tagMapping map[int64][]models.TagMappingModel, tagWeights map[int64]float64) {
 	// 序列化tagMapping并保存
 	mappingBytes, err := sonic.Marshal(tagMapping)
 	if err != nil {
@@ -427,13 +532,13 @@ func saveToRedis(tagMapping map[int64][]int64, tagIDs []int64) {
 		log.Error("tagMapping缓存写入失败: " + err.GetMsg())
 	}
 
-	// 序列化tagIDs并保存
-	idsBytes, err := sonic.Marshal(tagIDs)
+	// 序列化tagWeights并保存
+	weightsBytes, err := sonic.Marshal(tagWeights)
 	if err != nil {
-		log.Error("tagIDs序列化失败: " + err.Error())
+		log.Error("tagWeights序列化失败: " + err.Error())
 		return
 	}
-	if err := cs.SetExpire(redisTagIDsKey, idsBytes, cacheExpireTime); err != nil {
-		log.Error("tagIDs缓存写入失败: " + err.GetMsg())
+	if err := cs.SetExpire(redisTagWeightsKey, weightsBytes, cacheExpireTime); err != nil {
+		log.Error("tagWeights缓存写入失败: " + err.GetMsg())
 	}
 }
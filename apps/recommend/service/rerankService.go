@@ -0,0 +1,167 @@
+package service
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/recommend/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+)
+
+const (
+	rerankDefaultLimit     = 8
+	rerankMaxLimit         = 30
+	rerankDefaultDiversity = 0.3
+	// 候选集为返回数量的倍数, 不足 rerankMinCandidates 时按其取
+	rerankCandidateFactor = 3
+	rerankMinCandidates   = 12
+	// 开启探索时每个位置随机选取候选的概率
+	exploreEpsilon = 0.2
+)
+
+// rerankOptions 解析后的重排参数
+type rerankOptions struct {
+	limit     int
+	diversity float64
+	explore   bool
+	seed      int64
+}
+
+// parseRecommendQuery 解析重排参数, 未传时使用默认值
+func parseRecommendQuery(id int64, query models.RecommendQuery) (res rerankOptions, err common.GFError) {
+	res.limit, res.diversity = rerankDefaultLimit, rerankDefaultDiversity
+	if query.Limit != "" {
+		limit, parseErr := util.String2Int(query.Limit)
+		if parseErr != nil || limit <= 0 {
+			return res, common.NewServiceError("limit 参数有误")
+		}
+		res.limit = min(limit, rerankMaxLimit)
+	}
+	if query.Diversity != "" {
+		diversity, parseErr := util.String2Float64(query.Diversity)
+		if parseErr != nil || diversity < 0 || diversity > 1 {
+			return res, common.NewServiceError("diversity 必须在 0-1 之间")
+		}
+		res.diversity = diversity
+	}
+	if query.Seed != "" {
+		// 同一 seed 对不同游戏的随机序列不同
+		h := fnv.New64a()
+		h.Write([]byte(query.Seed + ":" + util.Int642String(id)))
+		res.explore, res.seed = true, int64(h.Sum64())
+	}
+	return res, nil
+}
+
+// rerank 从按相关度降序的候选中选出 limit 个结果
+// 使用最大边际相关(MMR): 每次选取 (1-λ)*相关度 - λ*与已选结果的最大相似度 最高的候选, 相似度为标签向量的余弦相似度
+// 标签向量由 UpdateCBFIndex 预先写入, 缺少时现场计算
+// 开启探索时每个位置以 exploreEpsilon 的概率改为随机选取, 随机数由 seed 决定
+func rerank(candidates []models.ContentSimilarities, opts rerankOptions) ([]models.ContentSimilarities, common.GFError) {
+	poolSize := max(opts.limit*rerankCandidateFactor, rerankMinCandidates)
+	if len(candidates) > poolSize {
+		candidates = candidates[:poolSize]
+	}
+	if !opts.explore && opts.diversity == 0 {
+		return candidates[:min(opts.limit, len(candidates))], nil
+	}
+
+	// 多样性为 0 时不需要标签向量, 只读取候选集的向量
+	var features map[int64]models.CBFFeatureModel
+	if opts.diversity > 0 {
+		ids := make([]int64, len(candidates))
+		for i, v := range candidates {
+			ids[i] = v.ID
+		}
+		var err common.GFError
+		if features, err = rerankFeatures(ids); err != nil {
+			return nil, err
+		}
+	}
+	var rng *rand.Rand
+	if opts.explore {
+		rng = rand.New(rand.NewSource(opts.seed))
+	}
+
+	remaining := append([]models.ContentSimilarities(nil), candidates...)
+	// 每个候选与已选结果的最大相似度
+	maxSim := make([]float64, len(remaining))
+	res := make([]models.ContentSimilarities, 0, opts.limit)
+	for len(res) < opts.limit && len(remaining) > 0 {
+		pick := 0
+		if rng != nil && rng.Float64() < exploreEpsilon {
+			pick = rng.Intn(len(remaining))
+		} else {
+			best := math.Inf(-1)
+			for i, v := range remaining {
+				score := (1-opts.diversity)*v.Similarity - opts.diversity*maxSim[i]
+				if score > best {
+					best, pick = score, i
+				}
+			}
+		}
+
+		chosen := remaining[pick]
+		res = append(res, chosen)
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+		maxSim = append(maxSim[:pick], maxSim[pick+1:]...)
+		if features == nil {
+			continue
+		}
+		for i, v := range remaining {
+			maxSim[i] = max(maxSim[i], featureCosine(features[v.ID], features[chosen.ID]))
+		}
+	}
+	return res, nil
+}
+
+// rerankFeatures 读取候选的标签向量, 索引尚未写入向量时现场计算
+// 现场计算仍缺少向量的候选没有标签, 视为与其他结果不相似
+func rerankFeatures(ids []int64) (map[int64]models.CBFFeatureModel, common.GFError) {
+	res, err := loadCBFFeatures(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == len(ids) {
+		return res, nil
+	}
+	features, indexToTagID, err := buildContentFeatures()
+	if err != nil {
+		log.Warn("rerank 缺少标签向量, 现场计算失败: ", err.GetMsg())
+		return res, nil
+	}
+	missing := 0
+	for _, id := range ids {
+		if _, ok := res[id]; ok {
+			continue
+		}
+		missing++
+		if feature, ok := features[id]; ok {
+			res[id] = toCBFFeature(feature, indexToTagID)
+		}
+	}
+	log.Infof("rerank 缺少 %d 个标签向量, 已现场计算", missing)
+	return res, nil
+}
+
+// featureCosine 两个游戏标签向量的余弦相似度
+func featureCosine(a models.CBFFeatureModel, b models.CBFFeatureModel) float64 {
+	if len(a.Tag) == 0 || len(b.Tag) == 0 {
+		return 0
+	}
+	weights := make(map[int64]float64, len(a.Tag))
+	for i, idx := range a.Tag {
+		weights[idx] = a.Weight[i]
+	}
+	dot := 0.0
+	for i, idx := range b.Tag {
+		dot += weights[idx] * b.Weight[i]
+	}
+	if dot == 0 {
+		return 0
+	}
+	return dot / (magnitude(a.Weight) * magnitude(b.Weight))
+}
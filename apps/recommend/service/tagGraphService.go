@@ -0,0 +1,331 @@
+package service
+
+import (
+	"encoding/xml"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/recommend/dao"
+	"github.com/GoFurry/gofurry-game-backend/apps/recommend/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	cm "github.com/GoFurry/gofurry-game-backend/common/models"
+	cs "github.com/GoFurry/gofurry-game-backend/common/service"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+	"github.com/bytedance/sonic"
+)
+
+type tagGraphService struct{}
+
+var tagGraphSingleton = new(tagGraphService)
+
+func GetTagGraphService() *tagGraphService { return tagGraphSingleton }
+
+// Redis 定义
+const (
+	redisTagCooccurrenceKey = "recommend:tag-cooccurrence"
+	tagCooccurrenceTimeout  = 3 * time.Hour // 由每小时任务刷新
+)
+
+// 缓存未命中时只统计一次, 也避免与定时任务并发写入
+var cooccurrenceMutex sync.Mutex
+
+const (
+	relatedTagDefaultLimit = 10
+	relatedTagMaxLimit     = 50
+	// 共现次数低于该值时 NPMI 不可靠, 不参与按 NPMI 排序
+	npmiMinCount = 2
+)
+
+// 相关标签排序方式
+const (
+	MetricJaccard = "jaccard"
+	MetricNPMI    = "npmi"
+)
+
+// 获取与标签共现最多的标签, metric 为 jaccard 或 npmi
+func (s tagGraphService) GetRelatedTags(id string, lang string, limit string, metric string) (res []models.TagRelatedVo, err common.GFError) {
+	tagID, parseErr := util.String2Int64(id)
+	if parseErr != nil {
+		return nil, common.NewServiceError("Tag ID 转换有误")
+	}
+	num := relatedTagDefaultLimit
+	if limit != "" {
+		if num, parseErr = util.String2Int(limit); parseErr != nil || num <= 0 {
+			return nil, common.NewServiceError("limit 参数有误")
+		}
+		num = min(num, relatedTagMaxLimit)
+	}
+	if metric == "" {
+		metric = MetricJaccard
+	}
+	if metric != MetricJaccard && metric != MetricNPMI {
+		return nil, common.NewServiceError("metric 仅支持 jaccard/npmi")
+	}
+
+	cooccurrence, err := s.getCooccurrence()
+	if err != nil {
+		return nil, err
+	}
+	names, err := getTagNames(lang)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := names[tagID]; !ok {
+		return nil, common.NewServiceError("标签不存在")
+	}
+
+	res = []models.TagRelatedVo{}
+	for _, v := range cooccurrence.Edges {
+		other := v.Target
+		switch {
+		case v.Source == tagID:
+		case v.Target == tagID:
+			other = v.Source
+		default:
+			continue
+		}
+		if metric == MetricNPMI && v.Count < npmiMinCount {
+			continue
+		}
+		res = append(res, models.TagRelatedVo{
+			ID:        util.Int642String(other),
+			Name:      names[other],
+			GameCount: cooccurrence.Counts[other],
+			CoCount:   v.Count,
+			Jaccard:   v.Jaccard,
+			NPMI:      v.NPMI,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if metric == MetricNPMI && res[i].NPMI != res[j].NPMI {
+			return res[i].NPMI > res[j].NPMI
+		}
+		if res[i].Jaccard != res[j].Jaccard {
+			return res[i].Jaccard > res[j].Jaccard
+		}
+		return res[i].CoCount > res[j].CoCount
+	})
+	if len(res) > num {
+		res = res[:num]
+	}
+	return res, nil
+}
+
+// 获取标签共现图, 节点为关联了游戏的标签, 边权重为 Jaccard
+func (s tagGraphService) GetTagGraph(lang string) (res models.TagGraphVo, err common.GFError) {
+	cooccurrence, err := s.getCooccurrence()
+	if err != nil {
+		return res, err
+	}
+	names, err := getTagNames(lang)
+	if err != nil {
+		return res, err
+	}
+
+	res.Nodes = make([]models.TagGraphNodeVo, 0, len(cooccurrence.Counts))
+	for id, count := range cooccurrence.Counts {
+		if name, ok := names[id]; ok {
+			res.Nodes = append(res.Nodes, models.TagGraphNodeVo{ID: util.Int642String(id), Name: name, GameCount: count})
+		}
+	}
+	sort.Slice(res.Nodes, func(i, j int) bool {
+		if res.Nodes[i].GameCount != res.Nodes[j].GameCount {
+			return res.Nodes[i].GameCount > res.Nodes[j].GameCount
+		}
+		return res.Nodes[i].ID < res.Nodes[j].ID
+	})
+
+	res.Edges = make([]models.TagGraphEdgeVo, 0, len(cooccurrence.Edges))
+	for _, v := range cooccurrence.Edges {
+		_, sourceOK := names[v.Source]
+		_, targetOK := names[v.Target]
+		if !sourceOK || !targetOK {
+			continue
+		}
+		res.Edges = append(res.Edges, models.TagGraphEdgeVo{
+			Source: util.Int642String(v.Source),
+			Target: util.Int642String(v.Target),
+			Weight: v.Jaccard,
+			Count:  v.Count,
+			NPMI:   v.NPMI,
+		})
+	}
+	res.UpdateTime = cooccurrence.UpdateTime
+	return res, nil
+}
+
+// 获取 GraphML 格式的标签共现图
+func (s tagGraphService) GetTagGraphML(lang string) (string, common.GFError) {
+	graph, err := s.GetTagGraph(lang)
+	if err != nil {
+		return "", err
+	}
+	doc := models.GraphML{
+		Keys: []models.GraphMLKey{
+			{ID: "name", For: "node", AttrName: "name", AttrType: "string"},
+			{ID: "game_count", For: "node", AttrName: "game_count", AttrType: "int"},
+			{ID: "weight", For: "edge", AttrName: "weight", AttrType: "double"},
+			{ID: "count", For: "edge", AttrName: "count", AttrType: "int"},
+			{ID: "npmi", For: "edge", AttrName: "npmi", AttrType: "double"},
+		},
+		Graph: models.GraphMLGraph{ID: "tag-cooccurrence", EdgeDefault: "undirected"},
+	}
+	for _, v := range graph.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, models.GraphMLNode{ID: v.ID, Data: []models.GraphMLData{
+			{Key: "name", Value: v.Name},
+			{Key: "game_count", Value: strconv.Itoa(v.GameCount)},
+		}})
+	}
+	for _, v := range graph.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, models.GraphMLEdge{Source: v.Source, Target: v.Target, Data: []models.GraphMLData{
+			{Key: "weight", Value: strconv.FormatFloat(v.Weight, 'f', -1, 64)},
+			{Key: "count", Value: strconv.Itoa(v.Count)},
+			{Key: "npmi", Value: strconv.FormatFloat(v.NPMI, 'f', -1, 64)},
+		}})
+	}
+	body, marshalErr := xml.MarshalIndent(doc, "", "  ")
+	if marshalErr != nil {
+		log.Error("GetTagGraphML marshal err: ", marshalErr)
+		return "", common.NewServiceError("生成 GraphML 失败")
+	}
+	return xml.Header + string(body), nil
+}
+
+// UpdateTagCooccurrenceCache 重新统计标签共现并写入缓存
+func (s tagGraphService) UpdateTagCooccurrenceCache() common.GFError {
+	cooccurrenceMutex.Lock()
+	defer cooccurrenceMutex.Unlock()
+	cooccurrence, err := buildCooccurrence()
+	if err != nil {
+		return err
+	}
+	saveCooccurrence(cooccurrence)
+	return nil
+}
+
+// getCooccurrence 优先读取缓存, 未命中时现场统计并写入缓存
+func (s tagGraphService) getCooccurrence() (res models.TagCooccurrenceModel, err common.GFError) {
+	if res, ok := loadCooccurrence(); ok {
+		return res, nil
+	}
+
+	cooccurrenceMutex.Lock()
+	defer cooccurrenceMutex.Unlock()
+	if res, ok := loadCooccurrence(); ok {
+		return res, nil
+	}
+	if res, err = buildCooccurrence(); err != nil {
+		return
+	}
+	saveCooccurrence(res)
+	return res, nil
+}
+
+// loadCooccurrence 读取缓存的共现矩阵, 未命中时返回 false
+func loadCooccurrence() (res models.TagCooccurrenceModel, ok bool) {
+	jsonStr, err := cs.GetString(redisTagCooccurrenceKey)
+	if err != nil || jsonStr == "" {
+		return res, false
+	}
+	return res, sonic.Unmarshal([]byte(jsonStr), &res) == nil
+}
+
+// buildCooccurrence 基于推荐使用的游戏标签映射统计共现矩阵
+func buildCooccurrence() (res models.TagCooccurrenceModel, err common.GFError) {
+	tagMapping, tagWeights, err := getTagToMap()
+	if err != nil {
+		return res, err
+	}
+
+	type tagPair struct{ source, target int64 }
+	res.Counts = map[int64]int{}
+	pairs := map[tagPair]int{}
+	for _, tags := range tagMapping {
+		seen := make(map[int64]struct{}, len(tags))
+		valid := make([]int64, 0, len(tags))
+		for _, tag := range tags {
+			id := tag.TagID
+			if _, ok := tagWeights[id]; !ok {
+				continue
+			}
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			valid = append(valid, id)
+		}
+		if len(valid) == 0 {
+			continue
+		}
+		res.Games++
+		sort.Slice(valid, func(i, j int) bool { return valid[i] < valid[j] })
+		for i, a := range valid {
+			res.Counts[a]++
+			for _, b := range valid[i+1:] {
+				pairs[tagPair{a, b}]++
+			}
+		}
+	}
+
+	total := float64(res.Games)
+	res.Edges = make([]models.TagEdgeModel, 0, len(pairs))
+	for pair, count := range pairs {
+		countA, countB, both := float64(res.Counts[pair.source]), float64(res.Counts[pair.target]), float64(count)
+		edge := models.TagEdgeModel{
+			Source:  pair.source,
+			Target:  pair.target,
+			Count:   count,
+			Jaccard: round4(both / (countA + countB - both)),
+		}
+		// 两个标签总是同时出现时 -log(p(a,b)) 为 0, NPMI 取 1
+		if pJoint := both / total; pJoint < 1 {
+			edge.NPMI = round4(math.Log(both*total/(countA*countB)) / -math.Log(pJoint))
+		} else {
+			edge.NPMI = 1
+		}
+		res.Edges = append(res.Edges, edge)
+	}
+	sort.Slice(res.Edges, func(i, j int) bool {
+		if res.Edges[i].Source != res.Edges[j].Source {
+			return res.Edges[i].Source < res.Edges[j].Source
+		}
+		return res.Edges[i].Target < res.Edges[j].Target
+	})
+	res.UpdateTime = cm.LocalTime(time.Now())
+	return res, nil
+}
+
+func saveCooccurrence(cooccurrence models.TagCooccurrenceModel) {
+	jsonRecord, jsonErr := sonic.Marshal(cooccurrence)
+	if jsonErr != nil {
+		log.Error("tagCooccurrence 序列化失败: " + jsonErr.Error())
+		return
+	}
+	if err := cs.SetExpire(redisTagCooccurrenceKey, string(jsonRecord), tagCooccurrenceTimeout); err != nil {
+		log.Error("tagCooccurrence 缓存写入失败: " + err.GetMsg())
+	}
+}
+
+// getTagNames 未删除标签的名称
+func getTagNames(lang string) (map[int64]string, common.GFError) {
+	tags, err := dao.GetRecommendDao().GetTagList()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(tags))
+	for _, v := range tags {
+		names[v.ID] = v.Name
+		if lang == "en" {
+			names[v.ID] = v.NameEn
+		}
+	}
+	return names, nil
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
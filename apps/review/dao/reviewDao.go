@@ -37,6 +37,7 @@ func (dao reviewDao) GetHotGame(num int) (res []models.AvgScoreResult, err commo
 			gameTable+".info_en",
 			gameTable+".header",
 		).
+		Where(gameTable + ".deleted IS NOT TRUE").
 		Group(commentTable + ".game_id, " + gameTable + ".name, " + gameTable + ".name_en, " +
 			gameTable + ".info, " + gameTable + ".info_en, " + gameTable + ".header").
 		Order("avg_score DESC").
@@ -109,6 +110,7 @@ func (dao reviewDao) GetListByLimit(num int, lang string) (res []models.Anonymou
 	db := dao.Gm.Table(models.TableNameGfgGameComment).
 		Select(selectFields).
 		Joins("LEFT JOIN gfg_game ON gfg_game_comment.game_id = gfg_game.id").
+		Where("gfg_game.deleted IS NOT TRUE").
 		Order("gfg_game_comment.create_time DESC").
 		Limit(num).
 		Find(&res)
@@ -2,6 +2,7 @@ package schedule
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/GoFurry/gofurry-game-backend/apps/schedule/task"
@@ -9,6 +10,12 @@ import (
 	cs "github.com/GoFurry/gofurry-game-backend/common/service"
 )
 
+var (
+	jobWg    sync.WaitGroup // 执行中的任务
+	jobMu    sync.Mutex
+	stopping bool // 关闭后不再启动新任务
+)
+
 // 初始化
 func InitScheduleOnStart() {
 	defer func() {
@@ -19,27 +26,87 @@ func InitScheduleOnStart() {
 	log.Info("Schedule 模块初始化开始...")
 
 	//初始化后执行一次 Schedule
-	go ScheduleByTenMinutes()
-	go ScheduleByOneHour()
+	go tracked(ScheduleByTenMinutes)()
+	go tracked(ScheduleByOneHour)()
 	// 定时任务执行 Schedule
-	cs.AddCronJob(10*time.Minute, ScheduleByTenMinutes)
-	cs.AddCronJob(1*time.Hour, ScheduleByOneHour)
+	cs.AddCronJob(10*time.Minute, tracked(ScheduleByTenMinutes))
+	cs.AddCronJob(1*time.Hour, tracked(ScheduleByOneHour))
 
 	log.Info("Schedule 模块初始化结束...")
 }
 
+// Wait 停止启动新任务并等待执行中的任务结束, 超时返回 false
+func Wait(timeout time.Duration) bool {
+	jobMu.Lock()
+	stopping = true
+	jobMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		jobWg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Go 在后台执行任务, 与定时任务一样在关闭时等待其结束, 关闭后不再执行
+// 管理后台修改数据后触发的缓存重建应通过此处启动
+func Go(job func()) {
+	go tracked(job)()
+}
+
+// tracked 包装任务, 记录执行状态以便关闭时等待
+func tracked(job func()) func() {
+	return func() {
+		jobMu.Lock()
+		if stopping {
+			jobMu.Unlock()
+			return
+		}
+		jobWg.Add(1)
+		jobMu.Unlock()
+		defer jobWg.Done()
+		job()
+	}
+}
+
 // 任务表
 func ScheduleByTenMinutes() {
 	// 缓存游戏模块主页分组内容
 	task.UpdateMainInfoCache()
+	// 采集在线人数
+	task.CollectSteamPlayerCount()
+	// 缓存在线人数趋势
+	task.UpdatePlayerTrendingCache()
 }
 
 // 任务表
 func ScheduleByOneHour() {
+	// 采集商店详情与更新公告
+	task.CollectSteamAppInfo()
+	// 汇总在线人数日数据
+	task.RollupPlayerCount()
+	// 发送降价提醒
+	task.SendPriceDigest()
 	// 缓存游戏资讯面板数据
 	task.UpdateGamePanelCache()
 	// 缓存更新公告数据
 	task.UpdateGameNewsCache()
+	// 缓存订阅源
+	task.UpdateFeedCache()
 	// 缓存创作者数据
 	task.UpdateGameCreatorCache()
+	// 缓存标签共现统计
+	task.UpdateTagCooccurrenceCache()
+	// 预计算相似游戏索引
+	task.UpdateCBFIndex()
+	// 缓存基于评分的游戏相似度
+	task.UpdateCFModelCache()
+	// 缓存站点地图
+	task.UpdateSitemapCache()
 }
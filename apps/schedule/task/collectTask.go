@@ -0,0 +1,43 @@
+package task
+
+import (
+	"context"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/collector/service"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	"github.com/GoFurry/gofurry-game-backend/roof/env"
+)
+
+// 采集 Steam 商店详情与更新公告, 面板与公告缓存由调用方随后刷新
+func CollectSteamAppInfo() {
+	if !env.GetServerConfig().Collector.IsOn {
+		return
+	}
+	log.Info("CollectTask CollectSteamAppInfo 开始...")
+	res, err := service.GetCollectorService().CollectAppInfo(context.Background())
+	if err != nil {
+		log.Error("CollectTask CollectSteamAppInfo 失败: ", err.GetMsg())
+		return
+	}
+	for _, v := range res.Errors {
+		log.Warn("CollectTask CollectSteamAppInfo ", v)
+	}
+	log.Infof("CollectTask CollectSteamAppInfo 结束, 共 %d 个, 成功 %d 个, 失败 %d 个", res.Total, res.Success, res.Failed)
+}
+
+// 采集 Steam 在线人数
+func CollectSteamPlayerCount() {
+	if !env.GetServerConfig().Collector.IsOn {
+		return
+	}
+	log.Info("CollectTask CollectSteamPlayerCount 开始...")
+	res, err := service.GetCollectorService().CollectPlayerCount(context.Background())
+	if err != nil {
+		log.Error("CollectTask CollectSteamPlayerCount 失败: ", err.GetMsg())
+		return
+	}
+	for _, v := range res.Errors {
+		log.Warn("CollectTask CollectSteamPlayerCount ", v)
+	}
+	log.Infof("CollectTask CollectSteamPlayerCount 结束, 共 %d 个, 成功 %d 个, 失败 %d 个", res.Total, res.Success, res.Failed)
+}
@@ -0,0 +1,13 @@
+package task
+
+import (
+	"github.com/GoFurry/gofurry-game-backend/apps/feed/service"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+)
+
+// 重建 RSS/Atom/JSON 订阅源缓存, 在更新公告采集之后执行
+func UpdateFeedCache() {
+	log.Info("FeedTask UpdateFeedCache 开始...")
+	service.GetFeedService().UpdateFeedCache()
+	log.Info("FeedTask UpdateFeedCache 结束...")
+}
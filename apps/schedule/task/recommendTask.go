@@ -0,0 +1,36 @@
+package task
+
+import (
+	"github.com/GoFurry/gofurry-game-backend/apps/recommend/service"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+)
+
+// 统计标签共现并缓存
+func UpdateTagCooccurrenceCache() {
+	log.Info("RecommendTask UpdateTagCooccurrenceCache 开始...")
+	if err := service.GetTagGraphService().UpdateTagCooccurrenceCache(); err != nil {
+		log.Error("RecommendTask UpdateTagCooccurrenceCache 失败: ", err.GetMsg())
+		return
+	}
+	log.Info("RecommendTask UpdateTagCooccurrenceCache 结束...")
+}
+
+// 预计算 CBF 相似游戏索引, 只重算标签映射变化的游戏
+func UpdateCBFIndex() {
+	log.Info("RecommendTask UpdateCBFIndex 开始...")
+	if err := service.GetRecommendService().UpdateCBFIndex(); err != nil {
+		log.Error("RecommendTask UpdateCBFIndex 失败: ", err.GetMsg())
+		return
+	}
+	log.Info("RecommendTask UpdateCBFIndex 结束...")
+}
+
+// 计算基于评分的游戏相似度并缓存
+func UpdateCFModelCache() {
+	log.Info("RecommendTask UpdateCFModelCache 开始...")
+	if err := service.GetRecommendService().UpdateCFModelCache(); err != nil {
+		log.Error("RecommendTask UpdateCFModelCache 失败: ", err.GetMsg())
+		return
+	}
+	log.Info("RecommendTask UpdateCFModelCache 结束...")
+}
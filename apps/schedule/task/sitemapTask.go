@@ -0,0 +1,16 @@
+package task
+
+import (
+	"github.com/GoFurry/gofurry-game-backend/apps/sitemap/service"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+)
+
+// 重建站点地图缓存
+func UpdateSitemapCache() {
+	log.Info("SitemapTask UpdateSitemapCache 开始...")
+	if err := service.GetSitemapService().UpdateSitemapCache(); err != nil {
+		log.Error("SitemapTask UpdateSitemapCache 失败: ", err.GetMsg())
+		return
+	}
+	log.Info("SitemapTask UpdateSitemapCache 结束...")
+}
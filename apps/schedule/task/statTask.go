@@ -6,6 +6,7 @@ import (
 
 	gd "github.com/GoFurry/gofurry-game-backend/apps/game/dao"
 	gm "github.com/GoFurry/gofurry-game-backend/apps/game/models"
+	gs "github.com/GoFurry/gofurry-game-backend/apps/game/service"
 	rd "github.com/GoFurry/gofurry-game-backend/apps/review/dao"
 	rm "github.com/GoFurry/gofurry-game-backend/apps/review/models"
 	"github.com/GoFurry/gofurry-game-backend/apps/schedule/models"
@@ -280,3 +281,24 @@ func parseGameCreator(records []gm.TempCreator) (res []gm.CreatorVo, err error)
 	}
 	return
 }
+
+// 汇总昨天起的在线人数, 昨天的数据在零点后仍可能补采, 一并重新计算
+func RollupPlayerCount() {
+	log.Info("StatTask RollupPlayerCount 开始...")
+	rows, err := gd.GetGameDao().RollupPlayerCount(time.Now().AddDate(0, 0, -1))
+	if err != nil {
+		log.Error("RollupPlayerCount err:", err.GetMsg())
+		return
+	}
+	log.Infof("StatTask RollupPlayerCount 结束, 写入 %d 条", rows)
+}
+
+func UpdatePlayerTrendingCache() {
+	log.Info("StatTask UpdatePlayerTrendingCache 开始...")
+	for window := range gs.TrendingWindows {
+		if _, err := gs.GetGameOnlineService().UpdateTrendingCache(window); err != nil {
+			log.Error("UpdateTrendingCache ", window, " err:", err.GetMsg())
+		}
+	}
+	log.Info("StatTask UpdatePlayerTrendingCache 结束...")
+}
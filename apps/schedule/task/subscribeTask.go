@@ -0,0 +1,20 @@
+package task
+
+import (
+	"github.com/GoFurry/gofurry-game-backend/apps/subscribe/service"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	"github.com/GoFurry/gofurry-game-backend/roof/env"
+)
+
+// 发送降价提醒, 在商店详情采集之后执行
+func SendPriceDigest() {
+	if !env.GetServerConfig().Mail.IsOn {
+		return
+	}
+	log.Info("SubscribeTask SendPriceDigest 开始...")
+	res, err := service.GetSubscribeService().SendPriceDigest()
+	if err != nil {
+		log.Error("SubscribeTask SendPriceDigest 失败: ", err.GetMsg())
+	}
+	log.Infof("SubscribeTask SendPriceDigest 结束, 价格变化 %d 个, 提醒 %d 个, 发送 %d/%d 封", res.Changed, res.Alerts, res.Sent, res.Emails)
+}
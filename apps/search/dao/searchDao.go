@@ -30,7 +30,7 @@ func (dao searchDao) GetGameListByText(text string, limit int) (res []gm.GfgGame
 
 	// 不区分大小写模糊匹配
 	searchText := "%" + strings.TrimSpace(text) + "%"
-	db.Where("name ILIKE ? OR name_en ILIKE ? OR info ILIKE ? OR info_en ILIKE ?",
+	db.Where("(name ILIKE ? OR name_en ILIKE ? OR info ILIKE ? OR info_en ILIKE ?) AND deleted IS NOT TRUE",
 		searchText, searchText, searchText, searchText)
 	db.Order("weight ASC, update_time DESC").Limit(limit)
 
@@ -103,6 +103,9 @@ func (dao searchDao) Paginate(req models.SearchPageQueryRequest) (cm.PageRespons
 
 // buildSearchPageCondition 构建搜索分页查询条件
 func buildSearchPageCondition(db *gorm.DB, req *models.SearchPageQueryRequest, rootDB *gorm.DB) {
+	// 排除已删除的游戏
+	db.Where("gfg_game.deleted IS NOT TRUE")
+
 	// 更新时间范围筛选
 	if !req.UpdateStartTime.IsZero() && !req.UpdateEndTime.IsZero() {
 		db.Where("gfg_game.update_time BETWEEN ? AND ?", req.UpdateStartTime, req.UpdateEndTime)
@@ -130,11 +133,28 @@ func buildSearchPageCondition(db *gorm.DB, req *models.SearchPageQueryRequest, r
 		`, keyword, keyword, keyword, keyword, keyword, keyword)
 	}
 
-	// 标签筛选
-	if len(req.TagList) > 0 {
+	// 标签筛选 包含子孙标签时每个标签单独匹配其子树
+	if len(req.TagList) > 0 && req.WithDescendants {
+		for _, tagID := range req.TagList {
+			// UNION 会去重, 父子关系成环时递归也会终止
+			subtree := rootDB.Raw(`
+				WITH RECURSIVE subtree(id) AS (
+					SELECT CAST(? AS BIGINT)
+					UNION
+					SELECT gfg_tag.id FROM gfg_tag JOIN subtree ON gfg_tag.prefix = subtree.id WHERE gfg_tag.deleted IS NOT TRUE
+				)
+				SELECT id FROM subtree
+			`, tagID)
+			tagSubQuery := rootDB.Table("gfg_tag_map").
+				Select("game_id").
+				Where("tag_id IN (?) AND deleted IS NOT TRUE", subtree)
+
+			db.Where("gfg_game.id IN (?)", tagSubQuery)
+		}
+	} else if len(req.TagList) > 0 {
 		tagSubQuery := rootDB.Table("gfg_tag_map").
 			Select("game_id").
-			Where("tag_id IN (?)", req.TagList).
+			Where("tag_id IN (?) AND deleted IS NOT TRUE", req.TagList).
 			Group("game_id").
 			Having("COUNT(DISTINCT tag_id) = ?", len(req.TagList))
 
@@ -21,10 +21,11 @@ type SearchPageQueryRequest struct {
 	PubEndTime      cm.LocalTime `json:"pub_end_time"`
 	UpdateStartTime cm.LocalTime `json:"update_start_time"` // 更新时间
 	UpdateEndTime   cm.LocalTime `json:"update_end_time"`
-	ScoreOrder      bool         `json:"score"`        // 评分排序
-	RemarkOrder     bool         `json:"remark_order"` // 评论数排序
-	TimeOrder       bool         `json:"time_order"`   // 更新日期排序
-	TagList         []int64      `json:"tag_list"`     // 标签列表
+	ScoreOrder      bool         `json:"score"`               // 评分排序
+	RemarkOrder     bool         `json:"remark_order"`        // 评论数排序
+	TimeOrder       bool         `json:"time_order"`          // 更新日期排序
+	TagList         []int64      `json:"tag_list"`            // 标签列表
+	WithDescendants bool         `json:"include_descendants"` // 标签同时匹配其子孙标签
 	Lang            string       `json:"lang"`
 }
 
@@ -0,0 +1,62 @@
+package dao
+
+import (
+	"reflect"
+
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/abstract"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var newSeedDao = new(seedDao)
+
+func init() {
+	newSeedDao.Init()
+}
+
+type seedDao struct{ abstract.Dao }
+
+func GetSeedDao() *seedDao { return newSeedDao }
+
+// 单批写入条数
+const batchSize = 200
+
+// Seed 在同一事务中按主键写入各表数据, 每个元素为模型切片的指针, 空切片跳过
+// insertOnly 中的数据与已有记录的主键或唯一索引冲突时跳过, 返回每个切片实际写入的条数
+// overwrite 中的数据已存在时整行覆盖
+func (dao seedDao) Seed(insertOnly []any, overwrite []any) (inserted []int64, err common.GFError) {
+	inserted = make([]int64, len(insertOnly))
+	txErr := dao.Gm.Transaction(func(tx *gorm.DB) error {
+		for i, rows := range insertOnly {
+			if isEmpty(rows) {
+				continue
+			}
+			db := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(rows, batchSize)
+			if db.Error != nil {
+				return db.Error
+			}
+			inserted[i] = db.RowsAffected
+		}
+		for _, rows := range overwrite {
+			if isEmpty(rows) {
+				continue
+			}
+			if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(rows, batchSize).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if txErr != nil {
+		log.Error(txErr)
+		return nil, common.NewDaoError(txErr.Error())
+	}
+	return inserted, nil
+}
+
+func isEmpty(rows any) bool {
+	v := reflect.Indirect(reflect.ValueOf(rows))
+	return v.Kind() == reflect.Slice && v.Len() == 0
+}
@@ -0,0 +1,42 @@
+package models
+
+/*
+ * @Desc: 初始数据
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import gm "github.com/GoFurry/gofurry-game-backend/apps/game/models"
+
+// 夹具文件名(不含扩展名), 按此顺序入库
+const (
+	FixtureAdminUsers   = "admin_users"
+	FixtureGames        = "games"
+	FixtureRecords      = "records"
+	FixtureNews         = "news"
+	FixtureTags         = "tags"
+	FixtureTagMaps      = "tag_maps"
+	FixtureComments     = "comments"
+	FixturePlayerCounts = "player_counts"
+	FixturePriceHistory = "price_history"
+	FixtureCreators     = "creators"
+	FixtureGameInfo     = "game_info"
+)
+
+// 支持的夹具扩展名, 同名文件按此顺序取第一个
+var FixtureExts = []string{".json", ".yaml", ".yml"}
+
+// AdminUserFixture 管理员账号, 密码为明文, 入库前加盐哈希
+type AdminUserFixture struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Role     string `json:"role"`
+}
+
+// GameInfoFixture 游戏详情快照, 写入 Redis game:<lang>-info<ID>
+type GameInfoFixture struct {
+	GameID int64            `json:"gameId,string"`
+	Lang   string           `json:"lang"`
+	Info   gm.GameSaveModel `json:"info"`
+}
@@ -0,0 +1,273 @@
+package seed
+
+/*
+ * @Desc: 从 JSON/YAML 夹具导入初始数据, 便于本地联调
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	am "github.com/GoFurry/gofurry-game-backend/apps/auth/models"
+	gd "github.com/GoFurry/gofurry-game-backend/apps/game/dao"
+	gm "github.com/GoFurry/gofurry-game-backend/apps/game/models"
+	gs "github.com/GoFurry/gofurry-game-backend/apps/game/service"
+	rm "github.com/GoFurry/gofurry-game-backend/apps/recommend/models"
+	rs "github.com/GoFurry/gofurry-game-backend/apps/recommend/service"
+	vm "github.com/GoFurry/gofurry-game-backend/apps/review/models"
+	"github.com/GoFurry/gofurry-game-backend/apps/schedule/task"
+	"github.com/GoFurry/gofurry-game-backend/apps/seed/dao"
+	"github.com/GoFurry/gofurry-game-backend/apps/seed/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	cm "github.com/GoFurry/gofurry-game-backend/common/models"
+	cs "github.com/GoFurry/gofurry-game-backend/common/service"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+	"github.com/GoFurry/gofurry-game-backend/roof/db"
+	"github.com/GoFurry/gofurry-game-backend/roof/env"
+	"github.com/GoFurry/gofurry-game-backend/roof/migrate"
+	"github.com/bytedance/sonic"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultFixtureDir 默认夹具目录
+const DefaultFixtureDir = "./conf/fixtures"
+
+// fixtures 一次导入的全部数据
+type fixtures struct {
+	adminUsers   []models.AdminUserFixture
+	games        []gm.GfgGame
+	records      []gm.GfgGameRecord
+	news         []gm.GfgGameNews
+	tags         []rm.GfgTag
+	tagMaps      []rm.GfgTagMap
+	comments     []vm.GfgGameComment
+	playerCounts []gm.GfgGamePlayerCount
+	priceHistory []gm.GfgGamePriceHistory
+	creators     []gm.GfgGameCreator
+	gameInfo     []models.GameInfoFixture
+}
+
+// Run 执行迁移后导入 dir 下的夹具, 并写入游戏详情依赖的 Redis 数据
+// 数据按主键覆盖写入, 可重复执行; 已存在的管理员账号不会被覆盖
+// 只允许在 debug 模式下执行, 其他模式需指定 force
+func Run(dir string, out io.Writer, force bool) error {
+	if mode := env.GetServerConfig().Server.Mode; mode != "debug" && !force {
+		return fmt.Errorf("seed 仅用于本地联调, 当前 server.mode 为 %q, 确需导入请指定 --force", mode)
+	}
+	if stat, err := os.Stat(dir); err != nil || !stat.IsDir() {
+		return fmt.Errorf("夹具目录不存在: %s", dir)
+	}
+	f, err := load(dir)
+	if err != nil {
+		return err
+	}
+
+	m, err := migrate.New(db.Orm.DB())
+	if err != nil {
+		return err
+	}
+	if err = m.Up(out); err != nil {
+		return err
+	}
+
+	now := cm.LocalTime(time.Now())
+	admins := make([]am.GfgAdminUser, 0, len(f.adminUsers))
+	for _, v := range f.adminUsers {
+		if v.Username == "" || v.Password == "" {
+			return errors.New("管理员账号缺少用户名或密码")
+		}
+		if v.Role != common.ROLE_ADMIN && v.Role != common.ROLE_EDITOR {
+			return fmt.Errorf("管理员 %s 角色有误: %q", v.Username, v.Role)
+		}
+		hash, hashErr := util.HashPassword(v.Password)
+		if hashErr != nil {
+			return fmt.Errorf("管理员 %s 密码哈希失败: %w", v.Username, hashErr)
+		}
+		admins = append(admins, am.GfgAdminUser{
+			ID:         v.ID,
+			Username:   v.Username,
+			Password:   hash,
+			Role:       v.Role,
+			CreateTime: now,
+			UpdateTime: now,
+		})
+	}
+
+	inserted, gfErr := dao.GetSeedDao().Seed([]any{&admins}, []any{&f.games, &f.records, &f.news, &f.tags, &f.tagMaps,
+		&f.comments, &f.playerCounts, &f.priceHistory, &f.creators})
+	if gfErr != nil {
+		return errors.New(gfErr.GetMsg())
+	}
+	if skipped := int64(len(admins)) - inserted[0]; skipped > 0 {
+		fmt.Fprintf(out, "skipped %-13s %d (already exist)\n", models.FixtureAdminUsers, skipped)
+	}
+	for _, line := range []struct {
+		name  string
+		count int
+	}{
+		{models.FixtureAdminUsers, int(inserted[0])},
+		{models.FixtureGames, len(f.games)},
+		{models.FixtureRecords, len(f.records)},
+		{models.FixtureNews, len(f.news)},
+		{models.FixtureTags, len(f.tags)},
+		{models.FixtureTagMaps, len(f.tagMaps)},
+		{models.FixtureComments, len(f.comments)},
+		{models.FixturePlayerCounts, len(f.playerCounts)},
+		{models.FixturePriceHistory, len(f.priceHistory)},
+		{models.FixtureCreators, len(f.creators)},
+	} {
+		fmt.Fprintf(out, "seeded %-14s %d\n", line.name, line.count)
+	}
+
+	rows, gfErr := gd.GetGameDao().RollupPlayerCount(time.Time{})
+	if gfErr != nil {
+		return errors.New(gfErr.GetMsg())
+	}
+	fmt.Fprintf(out, "rollup %-14s %d\n", models.FixturePlayerCounts, rows)
+
+	if err = cacheGameDetail(f); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "cached %-14s %d\n", models.FixtureGameInfo, len(f.gameInfo))
+
+	refreshCache()
+	fmt.Fprintln(out, "cache refreshed")
+	return nil
+}
+
+// load 读取目录下的全部夹具, 缺失的文件视为空
+func load(dir string) (f fixtures, err error) {
+	steps := []struct {
+		name string
+		out  any
+	}{
+		{models.FixtureAdminUsers, &f.adminUsers},
+		{models.FixtureGames, &f.games},
+		{models.FixtureRecords, &f.records},
+		{models.FixtureNews, &f.news},
+		{models.FixtureTags, &f.tags},
+		{models.FixtureTagMaps, &f.tagMaps},
+		{models.FixtureComments, &f.comments},
+		{models.FixturePlayerCounts, &f.playerCounts},
+		{models.FixturePriceHistory, &f.priceHistory},
+		{models.FixtureCreators, &f.creators},
+		{models.FixtureGameInfo, &f.gameInfo},
+	}
+	for _, step := range steps {
+		if err = loadFixture(dir, step.name, step.out); err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
+
+// loadFixture 按扩展名解析单个夹具文件
+// YAML 先转换为 JSON 再解析, 两种格式共用模型上的 json 标签
+func loadFixture(dir string, name string, out any) error {
+	for _, ext := range models.FixtureExts {
+		path := filepath.Join(dir, name+ext)
+		content, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if ext != ".json" {
+			var raw any
+			if err = yaml.Unmarshal(content, &raw); err != nil {
+				return fmt.Errorf("解析夹具 %s 失败: %w", path, err)
+			}
+			if content, err = sonic.Marshal(yamlToJSON(raw)); err != nil {
+				return fmt.Errorf("转换夹具 %s 失败: %w", path, err)
+			}
+		}
+		if err = sonic.Unmarshal(content, out); err != nil {
+			return fmt.Errorf("解析夹具 %s 失败: %w", path, err)
+		}
+		return nil
+	}
+	return nil
+}
+
+// yamlToJSON 将 yaml.v2 解析出的 map[interface{}]interface{} 转为可 JSON 序列化的结构
+func yamlToJSON(v any) any {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]any, len(val))
+		for k, item := range val {
+			res[fmt.Sprint(k)] = yamlToJSON(item)
+		}
+		return res
+	case []interface{}:
+		for i, item := range val {
+			val[i] = yamlToJSON(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// cacheGameDetail 写入游戏详情快照与最新在线人数
+// 没有在线人数的游戏写入 0, 保证详情接口可用
+func cacheGameDetail(f fixtures) error {
+	for _, v := range f.gameInfo {
+		lang := strings.ToLower(v.Lang)
+		if lang != "zh" && lang != "en" {
+			return fmt.Errorf("游戏 %d 详情语言有误: %q", v.GameID, v.Lang)
+		}
+		info, err := sonic.Marshal(v.Info)
+		if err != nil {
+			return err
+		}
+		if gfErr := cs.Set("game:"+lang+"-info"+strconv.FormatInt(v.GameID, 10), string(info)); gfErr != nil {
+			return errors.New(gfErr.GetMsg())
+		}
+	}
+
+	latest := make(map[int64]gm.GfgGamePlayerCount, len(f.games))
+	for _, v := range f.playerCounts {
+		if cur, ok := latest[v.GameID]; !ok || v.CreateTime.Time().After(cur.CreateTime.Time()) {
+			latest[v.GameID] = v
+		}
+	}
+	for _, game := range f.games {
+		online := gm.GameOnlineModel{GameID: strconv.FormatInt(game.ID, 10)}
+		if count, ok := latest[game.ID]; ok {
+			online.ID = count.ID
+			online.Count = count.Count_
+			online.CreateTime = count.CreateTime.String()
+		} else {
+			online.CreateTime = time.Now().Format(common.TIME_FORMAT_DATE)
+		}
+		data, err := sonic.Marshal(online)
+		if err != nil {
+			return err
+		}
+		if gfErr := cs.Set("game:online"+online.GameID, string(data)); gfErr != nil {
+			return errors.New(gfErr.GetMsg())
+		}
+	}
+	return nil
+}
+
+// refreshCache 清理推荐缓存并同步重建首页相关缓存
+func refreshCache() {
+	gs.GetGameService().InvalidateInfoCache()
+	gs.GetGameService().InvalidateCreatorCache()
+	rs.GetRecommendService().InvalidateCache()
+	task.UpdateMainInfoCache()
+	task.UpdateGamePanelCache()
+	task.UpdateGameNewsCache()
+	task.UpdateGameCreatorCache()
+	task.UpdatePlayerTrendingCache()
+}
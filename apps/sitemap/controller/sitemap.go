@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/sitemap/service"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/gofiber/fiber/v2"
+)
+
+type sitemapApi struct{}
+
+var SitemapApi *sitemapApi
+
+func init() {
+	SitemapApi = &sitemapApi{}
+}
+
+// @Summary 站点地图索引
+// @Schemes
+// @Description 列出游戏、标签、创作者页面的所有站点地图分片
+// @Tags Sitemap
+// @Produce xml
+// @Success 200 {string} string
+// @Router /sitemap.xml [Get]
+func (api *sitemapApi) GetIndex(c *fiber.Ctx) error {
+	return send(c, "index")
+}
+
+// @Summary 站点地图分片
+// @Schemes
+// @Description 单个站点地图分片, 每个页面包含 zh/en 的 hreflang 备用链接
+// @Tags Sitemap
+// @Produce xml
+// @Param name path string true "分片名, 如 game-1.xml"
+// @Success 200 {string} string
+// @Router /sitemap/{name} [Get]
+func (api *sitemapApi) GetChunk(c *fiber.Ctx) error {
+	name, ok := strings.CutSuffix(c.Params("name"), ".xml")
+	if !ok {
+		return common.NewResponse(c).ErrorWithCode("站点地图不存在", fiber.StatusNotFound)
+	}
+	return send(c, name)
+}
+
+func send(c *fiber.Ctx, name string) error {
+	body, err := service.GetSitemapService().GetSitemap(name)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+	if body == "" {
+		return common.NewResponse(c).ErrorWithCode("站点地图不存在", fiber.StatusNotFound)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXMLCharsetUTF8)
+	c.Set(fiber.HeaderCacheControl, "public, max-age=3600")
+	return c.SendString(body)
+}
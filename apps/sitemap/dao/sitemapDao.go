@@ -0,0 +1,38 @@
+package dao
+
+import (
+	gm "github.com/GoFurry/gofurry-game-backend/apps/game/models"
+	rm "github.com/GoFurry/gofurry-game-backend/apps/recommend/models"
+	"github.com/GoFurry/gofurry-game-backend/apps/sitemap/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/abstract"
+)
+
+var newSitemapDao = new(sitemapDao)
+
+func init() {
+	newSitemapDao.Init()
+}
+
+type sitemapDao struct{ abstract.Dao }
+
+func GetSitemapDao() *sitemapDao { return newSitemapDao }
+
+var kindTables = map[string]string{
+	models.KindGame:    gm.TableNameGfgGame,
+	models.KindTag:     rm.TableNameGfgTag,
+	models.KindCreator: gm.TableNameGfgGameCreator,
+}
+
+// ListPages 按 ID 顺序获取某类未删除页面的 ID 与更新时间
+func (dao sitemapDao) ListPages(kind string) (res []models.PageModel, err common.GFError) {
+	table, ok := kindTables[kind]
+	if !ok {
+		return res, common.NewDaoError("未知的页面类型: " + kind)
+	}
+	db := dao.Gm.Table(table).Select("id, update_time").Where("deleted IS NOT TRUE").Order("id").Find(&res)
+	if dbErr := db.Error; dbErr != nil {
+		return res, common.NewDaoError(dbErr.Error())
+	}
+	return res, nil
+}
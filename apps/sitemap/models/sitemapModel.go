@@ -0,0 +1,56 @@
+package models
+
+/*
+ * @Desc: 站点地图
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import (
+	"encoding/xml"
+
+	cm "github.com/GoFurry/gofurry-game-backend/common/models"
+)
+
+// 站点地图中的页面类型, 同时作为分片文件名前缀
+const (
+	KindGame    = "game"
+	KindTag     = "tag"
+	KindCreator = "creator"
+)
+
+// PageModel 需要收录的页面记录
+type PageModel struct {
+	ID         int64        `gorm:"column:id"`
+	UpdateTime cm.LocalTime `gorm:"column:update_time"`
+}
+
+// SitemapIndex <sitemapindex>
+type SitemapIndex struct {
+	XMLName  xml.Name  `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []Sitemap `xml:"sitemap"`
+}
+
+type Sitemap struct {
+	Loc     string `xml:"loc"`
+	Lastmod string `xml:"lastmod,omitempty"`
+}
+
+// URLSet <urlset>, 多语言页面通过 xhtml:link 声明
+type URLSet struct {
+	XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	XhtmlNS string   `xml:"xmlns:xhtml,attr"`
+	URLs    []URL    `xml:"url"`
+}
+
+type URL struct {
+	Loc        string      `xml:"loc"`
+	Lastmod    string      `xml:"lastmod,omitempty"`
+	Alternates []Alternate `xml:"xhtml:link"`
+}
+
+type Alternate struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/sitemap/dao"
+	"github.com/GoFurry/gofurry-game-backend/apps/sitemap/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	cs "github.com/GoFurry/gofurry-game-backend/common/service"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+	"github.com/GoFurry/gofurry-game-backend/roof/env"
+)
+
+type sitemapService struct{}
+
+var sitemapSingleton = new(sitemapService)
+
+func GetSitemapService() *sitemapService { return sitemapSingleton }
+
+// Redis 定义, 索引与分片均为完整的 XML 文本
+const (
+	redisSitemapPrefix  = "sitemap:" // sitemap:index / sitemap:<类型>-<序号>
+	sitemapIndexName    = "index"
+	sitemapCacheTimeout = 3 * time.Hour
+	sitemapChunkTimeout = sitemapCacheTimeout + 10*time.Minute // 分片晚于索引过期, 索引引用的分片始终存在
+	sitemapMaxChunk     = 50000                                // 协议规定单个站点地图最多 50000 个 URL
+)
+
+var (
+	sitemapKinds = []string{models.KindGame, models.KindTag, models.KindCreator}
+	sitemapLangs = []string{"zh", "en"}
+	// 分片名, 如 game-1
+	chunkPattern = regexp.MustCompile(`^(game|tag|creator)-[1-9][0-9]*$`)
+	// 缓存未命中时只重建一次
+	rebuildMu sync.Mutex
+)
+
+// GetSitemap 获取站点地图, name 为 index 或分片名, 不存在时返回空字符串
+// 只有索引缺失时才重建, 索引仍在时未命中的分片一定不存在, 不会触发全量重建
+func (s sitemapService) GetSitemap(name string) (string, common.GFError) {
+	if name != sitemapIndexName && !chunkPattern.MatchString(name) {
+		return "", nil
+	}
+	if body, err := cs.GetString(redisSitemapPrefix + name); err == nil && body != "" {
+		return body, nil
+	}
+	if name != sitemapIndexName {
+		if index, err := cs.GetString(redisSitemapPrefix + sitemapIndexName); err == nil && index != "" {
+			return "", nil
+		}
+	}
+
+	rebuildMu.Lock()
+	defer rebuildMu.Unlock()
+	if index, err := cs.GetString(redisSitemapPrefix + sitemapIndexName); err != nil || index == "" {
+		if err := s.UpdateSitemapCache(); err != nil {
+			return "", err
+		}
+	}
+	body, _ := cs.GetString(redisSitemapPrefix + name)
+	return body, nil
+}
+
+// UpdateSitemapCache 重建所有分片, 最后写入索引
+// 数量减少后多出的旧分片不再被索引引用, 到期自动清除, 在此之前请求旧分片仍会返回旧内容
+func (s sitemapService) UpdateSitemapCache() common.GFError {
+	site := env.GetServerConfig().Site
+	chunk := site.SitemapChunk
+	if chunk <= 0 || chunk > sitemapMaxChunk {
+		chunk = sitemapMaxChunk
+	}
+	// 每个页面按语言生成多条 URL
+	pagesPerChunk := max(chunk/len(sitemapLangs), 1)
+
+	index := models.SitemapIndex{}
+	for _, kind := range sitemapKinds {
+		path := pagePath(site, kind)
+		if path == "" {
+			continue
+		}
+		pages, err := dao.GetSitemapDao().ListPages(kind)
+		if err != nil {
+			return err
+		}
+		for n, start := 1, 0; start < len(pages); n, start = n+1, start+pagesPerChunk {
+			name := fmt.Sprintf("%s-%d", kind, n)
+			body, lastmod, err := renderURLSet(site, path, pages[start:min(start+pagesPerChunk, len(pages))])
+			if err != nil {
+				return err
+			}
+			if err = cs.SetExpire(redisSitemapPrefix+name, body, sitemapChunkTimeout); err != nil {
+				return err
+			}
+			index.Sitemaps = append(index.Sitemaps, models.Sitemap{
+				Loc:     strings.TrimRight(site.APIURL, "/") + "/sitemap/" + name + ".xml",
+				Lastmod: lastmod,
+			})
+		}
+	}
+
+	body, err := marshalXML(index)
+	if err != nil {
+		return err
+	}
+	return cs.SetExpire(redisSitemapPrefix+sitemapIndexName, body, sitemapCacheTimeout)
+}
+
+// renderURLSet 生成单个分片, 返回分片内最新的更新时间
+func renderURLSet(site env.SiteConfig, path string, pages []models.PageModel) (body string, lastmod string, err common.GFError) {
+	set := models.URLSet{XhtmlNS: "http://www.w3.org/1999/xhtml"}
+	var latest time.Time
+	for _, page := range pages {
+		id := util.Int642String(page.ID)
+		updated := page.UpdateTime.Time()
+		if updated.After(latest) {
+			latest = updated
+		}
+
+		alternates := make([]models.Alternate, 0, len(sitemapLangs)+1)
+		for _, lang := range sitemapLangs {
+			alternates = append(alternates, models.Alternate{Rel: "alternate", Hreflang: lang, Href: site.PageURL(path, lang, id)})
+		}
+		alternates = append(alternates, models.Alternate{Rel: "alternate", Hreflang: "x-default", Href: site.PageURL(path, sitemapLangs[0], id)})
+
+		for _, lang := range sitemapLangs {
+			set.URLs = append(set.URLs, models.URL{
+				Loc:        site.PageURL(path, lang, id),
+				Lastmod:    formatLastmod(updated),
+				Alternates: alternates,
+			})
+		}
+	}
+	if body, err = marshalXML(set); err != nil {
+		return
+	}
+	return body, formatLastmod(latest), nil
+}
+
+func marshalXML(v any) (string, common.GFError) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Error("marshal sitemap err: ", err)
+		return "", common.NewServiceError("生成站点地图失败")
+	}
+	return xml.Header + string(body), nil
+}
+
+func pagePath(site env.SiteConfig, kind string) string {
+	switch kind {
+	case models.KindGame:
+		return site.GamePath
+	case models.KindTag:
+		return site.TagPath
+	case models.KindCreator:
+		return site.CreatorPath
+	}
+	return ""
+}
+
+// formatLastmod W3C Datetime, 零值时省略
+func formatLastmod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"github.com/GoFurry/gofurry-game-backend/apps/subscribe/models"
+	"github.com/GoFurry/gofurry-game-backend/apps/subscribe/service"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/abstract"
+	"github.com/gofiber/fiber/v2"
+)
+
+type subscribeApi struct{}
+
+var SubscribeApi *subscribeApi
+
+func init() {
+	SubscribeApi = &subscribeApi{}
+}
+
+// @Summary 申请订阅验证码
+// @Schemes
+// @Description 向邮箱发送订阅验证码, 同一邮箱一分钟内只能申请一次, 同一 IP 每小时最多申请 10 次
+// @Tags Subscribe
+// @Accept json
+// @Produce json
+// @Param body body models.SubscribeCodeRequest true "请求body"
+// @Success 200 {object} common.ResultData
+// @Router /api/subscribe/code [Post]
+func (api *subscribeApi) SendCode(c *fiber.Ctx) error {
+	req := models.SubscribeCodeRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	if err := service.GetSubscribeService().SendCode(req, c.IP()); err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).Success()
+}
+
+// @Summary 订阅游戏降价提醒
+// @Schemes
+// @Description 校验验证码后订阅, 价格低于订阅时的价格或折扣达到阈值时发送邮件
+// @Tags Subscribe
+// @Accept json
+// @Produce json
+// @Param body body models.SubscribeRequest true "请求body"
+// @Success 200 {object} models.SubscribeVo
+// @Router /api/subscribe [Post]
+func (api *subscribeApi) Subscribe(c *fiber.Ctx) error {
+	req := models.SubscribeRequest{}
+	if err := c.BodyParser(&req); err != nil {
+		return common.NewResponse(c).Error("解析请求体失败")
+	}
+	if errs := abstract.ValidateServiceApi.Validate(&req); len(errs) > 0 {
+		return common.NewResponse(c).Error(errs)
+	}
+	data, err := service.GetSubscribeService().Subscribe(req)
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 退订降价提醒
+// @Schemes
+// @Description 邮件中的退订链接, 需携带签名
+// @Tags Subscribe
+// @Accept json
+// @Produce json
+// @Param id query string true "订阅id"
+// @Param sign query string true "签名"
+// @Success 200 {object} common.ResultData
+// @Router /api/subscribe/unsubscribe [Get]
+func (api *subscribeApi) Unsubscribe(c *fiber.Ctx) error {
+	id := c.Query("id", "0")
+	sign := c.Query("sign")
+	if err := service.GetSubscribeService().Unsubscribe(id, sign); err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).Success()
+}
@@ -0,0 +1,127 @@
+package dao
+
+import (
+	"errors"
+	"time"
+
+	gm "github.com/GoFurry/gofurry-game-backend/apps/game/models"
+	"github.com/GoFurry/gofurry-game-backend/apps/subscribe/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/abstract"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	cm "github.com/GoFurry/gofurry-game-backend/common/models"
+	"gorm.io/gorm"
+)
+
+var newSubscribeDao = new(subscribeDao)
+
+func init() {
+	newSubscribeDao.Init()
+}
+
+type subscribeDao struct{ abstract.Dao }
+
+func GetSubscribeDao() *subscribeDao { return newSubscribeDao }
+
+// ExistsGame 判断游戏是否存在且未删除
+func (dao subscribeDao) ExistsGame(gameID int64) (bool, common.GFError) {
+	var count int64
+	db := dao.Gm.Table(gm.TableNameGfgGame).Where("id = ? AND deleted IS NOT TRUE", gameID).Count(&count)
+	if dbErr := db.Error; dbErr != nil {
+		return false, common.NewDaoError(dbErr.Error())
+	}
+	return count > 0, nil
+}
+
+// GetGamePrice 获取未删除游戏某语言的当前价格记录
+func (dao subscribeDao) GetGamePrice(gameID int64, lang string) (res gm.GfgGameRecord, err common.GFError) {
+	db := dao.Gm.Table(gm.TableNameGfgGameRecord).Select("game_id, lang, initial, final, discount").
+		Where("game_id = ? AND lang = ?", gameID, lang).
+		Where("game_id IN (?)", dao.Gm.Table(gm.TableNameGfgGame).Select("id").Where("deleted IS NOT TRUE"))
+	if dbErr := db.Take(&res).Error; dbErr != nil {
+		if errors.Is(dbErr, gorm.ErrRecordNotFound) {
+			return res, common.NewDaoError("游戏不存在或暂无价格记录")
+		}
+		return res, common.NewDaoError(dbErr.Error())
+	}
+	return res, nil
+}
+
+// GetSubscription 获取未退订的订阅, 不存在时 found 为 false
+func (dao subscribeDao) GetSubscription(where string, args ...any) (res models.GfgGameSubscription, found bool, err common.GFError) {
+	db := dao.Gm.Table(models.TableNameGfgGameSubscription).Where("deleted IS NOT TRUE").Where(where, args...)
+	if dbErr := db.Take(&res).Error; dbErr != nil {
+		if errors.Is(dbErr, gorm.ErrRecordNotFound) {
+			return res, false, nil
+		}
+		return res, false, common.NewDaoError(dbErr.Error())
+	}
+	return res, true, nil
+}
+
+// SaveSubscription 保存订阅设置并重置价格基准
+func (dao subscribeDao) SaveSubscription(record models.GfgGameSubscription) common.GFError {
+	db := dao.Gm.Table(models.TableNameGfgGameSubscription).Where("id = ?", record.ID).
+		Select("lang", "threshold", "last_final", "last_discount", "update_time").Updates(&record)
+	if dbErr := db.Error; dbErr != nil {
+		log.Error(dbErr)
+		return common.NewDaoError(dbErr.Error())
+	}
+	return nil
+}
+
+// Unsubscribe 软删除订阅
+func (dao subscribeDao) Unsubscribe(id int64) common.GFError {
+	db := dao.Gm.Table(models.TableNameGfgGameSubscription).Where("id = ?", id).
+		Updates(map[string]any{"deleted": true, "update_time": cm.LocalTime(time.Now())})
+	if dbErr := db.Error; dbErr != nil {
+		log.Error(dbErr)
+		return common.NewDaoError(dbErr.Error())
+	}
+	return nil
+}
+
+// ListPriceChanged 获取当前价格或折扣与基准不同的订阅, 按邮箱分组排序
+func (dao subscribeDao) ListPriceChanged() (res []models.PriceChangeModel, err common.GFError) {
+	subTable := models.TableNameGfgGameSubscription
+	recordTable := gm.TableNameGfgGameRecord
+	gameTable := gm.TableNameGfgGame
+
+	db := dao.Gm.Table(subTable).
+		Joins("JOIN " + recordTable + " ON " + recordTable + ".game_id = " + subTable + ".game_id AND " + recordTable + ".lang = " + subTable + ".lang").
+		Joins("JOIN " + gameTable + " ON " + gameTable + ".id = " + subTable + ".game_id").
+		Select(subTable + ".*, " + gameTable + ".name, " + gameTable + ".name_en, " +
+			recordTable + ".initial, " + recordTable + ".final, " + recordTable + ".discount").
+		Where(subTable + ".deleted IS NOT TRUE AND " + gameTable + ".deleted IS NOT TRUE").
+		Where("(" + recordTable + ".final <> " + subTable + ".last_final OR " + recordTable + ".discount <> " + subTable + ".last_discount)").
+		Order(subTable + ".email, " + subTable + ".id")
+	if dbErr := db.Find(&res).Error; dbErr != nil {
+		return res, common.NewDaoError(dbErr.Error())
+	}
+	return res, nil
+}
+
+// UpdateBaseline 将订阅的价格基准更新为当前价格, notified 中的订阅同时记录提醒时间
+func (dao subscribeDao) UpdateBaseline(changes []models.PriceChangeModel, notified map[int64]bool) common.GFError {
+	if len(changes) == 0 {
+		return nil
+	}
+	now := cm.LocalTime(time.Now())
+	err := dao.Gm.Transaction(func(tx *gorm.DB) error {
+		for _, v := range changes {
+			fields := map[string]any{"last_final": v.Final, "last_discount": v.Discount, "update_time": now}
+			if notified[v.ID] {
+				fields["notify_time"] = now
+			}
+			if err := tx.Table(models.TableNameGfgGameSubscription).Where("id = ?", v.ID).Updates(fields).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error(err)
+		return common.NewDaoError(err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package models
+
+/*
+ * @Desc: 降价订阅
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import cm "github.com/GoFurry/gofurry-game-backend/common/models"
+
+const TableNameGfgGameSubscription = "gfg_game_subscription"
+
+// GfgGameSubscription mapped from table <gfg_game_subscription>
+type GfgGameSubscription struct {
+	ID           int64         `gorm:"column:id;type:bigint;primaryKey;comment:订阅表ID" json:"id"`                                         // 订阅表ID
+	Email        string        `gorm:"column:email;type:character varying(255);not null;comment:邮箱" json:"email"`                        // 邮箱
+	GameID       int64         `gorm:"column:game_id;type:bigint;not null;comment:游戏表ID" json:"gameId,string"`                           // 游戏表ID
+	Lang         string        `gorm:"column:lang;type:character varying(20);not null;comment:订阅的价格记录语言" json:"lang"`                    // 订阅的价格记录语言
+	Threshold    int64         `gorm:"column:threshold;type:bigint;not null;comment:折扣阈值" json:"threshold"`                              // 折扣阈值
+	LastFinal    int64         `gorm:"column:last_final;type:bigint;not null;comment:上次提醒时的价格" json:"lastFinal"`                         // 上次提醒时的价格
+	LastDiscount int64         `gorm:"column:last_discount;type:bigint;not null;comment:上次提醒时的折扣" json:"lastDiscount"`                   // 上次提醒时的折扣
+	NotifyTime   *cm.LocalTime `gorm:"column:notify_time;type:int;type:unsigned;comment:上次提醒时间" json:"notifyTime"`                       // 上次提醒时间
+	CreateTime   cm.LocalTime  `gorm:"column:create_time;type:int;type:unsigned;not null;autoCreateTime;comment:创建时间" json:"createTime"` // 创建时间
+	UpdateTime   cm.LocalTime  `gorm:"column:update_time;type:int;type:unsigned;not null;autoUpdateTime;comment:更新时间" json:"updateTime"` // 更新时间
+	Deleted      bool          `gorm:"column:deleted;type:boolean;comment:软删除" json:"deleted"`                                           // 软删除
+}
+
+// TableName GfgGameSubscription's table name
+func (*GfgGameSubscription) TableName() string {
+	return TableNameGfgGameSubscription
+}
+
+// SubscribeCodeRequest 申请订阅验证码
+type SubscribeCodeRequest struct {
+	Email  string `json:"email" validate:"required,email,max=255" label:"邮箱"`
+	GameID string `json:"game_id" validate:"required,number" label:"游戏ID"`
+}
+
+// SubscribeRequest 校验验证码并订阅, 阈值为 0 时使用默认阈值
+type SubscribeRequest struct {
+	cm.EmailCodeModel
+	GameID    string `json:"game_id" validate:"required,number" label:"游戏ID"`
+	Lang      string `json:"lang" validate:"omitempty,oneof=zh en" label:"语言"`
+	Threshold int64  `json:"threshold" validate:"gte=0,lte=100" label:"折扣阈值"`
+}
+
+type SubscribeVo struct {
+	ID        string `json:"id"`
+	GameID    string `json:"game_id"`
+	Lang      string `json:"lang"`
+	Threshold int64  `json:"threshold"`
+}
+
+// PriceChangeModel 当前价格与基准不同的订阅
+type PriceChangeModel struct {
+	GfgGameSubscription
+	Name     string `gorm:"column:name"`
+	NameEn   string `gorm:"column:name_en"`
+	Initial  int64  `gorm:"column:initial"`
+	Final    int64  `gorm:"column:final"`
+	Discount int64  `gorm:"column:discount"`
+}
+
+// DigestVo 一次降价提醒的发送结果
+type DigestVo struct {
+	Changed int `json:"changed"` // 价格变化的订阅数
+	Alerts  int `json:"alerts"`  // 需要提醒的订阅数
+	Emails  int `json:"emails"`  // 需要发送的邮件数
+	Sent    int `json:"sent"`    // 成功发送的邮件数
+}
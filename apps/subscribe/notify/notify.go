@@ -0,0 +1,209 @@
+package notify
+
+/*
+ * @Desc: 订阅邮件, 包括验证码、降价提醒与退订签名
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strconv"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/subscribe/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	cs "github.com/GoFurry/gofurry-game-backend/common/service"
+)
+
+// Store 降价提醒读写的订阅数据
+type Store interface {
+	// ListPriceChanged 获取当前价格或折扣与基准不同的订阅, 按邮箱分组排序
+	ListPriceChanged() ([]models.PriceChangeModel, common.GFError)
+	// UpdateBaseline 将订阅的价格基准更新为当前价格, notified 中的订阅同时记录提醒时间
+	UpdateBaseline(changes []models.PriceChangeModel, notified map[int64]bool) common.GFError
+}
+
+// Sender 发送邮件, 返回成功发送的数量
+type Sender func(messages ...cs.MailMessage) (int, common.GFError)
+
+// Signer 退订链接签名
+type Signer struct {
+	Secret string // 签名密钥 mail.sign_secret
+	URL    string // 退订接口地址 mail.unsubscribe_url
+}
+
+// Sign 退订签名, 绑定订阅 ID 与邮箱
+func (s Signer) Sign(record models.GfgGameSubscription) string {
+	mac := hmac.New(sha256.New, []byte(s.Secret))
+	mac.Write([]byte(fmt.Sprintf("unsubscribe:%d:%s", record.ID, record.Email)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 校验退订签名
+func (s Signer) Verify(record models.GfgGameSubscription, sign string) bool {
+	return hmac.Equal([]byte(s.Sign(record)), []byte(sign))
+}
+
+// UnsubscribeURL 生成带签名的退订链接
+func (s Signer) UnsubscribeURL(record models.GfgGameSubscription) string {
+	query := url.Values{}
+	query.Set("id", strconv.FormatInt(record.ID, 10))
+	query.Set("sign", s.Sign(record))
+	return s.URL + "?" + query.Encode()
+}
+
+// CodeMail 生成订阅验证码邮件, expire 为有效分钟数
+func CodeMail(to string, code string, expire int) (msg cs.MailMessage, err common.GFError) {
+	body, err := render(codeTemplate, map[string]any{"Code": code, "Expire": expire})
+	if err != nil {
+		return msg, err
+	}
+	return cs.MailMessage{To: to, Subject: "GoFurry 订阅验证码 / Verification code", Body: body}, nil
+}
+
+// SendDigest 发送降价提醒: 价格低于基准或折扣首次达到阈值时提醒, 同一邮箱的提醒合并为一封
+// 邮件发送成功后将基准更新为当前价格; 价格上涨等无需提醒的变化直接更新基准
+func SendDigest(store Store, send Sender, signer Signer) (res models.DigestVo, err common.GFError) {
+	changes, err := store.ListPriceChanged()
+	if err != nil {
+		return res, err
+	}
+	res.Changed = len(changes)
+
+	// 记录已按邮箱排序, 按邮箱切分
+	var messages []cs.MailMessage
+	var groups [][]models.PriceChangeModel
+	for begin := 0; begin < len(changes); {
+		end := begin
+		var alerts []models.PriceChangeModel
+		for end < len(changes) && changes[end].Email == changes[begin].Email {
+			if isAlert(changes[end]) {
+				alerts = append(alerts, changes[end])
+			}
+			end++
+		}
+		begin = end
+		if len(alerts) == 0 {
+			continue
+		}
+		msg, buildErr := buildDigest(alerts, signer)
+		if buildErr != nil {
+			return res, buildErr
+		}
+		res.Alerts += len(alerts)
+		messages = append(messages, msg)
+		groups = append(groups, alerts)
+	}
+	res.Emails = len(messages)
+
+	var sent int
+	var sendErr common.GFError
+	if len(messages) > 0 {
+		sent, sendErr = send(messages...)
+	}
+	res.Sent = sent
+	notified := make(map[int64]bool)
+	for _, group := range groups[:sent] {
+		for _, v := range group {
+			notified[v.ID] = true
+		}
+	}
+	// 未发送成功的提醒保留基准, 下次重试
+	pending := make(map[int64]bool)
+	for _, group := range groups[sent:] {
+		for _, v := range group {
+			pending[v.ID] = true
+		}
+	}
+	update := make([]models.PriceChangeModel, 0, len(changes))
+	for _, v := range changes {
+		if !pending[v.ID] {
+			update = append(update, v)
+		}
+	}
+	if err = store.UpdateBaseline(update, notified); err != nil {
+		return res, err
+	}
+	if sendErr != nil {
+		log.Error("SendDigest 已发送 ", sent, "/", len(messages), " 封: ", sendErr.GetMsg())
+		return res, sendErr
+	}
+	return res, nil
+}
+
+// isAlert 价格低于基准, 或折扣从阈值以下达到阈值
+func isAlert(v models.PriceChangeModel) bool {
+	if v.Final < v.LastFinal {
+		return true
+	}
+	return v.Threshold > 0 && v.Discount >= v.Threshold && v.LastDiscount < v.Threshold
+}
+
+// buildDigest 生成一个邮箱的降价提醒, 语言取第一条订阅的语言
+func buildDigest(alerts []models.PriceChangeModel, signer Signer) (msg cs.MailMessage, err common.GFError) {
+	zh := alerts[0].Lang == "zh"
+	items := make([]map[string]any, len(alerts))
+	for i, v := range alerts {
+		name := v.Name
+		if !zh {
+			name = v.NameEn
+		}
+		// 价格取自订阅语言对应的记录, zh 为国区, en 为美区
+		symbol := "¥"
+		if v.Lang != "zh" {
+			symbol = "$"
+		}
+		items[i] = map[string]any{
+			"Name":        name,
+			"Initial":     formatPrice(symbol, v.Initial),
+			"Final":       formatPrice(symbol, v.Final),
+			"LastFinal":   formatPrice(symbol, v.LastFinal),
+			"Discount":    v.Discount,
+			"Unsubscribe": signer.UnsubscribeURL(v.GfgGameSubscription),
+		}
+	}
+	tpl, subject := digestTemplateEn, fmt.Sprintf("GoFurry: %d game(s) on sale", len(alerts))
+	if zh {
+		tpl, subject = digestTemplateZh, fmt.Sprintf("GoFurry 降价提醒: %d 款游戏降价", len(alerts))
+	}
+	body, err := render(tpl, map[string]any{"Items": items})
+	if err != nil {
+		return msg, err
+	}
+	return cs.MailMessage{To: alerts[0].Email, Subject: subject, Body: body}, nil
+}
+
+// formatPrice 价格以分为单位
+func formatPrice(symbol string, cents int64) string {
+	return fmt.Sprintf("%s%d.%02d", symbol, cents/100, cents%100)
+}
+
+func render(tpl *template.Template, data any) (string, common.GFError) {
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		log.Error("render mail template err: ", err)
+		return "", common.NewServiceError("生成邮件内容失败")
+	}
+	return buf.String(), nil
+}
+
+var codeTemplate = template.Must(template.New("code").Parse(`<p>您的订阅验证码为 / Your verification code is:</p>
+<p style="font-size:24px;font-weight:bold;letter-spacing:4px">{{.Code}}</p>
+<p>{{.Expire}} 分钟内有效 / Valid for {{.Expire}} minutes.</p>`))
+
+var digestTemplateZh = template.Must(template.New("digest-zh").Parse(`<p>您订阅的游戏有新的优惠:</p>
+<ul>{{range .Items}}
+<li><b>{{.Name}}</b> {{.Final}} <s>{{.Initial}}</s> -{{.Discount}}% (上次 {{.LastFinal}}) <a href="{{.Unsubscribe}}">退订</a></li>{{end}}
+</ul>`))
+
+var digestTemplateEn = template.Must(template.New("digest-en").Parse(`<p>Games you follow are on sale:</p>
+<ul>{{range .Items}}
+<li><b>{{.Name}}</b> {{.Final}} <s>{{.Initial}}</s> -{{.Discount}}% (was {{.LastFinal}}) <a href="{{.Unsubscribe}}">Unsubscribe</a></li>{{end}}
+</ul>`))
@@ -0,0 +1,310 @@
+package notify
+
+import (
+	"html"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/subscribe/models"
+	"github.com/GoFurry/gofurry-game-backend/apps/subscribe/stub"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	cs "github.com/GoFurry/gofurry-game-backend/common/service"
+	"github.com/GoFurry/gofurry-game-backend/roof/env"
+)
+
+// fakeStore 记录基准更新, 不访问数据库
+type fakeStore struct {
+	changes  []models.PriceChangeModel
+	updated  []int64
+	notified map[int64]bool
+}
+
+func (s *fakeStore) ListPriceChanged() ([]models.PriceChangeModel, common.GFError) {
+	return s.changes, nil
+}
+
+func (s *fakeStore) UpdateBaseline(changes []models.PriceChangeModel, notified map[int64]bool) common.GFError {
+	for _, v := range changes {
+		s.updated = append(s.updated, v.ID)
+	}
+	s.notified = notified
+	return nil
+}
+
+// receivedMail 替身服务保存的一封邮件
+type receivedMail struct {
+	subject string
+	body    string
+}
+
+// startStub 启动 SMTP 替身, 返回指向它的发送函数与邮件目录
+func startStub(t *testing.T) (Sender, string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	dir := t.TempDir()
+	go stub.Serve(l, dir, io.Discard)
+
+	conf := env.MailConfig{
+		IsOn: true,
+		Host: "127.0.0.1",
+		Port: l.Addr().(*net.TCPAddr).Port,
+		From: "GoFurry <noreply@go-furry.com>",
+	}
+	return func(messages ...cs.MailMessage) (int, common.GFError) {
+		return cs.SendMailWith(conf, messages...)
+	}, dir
+}
+
+// readMails 读取目录下的邮件, 按收件人分组
+func readMails(t *testing.T, dir string) map[string][]receivedMail {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := map[string][]receivedMail{}
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := mail.ReadMessage(f)
+		if err != nil {
+			f.Close()
+			t.Fatal(err)
+		}
+		var body io.Reader = msg.Body
+		if strings.EqualFold(msg.Header.Get("Content-Transfer-Encoding"), "quoted-printable") {
+			body = quotedprintable.NewReader(body)
+		}
+		data, err := io.ReadAll(body)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		to, err := mail.ParseAddress(msg.Header.Get("To"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res[to.Address] = append(res[to.Address], receivedMail{subject: subject, body: string(data)})
+	}
+	return res
+}
+
+var hrefPattern = regexp.MustCompile(`href="([^"]+)"`)
+
+// unsubscribeLinks 提取邮件中的退订链接
+func unsubscribeLinks(t *testing.T, body string) []*url.URL {
+	t.Helper()
+	var res []*url.URL
+	for _, m := range hrefPattern.FindAllStringSubmatch(body, -1) {
+		u, err := url.Parse(html.UnescapeString(m[1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, u)
+	}
+	return res
+}
+
+func change(id int64, email string, lang string, lastFinal, final, lastDiscount, discount, threshold int64) models.PriceChangeModel {
+	return models.PriceChangeModel{
+		GfgGameSubscription: models.GfgGameSubscription{
+			ID: id, Email: email, GameID: id * 10, Lang: lang,
+			Threshold: threshold, LastFinal: lastFinal, LastDiscount: lastDiscount,
+		},
+		Name:     "游戏" + strconv.FormatInt(id, 10),
+		NameEn:   "Game " + strconv.FormatInt(id, 10),
+		Initial:  8000,
+		Final:    final,
+		Discount: discount,
+	}
+}
+
+// 已按邮箱排序, 与 ListPriceChanged 一致
+func digestChanges() []models.PriceChangeModel {
+	return []models.PriceChangeModel{
+		change(1, "a@example.com", "zh", 8000, 4000, 0, 50, 0),   // 降价
+		change(2, "a@example.com", "zh", 6000, 6000, 20, 60, 50), // 折扣达到阈值
+		change(3, "a@example.com", "zh", 4000, 4000, 60, 70, 50), // 已在阈值以上, 不重复提醒
+		change(4, "b@example.com", "en", 2000, 1000, 0, 50, 0),   // 降价
+		change(5, "b@example.com", "en", 1000, 1500, 50, 20, 0),  // 涨价
+		change(6, "c@example.com", "zh", 3000, 3500, 10, 0, 50),  // 只有涨价, 不发送
+	}
+}
+
+func sortedIDs(ids []int64) []int64 {
+	res := slices.Clone(ids)
+	slices.Sort(res)
+	return res
+}
+
+func notifiedIDs(notified map[int64]bool) []int64 {
+	var res []int64
+	for id, ok := range notified {
+		if ok {
+			res = append(res, id)
+		}
+	}
+	return sortedIDs(res)
+}
+
+func TestSendDigest(t *testing.T) {
+	send, dir := startStub(t)
+	store := &fakeStore{changes: digestChanges()}
+	signer := Signer{Secret: "test-secret", URL: "http://127.0.0.1:9998/api/subscribe/unsubscribe"}
+
+	res, err := SendDigest(store, send, signer)
+	if err != nil {
+		t.Fatal(err.GetMsg())
+	}
+	if want := (models.DigestVo{Changed: 6, Alerts: 3, Emails: 2, Sent: 2}); res != want {
+		t.Fatalf("res = %+v, want %+v", res, want)
+	}
+	if got := sortedIDs(store.updated); !slices.Equal(got, []int64{1, 2, 3, 4, 5, 6}) {
+		t.Errorf("updated = %v, want all changes", got)
+	}
+	if got := notifiedIDs(store.notified); !slices.Equal(got, []int64{1, 2, 4}) {
+		t.Errorf("notified = %v, want [1 2 4]", got)
+	}
+
+	mails := readMails(t, dir)
+	if len(mails) != 2 || len(mails["a@example.com"]) != 1 || len(mails["b@example.com"]) != 1 {
+		t.Fatalf("mails = %v, want one digest for a and b", mails)
+	}
+	zh, en := mails["a@example.com"][0], mails["b@example.com"][0]
+	if zh.subject != "GoFurry 降价提醒: 2 款游戏降价" || en.subject != "GoFurry: 1 game(s) on sale" {
+		t.Errorf("subjects = %q, %q", zh.subject, en.subject)
+	}
+	for _, want := range []string{"游戏1", "¥40.00", "(上次 ¥80.00)", "游戏2", "-60%"} {
+		if !strings.Contains(zh.body, want) {
+			t.Errorf("zh digest missing %q:\n%s", want, zh.body)
+		}
+	}
+	if strings.Contains(zh.body, "游戏3") {
+		t.Errorf("zh digest should not contain game 3:\n%s", zh.body)
+	}
+	for _, want := range []string{"Game 4", "$10.00", "(was $20.00)"} {
+		if !strings.Contains(en.body, want) {
+			t.Errorf("en digest missing %q:\n%s", want, en.body)
+		}
+	}
+	if strings.Contains(en.body, "Game 5") {
+		t.Errorf("en digest should not contain game 5:\n%s", en.body)
+	}
+
+	// 退订链接的签名可以通过校验, 篡改 ID 或签名后失败
+	records := map[string]models.GfgGameSubscription{}
+	for _, v := range store.changes {
+		records[strconv.FormatInt(v.ID, 10)] = v.GfgGameSubscription
+	}
+	links := append(unsubscribeLinks(t, zh.body), unsubscribeLinks(t, en.body)...)
+	if len(links) != 3 {
+		t.Fatalf("links = %d, want 3", len(links))
+	}
+	for _, link := range links {
+		if base := link.Scheme + "://" + link.Host + link.Path; base != signer.URL {
+			t.Errorf("link = %s, want prefix %s", link, signer.URL)
+		}
+		id, sign := link.Query().Get("id"), link.Query().Get("sign")
+		record, ok := records[id]
+		if !ok {
+			t.Fatalf("link id %q not found", id)
+		}
+		if !signer.Verify(record, sign) {
+			t.Errorf("sign for id %s does not verify", id)
+		}
+		if signer.Verify(records["6"], sign) {
+			t.Errorf("sign for id %s verifies against id 6", id)
+		}
+		tampered := []byte(sign)
+		tampered[0] ^= 1
+		if signer.Verify(record, string(tampered)) {
+			t.Errorf("tampered sign for id %s verifies", id)
+		}
+		if (Signer{Secret: "other-secret"}).Verify(record, sign) {
+			t.Errorf("sign for id %s verifies with another secret", id)
+		}
+	}
+}
+
+func TestSendDigestPartialFailure(t *testing.T) {
+	send, dir := startStub(t)
+	store := &fakeStore{changes: digestChanges()}
+	signer := Signer{Secret: "test-secret", URL: "http://127.0.0.1/unsubscribe"}
+
+	// 只有第一封发送成功
+	failing := func(messages ...cs.MailMessage) (int, common.GFError) {
+		sent, err := send(messages[:1]...)
+		if err != nil {
+			return sent, err
+		}
+		return sent, common.NewServiceError("发送邮件失败")
+	}
+	res, err := SendDigest(store, failing, signer)
+	if err == nil {
+		t.Fatal("want error")
+	}
+	if res.Emails != 2 || res.Sent != 1 {
+		t.Fatalf("res = %+v, want 1/2 sent", res)
+	}
+	// b 的降价提醒保留基准等待重试, 不需要提醒的变化照常更新
+	if got := sortedIDs(store.updated); !slices.Equal(got, []int64{1, 2, 3, 5, 6}) {
+		t.Errorf("updated = %v, want [1 2 3 5 6]", got)
+	}
+	if got := notifiedIDs(store.notified); !slices.Equal(got, []int64{1, 2}) {
+		t.Errorf("notified = %v, want [1 2]", got)
+	}
+	if mails := readMails(t, dir); len(mails) != 1 || len(mails["a@example.com"]) != 1 {
+		t.Errorf("mails = %v, want only a's digest", mails)
+	}
+}
+
+func TestSendDigestNoAlerts(t *testing.T) {
+	store := &fakeStore{changes: digestChanges()[4:]}
+	send := func(messages ...cs.MailMessage) (int, common.GFError) {
+		t.Fatalf("send called with %d messages", len(messages))
+		return 0, nil
+	}
+	res, err := SendDigest(store, send, Signer{Secret: "test-secret"})
+	if err != nil {
+		t.Fatal(err.GetMsg())
+	}
+	if res.Changed != 2 || res.Emails != 0 || !slices.Equal(sortedIDs(store.updated), []int64{5, 6}) {
+		t.Fatalf("res = %+v updated = %v", res, store.updated)
+	}
+}
+
+func TestCodeMail(t *testing.T) {
+	send, dir := startStub(t)
+	msg, err := CodeMail("a@example.com", "123456", 10)
+	if err != nil {
+		t.Fatal(err.GetMsg())
+	}
+	if sent, err := send(msg); err != nil || sent != 1 {
+		t.Fatalf("sent = %d err = %v", sent, err)
+	}
+	mails := readMails(t, dir)["a@example.com"]
+	if len(mails) != 1 || !strings.Contains(mails[0].body, "123456") || !strings.Contains(mails[0].body, "10 分钟内有效") {
+		t.Fatalf("mails = %+v", mails)
+	}
+}
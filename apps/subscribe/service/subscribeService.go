@@ -0,0 +1,188 @@
+package service
+
+import (
+	"crypto/hmac"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/subscribe/dao"
+	"github.com/GoFurry/gofurry-game-backend/apps/subscribe/models"
+	"github.com/GoFurry/gofurry-game-backend/apps/subscribe/notify"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	cm "github.com/GoFurry/gofurry-game-backend/common/models"
+	cs "github.com/GoFurry/gofurry-game-backend/common/service"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+	"github.com/GoFurry/gofurry-game-backend/roof/env"
+)
+
+type subscribeService struct{}
+
+var subscribeSingleton = new(subscribeService)
+
+func GetSubscribeService() *subscribeService { return subscribeSingleton }
+
+// Redis 定义
+const (
+	redisCodePrefix     = "subscribe:code:"      // subscribe:code:<email>
+	redisCodeLockPrefix = "subscribe:code-lock:" // 同一邮箱发送验证码的间隔
+	redisCodeIPPrefix   = "subscribe:code-ip:"   // 同一 IP 的申请次数
+	redisCodeFailPrefix = "subscribe:code-fail:" // 同一验证码的校验次数
+	codeInterval        = time.Minute
+	defaultCodeExpire   = 10 // 分钟
+)
+
+const (
+	codeIPLimit  = 10        // 同一 IP 在 codeIPWindow 内最多申请的次数, 防止轮换邮箱批量发信
+	codeIPWindow = time.Hour // 计数周期
+	codeTryLimit = 5         // 同一验证码最多校验的次数, 超过后作废需重新申请
+)
+
+// 申请订阅验证码, 同一邮箱一分钟内只能申请一次, 同一 IP 每小时最多申请 codeIPLimit 次
+func (s subscribeService) SendCode(req models.SubscribeCodeRequest, ip string) common.GFError {
+	conf := env.GetServerConfig().Mail
+	if !conf.IsOn {
+		return common.NewServiceError("邮件服务未开启")
+	}
+	count, err := cs.IncrExpire(redisCodeIPPrefix+ip, codeIPWindow)
+	if err != nil {
+		return err
+	}
+	if count > codeIPLimit {
+		return common.NewServiceError("验证码申请次数过多, 请稍后再试")
+	}
+	gameID, parseErr := util.String2Int64(req.GameID)
+	if parseErr != nil {
+		return common.NewServiceError("Game ID 转换有误")
+	}
+	exists, err := dao.GetSubscribeDao().ExistsGame(gameID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return common.NewServiceError("游戏不存在")
+	}
+	if !cs.SetNX(redisCodeLockPrefix+req.Email, 1, codeInterval) {
+		return common.NewServiceError("验证码发送过于频繁, 请稍后再试")
+	}
+
+	expire := codeExpire()
+	code := util.GenerateRandomCode(common.EMAIL_CODE_LENGTH)
+	if err := cs.SetExpire(redisCodePrefix+req.Email, code, time.Duration(expire)*time.Minute); err != nil {
+		return err
+	}
+	cs.Del(redisCodeFailPrefix + req.Email)
+	msg, err := notify.CodeMail(req.Email, code, expire)
+	if err != nil {
+		return err
+	}
+	if _, err := cs.SendMail(msg); err != nil {
+		cs.Del(redisCodeLockPrefix + req.Email)
+		return err
+	}
+	return nil
+}
+
+// codeExpire 验证码有效期, 单位分钟
+func codeExpire() int {
+	if expire := env.GetServerConfig().Mail.CodeExpire; expire > 0 {
+		return expire
+	}
+	return defaultCodeExpire
+}
+
+// 校验验证码后订阅游戏, 已订阅时更新设置, 当前价格作为之后比较的基准
+func (s subscribeService) Subscribe(req models.SubscribeRequest) (res models.SubscribeVo, err common.GFError) {
+	gameID, parseErr := util.String2Int64(req.GameID)
+	if parseErr != nil {
+		return res, common.NewServiceError("Game ID 转换有误")
+	}
+	if len(req.Code) != common.EMAIL_CODE_LENGTH {
+		return res, common.NewServiceError("验证码有误")
+	}
+	code, err := cs.GetString(redisCodePrefix + req.Email)
+	if err != nil {
+		return res, common.NewServiceError("验证码有误或已过期")
+	}
+	// 先计数再比较, 并发猜测也不会超过 codeTryLimit 次
+	tries, err := cs.IncrExpire(redisCodeFailPrefix+req.Email, time.Duration(codeExpire())*time.Minute)
+	if err != nil {
+		return res, err
+	}
+	if tries > codeTryLimit {
+		cs.Del(redisCodePrefix+req.Email, redisCodeFailPrefix+req.Email)
+		return res, common.NewServiceError("验证码错误次数过多, 请重新申请")
+	}
+	if !hmac.Equal([]byte(code), []byte(req.Code)) {
+		return res, common.NewServiceError("验证码有误或已过期")
+	}
+
+	lang := req.Lang
+	if lang == "" {
+		lang = "zh"
+	}
+	threshold := req.Threshold
+	if threshold == 0 {
+		threshold = env.GetServerConfig().Mail.DiscountThreshold
+	}
+	price, err := dao.GetSubscribeDao().GetGamePrice(gameID, lang)
+	if err != nil {
+		return res, err
+	}
+
+	now := cm.LocalTime(time.Now())
+	record, found, err := dao.GetSubscribeDao().GetSubscription("email = ? AND game_id = ?", req.Email, gameID)
+	if err != nil {
+		return res, err
+	}
+	record.Lang = lang
+	record.Threshold = threshold
+	record.LastFinal = price.Final
+	record.LastDiscount = price.Discount
+	record.UpdateTime = now
+	if found {
+		err = dao.GetSubscribeDao().SaveSubscription(record)
+	} else {
+		record.ID = util.GenerateId()
+		record.Email = req.Email
+		record.GameID = gameID
+		record.CreateTime = now
+		err = dao.GetSubscribeDao().Add(&record)
+	}
+	if err != nil {
+		return res, err
+	}
+	cs.Del(redisCodePrefix+req.Email, redisCodeFailPrefix+req.Email)
+
+	res.ID = util.Int642String(record.ID)
+	res.GameID = req.GameID
+	res.Lang = lang
+	res.Threshold = threshold
+	return res, nil
+}
+
+// 校验签名后退订
+func (s subscribeService) Unsubscribe(id string, sign string) common.GFError {
+	intID, parseErr := util.String2Int64(id)
+	if parseErr != nil {
+		return common.NewServiceError("订阅 ID 转换有误")
+	}
+	record, found, err := dao.GetSubscribeDao().GetSubscription("id = ?", intID)
+	if err != nil {
+		return err
+	}
+	// 不存在与签名错误返回相同提示, 避免探测订阅
+	if !found || !mailSigner().Verify(record, sign) {
+		return common.NewServiceError("退订链接无效或已退订")
+	}
+	return dao.GetSubscribeDao().Unsubscribe(intID)
+}
+
+// 发送降价提醒, 同一邮箱的提醒合并为一封
+func (s subscribeService) SendPriceDigest() (models.DigestVo, common.GFError) {
+	return notify.SendDigest(dao.GetSubscribeDao(), cs.SendMail, mailSigner())
+}
+
+// mailSigner 按当前配置创建退订签名
+func mailSigner() notify.Signer {
+	conf := env.GetServerConfig().Mail
+	return notify.Signer{Secret: conf.SignSecret, URL: conf.UnsubscribeURL}
+}
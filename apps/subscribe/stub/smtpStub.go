@@ -0,0 +1,120 @@
+package stub
+
+/*
+ * @Desc: SMTP 替身, 接收邮件并保存为 .eml 文件, 用于本地联调降价提醒
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// 只实现发送邮件所需的最小命令集, 不支持 STARTTLS 与 AUTH
+// mail.username 需留空, mail.ssl 需关闭
+var seq atomic.Int64
+
+// Serve 在 l 上接收邮件, 每封邮件写入 dir/<时间>-<序号>.eml, 并向 out 输出摘要
+func Serve(l net.Listener, dir string, out io.Writer) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go handle(conn, dir, out)
+	}
+}
+
+// handle 处理单个 SMTP 会话
+func handle(conn net.Conn, dir string, out io.Writer) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	conn.SetDeadline(time.Now().Add(5 * time.Minute))
+
+	reply := func(code int, msg string) bool {
+		return tp.PrintfLine("%d %s", code, msg) == nil
+	}
+	if !reply(220, "gofurry smtp-stub ready") {
+		return
+	}
+
+	var from string
+	var to []string
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			if tp.PrintfLine("250-smtp-stub") != nil || !reply(250, "8BITMIME") {
+				return
+			}
+		case "HELO", "NOOP":
+			reply(250, "OK")
+		case "RSET":
+			from, to = "", nil
+			reply(250, "OK")
+		case "MAIL":
+			from = address(arg)
+			to = nil
+			reply(250, "OK")
+		case "RCPT":
+			if from == "" {
+				reply(503, "MAIL first")
+				continue
+			}
+			to = append(to, address(arg))
+			reply(250, "OK")
+		case "DATA":
+			if len(to) == 0 {
+				reply(503, "RCPT first")
+				continue
+			}
+			if !reply(354, "end data with <CR><LF>.<CR><LF>") {
+				return
+			}
+			data, readErr := io.ReadAll(tp.DotReader())
+			if readErr != nil {
+				return
+			}
+			name, saveErr := save(dir, data)
+			if saveErr != nil {
+				reply(451, "save failed")
+				continue
+			}
+			fmt.Fprintf(out, "%s %s -> %s %s\n", time.Now().Format(time.DateTime), from, strings.Join(to, ","), name)
+			from, to = "", nil
+			reply(250, "OK")
+		case "QUIT":
+			reply(221, "bye")
+			return
+		default:
+			reply(502, "command not implemented")
+		}
+	}
+}
+
+// address 提取 FROM:<a@b> / TO:<a@b> 中的地址, 忽略 BODY=8BITMIME 等参数
+func address(arg string) string {
+	_, addr, _ := strings.Cut(arg, ":")
+	addr, _, _ = strings.Cut(strings.TrimSpace(addr), " ")
+	return strings.Trim(addr, "<>")
+}
+
+func save(dir string, data []byte) (string, error) {
+	name := fmt.Sprintf("%s-%04d.eml", time.Now().Format("20060102150405"), seq.Add(1))
+	return name, os.WriteFile(filepath.Join(dir, name), data, 0o644)
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"github.com/GoFurry/gofurry-game-backend/apps/system/service"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/gofiber/fiber/v2"
+)
+
+type systemApi struct{}
+
+var SystemApi *systemApi
+
+func init() {
+	SystemApi = &systemApi{}
+}
+
+// @Summary 存活检测
+// @Schemes
+// @Description 进程存活即返回 200
+// @Tags System
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.LivenessVo
+// @Router /healthz [Get]
+func (api *systemApi) Liveness(c *fiber.Ctx) error {
+	return common.NewResponse(c).SuccessWithData(service.GetSystemService().Liveness())
+}
+
+// @Summary 热重载配置
+// @Schemes
+// @Description 重新读取配置并重建跨域、限流、WAF 规则与 GeoIP 数据库, 仅管理员可用
+// @Tags System
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.ReloadVo
+// @Router /api/admin/system/reload [Post]
+func (api *systemApi) Reload(c *fiber.Ctx) error {
+	data, err := service.GetSystemService().Reload()
+	if err != nil {
+		return common.NewResponse(c).Error(err.GetMsg())
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
+
+// @Summary 就绪检测
+// @Schemes
+// @Description 检测 Postgres、Redis、MongoDB 连接及 WAF、GeoIP 加载状态, 必需依赖不可用时返回 503
+// @Tags System
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.ReadinessVo
+// @Failure 503 {object} models.ReadinessVo
+// @Router /readyz [Get]
+func (api *systemApi) Readiness(c *fiber.Ctx) error {
+	data := service.GetSystemService().Readiness()
+	if !data.Ready {
+		return common.NewResponse(c).ErrorWithCode(data, fiber.StatusServiceUnavailable)
+	}
+
+	return common.NewResponse(c).SuccessWithData(data)
+}
@@ -0,0 +1,47 @@
+package models
+
+/*
+ * @Desc: 系统状态
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import cm "github.com/GoFurry/gofurry-game-backend/common/models"
+
+// 依赖状态
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDisabled = "disabled" // 未启用或未初始化
+	StatusOK       = "ok"
+)
+
+type LivenessVo struct {
+	Status    string       `json:"status"`
+	Uptime    int64        `json:"uptime"` // 运行时长(秒)
+	StartTime cm.LocalTime `json:"start_time"`
+}
+
+type DependencyVo struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Required bool   `json:"required"`   // 不可用时是否影响就绪状态
+	Latency  int64  `json:"latency_ms"` // 检测耗时(毫秒)
+	Error    string `json:"error,omitempty"`
+}
+
+// 热重载各步骤结果
+type ReloadVo struct {
+	Config  string `json:"config"`
+	Cors    string `json:"cors"`
+	Limiter string `json:"limiter"`
+	WAF     string `json:"waf"`
+	GeoIP   string `json:"geoip"`
+}
+
+type ReadinessVo struct {
+	Ready        bool            `json:"ready"`
+	Dependencies []DependencyVo  `json:"dependencies"`
+	WAF          string          `json:"waf"`
+	GeoIP        map[string]bool `json:"geoip"`
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/apps/system/models"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	cm "github.com/GoFurry/gofurry-game-backend/common/models"
+	cs "github.com/GoFurry/gofurry-game-backend/common/service"
+	"github.com/GoFurry/gofurry-game-backend/middleware"
+	"github.com/GoFurry/gofurry-game-backend/roof/db"
+	"github.com/GoFurry/gofurry-game-backend/roof/env"
+	"golang.org/x/sync/errgroup"
+)
+
+type systemService struct{}
+
+var systemSingleton = new(systemService)
+
+func GetSystemService() *systemService { return systemSingleton }
+
+// 单个依赖检测的超时时间
+const checkTimeout = 2 * time.Second
+
+var startTime = time.Now()
+
+// 防止 SIGHUP 与接口同时触发重载
+var reloadMu sync.Mutex
+
+// 存活检测 进程能响应即视为存活
+func (s systemService) Liveness() models.LivenessVo {
+	return models.LivenessVo{
+		Status:    models.StatusUp,
+		Uptime:    int64(time.Since(startTime).Seconds()),
+		StartTime: cm.LocalTime(startTime),
+	}
+}
+
+// 就绪检测 并发检测各依赖, 必需依赖不可用时未就绪
+func (s systemService) Readiness() models.ReadinessVo {
+	checks := []struct {
+		name     string
+		required bool
+		enabled  bool
+		ping     func(ctx context.Context) error
+	}{
+		{"postgres", true, true, db.Orm.Ping},
+		{"redis", true, cs.GetRedisService() != nil, func(ctx context.Context) error {
+			return cs.GetRedisService().Ping(ctx).Err()
+		}},
+		// MongoDB 仅在已建立连接时检测, 避免探针触发连接
+		{"mongodb", false, cs.Mongo.Initialized(), cs.Mongo.Ping},
+	}
+
+	res := models.ReadinessVo{Ready: true, Dependencies: make([]models.DependencyVo, len(checks))}
+	var g errgroup.Group
+	for i, check := range checks {
+		g.Go(func() error {
+			dep := models.DependencyVo{Name: check.name, Required: check.required, Status: models.StatusDisabled}
+			if check.enabled {
+				ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+				defer cancel()
+				begin := time.Now()
+				err := check.ping(ctx)
+				dep.Latency = time.Since(begin).Milliseconds()
+				dep.Status = models.StatusUp
+				if err != nil {
+					dep.Status = models.StatusDown
+					dep.Error = err.Error()
+				}
+			} else if check.required {
+				dep.Status = models.StatusDown
+				dep.Error = "未初始化"
+			}
+			res.Dependencies[i] = dep
+			return nil
+		})
+	}
+	g.Wait()
+
+	for _, dep := range res.Dependencies {
+		if dep.Required && dep.Status != models.StatusUp {
+			res.Ready = false
+		}
+	}
+
+	res.WAF = models.StatusDisabled
+	if env.GetServerConfig().Waf.WafSwitch {
+		res.WAF = models.StatusDown
+		if middleware.WAFLoaded() {
+			res.WAF = models.StatusUp
+		}
+	}
+	res.GeoIP = middleware.GeoIPStatus()
+	return res
+}
+
+// 热重载 重新读取配置并重建跨域、限流、WAF 与 GeoIP
+// 配置校验失败时不做任何替换; WAF 规则加载失败时保留旧规则
+func (s systemService) Reload() (res models.ReloadVo, err common.GFError) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	log.Info("开始热重载配置...")
+
+	cfg, cfgErr := env.ReloadServerConfig()
+	if cfgErr != nil {
+		log.Error("热重载配置失败, 保留原配置: ", cfgErr)
+		return res, common.NewServiceError("配置校验失败, 已保留原配置: " + cfgErr.Error())
+	}
+	res.Config = models.StatusOK
+
+	middleware.ReloadCors()
+	res.Cors = models.StatusOK
+	middleware.ReloadLimiter()
+	res.Limiter = models.StatusOK
+
+	res.WAF = models.StatusDisabled
+	if cfg.Waf.WafSwitch {
+		res.WAF = models.StatusOK
+		if wafErr := middleware.InitGlobalWAF(cfg.Waf.ConfPath); wafErr != nil {
+			res.WAF = "WAF 规则加载失败, 已保留旧规则: " + wafErr.Error()
+		}
+	}
+
+	res.GeoIP = models.StatusOK
+	if geoErr := middleware.ReloadGeoIP(); geoErr != nil {
+		log.Error("热重载 GeoIP 失败: ", geoErr)
+		res.GeoIP = "部分 GeoIP 数据库加载失败, 已保留旧数据库: " + geoErr.Error()
+	}
+
+	log.Info("热重载完成: ", res)
+	return res, nil
+}
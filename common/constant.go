@@ -15,9 +15,19 @@ const (
 GF-Game is a backend service for GoFurry Game Site.
 Usage:
   ./gf-game [params]
+    - (no params): run the server, requires auth.jwt_secret, e.g. export GF_GAME_AUTH_JWT_SECRET=$(openssl rand -base64 48).
     - install: install this backend to systemd.
     - uninstall: uninstall this backend from systemd.
     - version: show this backend version.
+    - migrate up: apply all pending database migrations.
+    - migrate down [n]: revert the last n applied migrations, default 1. 0001_init_schema is irreversible.
+    - migrate status: show migration status and detect schema drift.
+    - collect [info|players]: collect Steam app details, news and player counts once, default both.
+    - steam-stub [--dir <dir>] [--addr <addr>]: serve recorded Steam responses for local collection.
+    - digest: send price drop alerts to subscribers once, requires mail.is_on.
+    - smtp-stub [--dir <dir>] [--addr <addr>]: accept mail locally and save it as .eml files, default ./data/mail.
+    - seed [--fixtures <dir>] [--force]: migrate and load fixtures into Postgres and Redis, default ./conf/fixtures.
+      Only runs when server.mode is debug unless --force is given; existing admin users are never overwritten.
     - help: show this help message.
 `
 )
@@ -40,7 +50,13 @@ const (
 
 // JWT
 const (
-	TOKEN_SECRET = "GolangNotFurryTho" // JWT密钥
+	TOKEN_RENEW_HEADER = "X-Renew-Token" // 续租后下发新 Token 的响应头
+)
+
+// 角色
+const (
+	ROLE_ADMIN  = "admin"  // 管理员
+	ROLE_EDITOR = "editor" // 编辑
 )
 
 // 常量
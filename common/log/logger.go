@@ -19,10 +19,10 @@ import (
  * @version: v1.0.1
  */
 
-// 全局日志实例
+// 全局日志实例, InitLogger 之前为空实现, 只引用工具函数的包(如单元测试)无需初始化
 var (
-	GlobalLogger *zap.Logger
-	SugarLogger  *zap.SugaredLogger
+	GlobalLogger = zap.NewNop()
+	SugarLogger  = GlobalLogger.Sugar()
 )
 
 // Config 日志配置结构体
@@ -12,4 +12,5 @@ type GFClaims struct {
 	jwt.RegisteredClaims
 	UserName string `json:"userName"`
 	UserId   string `json:"userId"`
+	Role     string `json:"role"`
 }
@@ -30,8 +30,10 @@ type EventBus struct {
 	rwm         sync.RWMutex
 }
 
-// 发送线程池
-var threadPool = pool.New().WithMaxGoroutines(env.GetServerConfig().Thread.EventPublishThread)
+// 发送线程池, 首次发布时按配置创建
+var threadPool = sync.OnceValue(func() *pool.Pool {
+	return pool.New().WithMaxGoroutines(env.GetServerConfig().Thread.EventPublishThread)
+})
 
 func (eb *EventBus) PublishGlobalMsg(data any) {
 	eb.Publish(common.GLOBAL_MSG, data)
@@ -51,7 +53,7 @@ func (eb *EventBus) Publish(topic string, data any) {
 	defer eb.rwm.Unlock()
 	if chs, found := eb.Subscribers[topic]; found {
 		channels := append(DataChannelSlice{}, chs...)
-		threadPool.Go(func() {
+		threadPool().Go(func() {
 			func(data EventData, dataChannelSlices DataChannelSlice) {
 				defer func() {
 					if e := recover(); e != nil {
@@ -0,0 +1,60 @@
+package service
+
+/*
+ * @Desc: 邮件服务
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import (
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	"github.com/GoFurry/gofurry-game-backend/roof/env"
+	"gopkg.in/gomail.v2"
+)
+
+// MailMessage 一封待发送的 HTML 邮件
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// SendMail 按当前配置发送邮件
+func SendMail(messages ...MailMessage) (sent int, err common.GFError) {
+	return SendMailWith(env.GetServerConfig().Mail, messages...)
+}
+
+// SendMailWith 按指定配置发送邮件, 多封邮件复用同一个 SMTP 连接
+// 返回成功发送的数量, 任一封失败时返回错误并停止发送
+func SendMailWith(conf env.MailConfig, messages ...MailMessage) (sent int, err common.GFError) {
+	if !conf.IsOn {
+		return 0, common.NewServiceError("邮件服务未开启")
+	}
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	dialer := gomail.NewDialer(conf.Host, conf.Port, conf.Username, conf.Password)
+	dialer.SSL = conf.SSL
+	sender, dialErr := dialer.Dial()
+	if dialErr != nil {
+		log.Error("SendMail dial err: ", dialErr)
+		return 0, common.NewServiceError("连接邮件服务器失败")
+	}
+	defer sender.Close()
+
+	for _, v := range messages {
+		msg := gomail.NewMessage()
+		msg.SetHeader("From", conf.From)
+		msg.SetHeader("To", v.To)
+		msg.SetHeader("Subject", v.Subject)
+		msg.SetBody("text/html", v.Body)
+		if sendErr := gomail.Send(sender, msg); sendErr != nil {
+			log.Error("SendMail send to ", v.To, " err: ", sendErr)
+			return sent, common.NewServiceError("发送邮件失败")
+		}
+		sent++
+	}
+	return sent, nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -114,6 +115,19 @@ func (m *mongoDB) Collection(collName string, dbName ...string) *mongo.Collectio
 	return m.DB(dbName...).Collection(collName)
 }
 
+// Ping 检测连接, 未初始化时不会主动建立连接
+func (m *mongoDB) Ping(ctx context.Context) error {
+	if m.client == nil {
+		return errors.New("mongodb 未初始化")
+	}
+	return m.client.Ping(ctx, nil)
+}
+
+// Initialized 是否已建立连接
+func (m *mongoDB) Initialized() bool {
+	return m.client != nil
+}
+
 // Close 关闭MongoDB连接
 func (m *mongoDB) Close() error {
 	if m.client == nil {
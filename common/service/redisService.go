@@ -45,6 +45,14 @@ func connect() {
 
 }
 
+// 关闭 redis 连接
+func CloseRedis() error {
+	if client == nil {
+		return nil
+	}
+	return client.Close()
+}
+
 func OnConnectFunc(ctx context.Context, cn *redis.Conn) error {
 	log.Debug("new redis connect...")
 	return nil
@@ -154,6 +162,15 @@ func HGetAll(key string) (data map[string]string, gfsError common.GFError) {
 	return res, nil
 }
 
+func HKeys(key string) (data []string, gfsError common.GFError) {
+	res, err := client.HKeys(ctx, key).Result()
+	if err != nil {
+		log.Error("获取缓存失败..." + err.Error())
+		return nil, common.NewServiceError("获取缓存失败.")
+	}
+	return res, nil
+}
+
 func HDel(key string, fields ...string) (res int64, gfsError common.GFError) {
 	intVal, err := client.HDel(ctx, key, fields...).Result()
 	switch {
@@ -170,6 +187,24 @@ func Incr(key string) {
 	client.Incr(ctx, key)
 }
 
+// 自增并设置过期时间的脚本, 两步在 Redis 内原子执行, 不会留下没有过期时间的计数
+var incrExpireScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+if n == 1 or redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return n`)
+
+// 自增并在首次创建时设置过期时间, 返回自增后的值
+func IncrExpire(key string, expiration time.Duration) (res int64, gfsError common.GFError) {
+	res, err := incrExpireScript.Run(ctx, client, []string{key}, expiration.Milliseconds()).Int64()
+	if err != nil {
+		log.Error("自增缓存失败..." + err.Error())
+		return 0, common.NewServiceError("自增缓存失败.")
+	}
+	return res, nil
+}
+
 // redis 前缀统计
 func CountByPrefix(prefix string) (res int64, gfsError common.GFError) {
 	var cursor uint64 = 0
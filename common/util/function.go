@@ -8,17 +8,19 @@ package util
 
 import (
 	"crypto/md5"
+	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/pem"
 	"fmt"
-	"math/rand"
+	"math/big"
 	"net"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/GoFurry/gofurry-game-backend/common"
@@ -27,13 +29,18 @@ import (
 	"github.com/bwmarrin/snowflake"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
 )
 
-var clusterId, _ = snowflake.NewNode(int64(env.GetServerConfig().ClusterId))
+// 首次生成 ID 时按配置的集群 ID 创建节点
+var clusterId = sync.OnceValue(func() *snowflake.Node {
+	node, _ := snowflake.NewNode(int64(env.GetServerConfig().ClusterId))
+	return node
+})
 
 // 雪花算法生成新 ID
 func GenerateId() int64 {
-	id := clusterId.Generate()
+	id := clusterId().Generate()
 	return id.Int64()
 }
 
@@ -44,6 +51,20 @@ func CreateMD5(str string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// 密码哈希 使用 bcrypt, 每个哈希自带随机盐
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// 校验 bcrypt 密码哈希
+func CheckPassword(password string, hash string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
 // 判断是否为数字
 func IsNumber(str string) bool {
 	_, err := strconv.Atoi(str)
@@ -144,21 +165,33 @@ func In[T comparable](target T, aimList []T) bool {
 	return false
 }
 
-// 生成随机验证码
+// 生成随机验证码, 使用 crypto/rand 保证不可预测
 func GenerateRandomCode(length int) string {
-	//rand.Seed(time.Now().UnixNano())
 	letters := "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	randCode := ""
-	for i := 1; i <= length; i++ {
-		randCode += string(letters[rand.Intn(len(letters))])
+	max := big.NewInt(int64(len(letters)))
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		code[i] = letters[n.Int64()]
 	}
-	return randCode
+	return string(code)
+}
+
+// JWT 密钥 使用配置文件中的 auth.jwt_secret, 加载配置时已校验长度与强度
+func jwtSecret() []byte {
+	return []byte(env.GetServerConfig().Auth.JwtSecret)
 }
 
-// JWT 密钥
 func Secret() jwt.Keyfunc {
 	return func(token *jwt.Token) (interface{}, error) {
-		return []byte(common.TOKEN_SECRET), nil
+		// 只接受 HMAC 签名, 防止算法替换
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method: " + token.Method.Alg())
+		}
+		return jwtSecret(), nil
 	}
 }
 
@@ -168,6 +201,9 @@ func ParseToken(authorization string) (*cm.GFClaims, error) {
 	if err != nil {
 		fmt.Println(err)
 	}
+	if token == nil {
+		return nil, err
+	}
 	if claims, ok := token.Claims.(*cm.GFClaims); ok && token.Valid {
 		return claims, nil
 	}
@@ -183,10 +219,11 @@ nbf (Not Before): 生效时间
 iat (Issued At): 签发时间
 jti (JWT ID): 编号
 */
-func NewToken(userId string, userName string) (string, error) {
+func NewToken(userId string, userName string, role string) (string, error) {
 	claims := cm.GFClaims{
 		UserId:   userId,
 		UserName: userName,
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(common.JWT_RELET_NUM * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -194,7 +231,7 @@ func NewToken(userId string, userName string) (string, error) {
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(common.TOKEN_SECRET))
+	return token.SignedString(jwtSecret())
 }
 
 // 判断是否 IP
@@ -0,0 +1,220 @@
+package util
+
+/*
+ * @Desc: HTML 白名单过滤
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// 允许的标签及其属性
+var allowedTags = map[string][]string{
+	"p": {}, "br": {}, "hr": {}, "div": {"style"}, "span": {"style"},
+	"h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {},
+	"strong": {}, "b": {}, "em": {}, "i": {}, "u": {}, "s": {}, "strike": {}, "del": {}, "sub": {}, "sup": {},
+	"blockquote": {}, "pre": {}, "code": {},
+	"ul": {}, "ol": {}, "li": {},
+	"table": {}, "thead": {}, "tbody": {}, "tr": {}, "th": {"colspan", "rowspan"}, "td": {"colspan", "rowspan"},
+	"a":      {"href", "title", "target"},
+	"img":    {"src", "alt", "title", "width", "height"},
+	"video":  {"src", "poster", "controls", "width", "height"},
+	"source": {"src", "type"},
+	"iframe": {"src", "width", "height", "allowfullscreen", "frameborder"},
+}
+
+// 连同内容一起丢弃的标签
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "object": true, "embed": true, "noscript": true,
+	"template": true, "svg": true, "math": true, "form": true, "textarea": true, "select": true,
+}
+
+// 自闭合标签
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true, "source": true}
+
+// 允许的 URL 协议
+var allowedSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// 允许嵌入的 iframe 来源
+var allowedIframePrefixes = []string{
+	"https://www.youtube.com/embed/",
+	"https://www.youtube-nocookie.com/embed/",
+	"https://player.bilibili.com/",
+}
+
+// 允许的内联样式属性
+var allowedStyles = map[string]bool{"color": true, "font-size": true, "font-family": true, "text-align": true}
+
+var styleValuePattern = regexp.MustCompile(`^[#a-zA-Z0-9 ,.%\-'"]+$`)
+
+// SanitizeHTML 按白名单过滤 HTML, 移除脚本、事件属性和危险链接
+func SanitizeHTML(input string) string {
+	var sb strings.Builder
+	var stack []string // 已输出但未闭合的标签
+	skipTag := ""      // 正在丢弃的标签
+	skipDepth := 0     // 同名标签嵌套深度
+
+	tokenizer := html.NewTokenizer(strings.NewReader(input))
+	for {
+		tt := tokenizer.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+		name := strings.ToLower(token.Data)
+
+		// 丢弃整个子树
+		if skipDepth > 0 {
+			if name == skipTag {
+				switch tt {
+				case html.StartTagToken:
+					skipDepth++
+				case html.EndTagToken:
+					skipDepth--
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			// 危险标签与不可信的嵌入连同内容一起丢弃
+			if droppedTags[name] || (name == "iframe" && !isAllowedIframe(token.Attr)) {
+				if tt == html.StartTagToken {
+					skipTag, skipDepth = name, 1
+				}
+				continue
+			}
+			attrs, ok := allowedTags[name]
+			if !ok {
+				continue
+			}
+			sb.WriteString("<" + name)
+			writeAttrs(&sb, name, token.Attr, attrs)
+			if voidTags[name] || tt == html.SelfClosingTagToken {
+				sb.WriteString(" />")
+				continue
+			}
+			sb.WriteString(">")
+			stack = append(stack, name)
+		case html.EndTagToken:
+			if voidTags[name] {
+				continue
+			}
+			// 只闭合已打开的标签, 中间未闭合的一并补齐
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i] == name {
+					for j := len(stack) - 1; j >= i; j-- {
+						sb.WriteString("</" + stack[j] + ">")
+					}
+					stack = stack[:i]
+					break
+				}
+			}
+		case html.TextToken:
+			sb.WriteString(html.EscapeString(token.Data))
+		}
+		// 注释、Doctype 直接丢弃
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		sb.WriteString("</" + stack[i] + ">")
+	}
+	return sb.String()
+}
+
+// writeAttrs 输出白名单内的属性
+func writeAttrs(sb *strings.Builder, tag string, attrs []html.Attribute, allowed []string) {
+	hasTarget := false
+	for _, attr := range attrs {
+		key := strings.ToLower(attr.Key)
+		if !In(key, allowed) {
+			continue
+		}
+		val := attr.Val
+		switch key {
+		case "href", "src", "poster":
+			if !isSafeURL(val) {
+				continue
+			}
+		case "style":
+			val = sanitizeStyle(val)
+			if val == "" {
+				continue
+			}
+		case "target":
+			if val != "_blank" {
+				continue
+			}
+			hasTarget = true
+		}
+		sb.WriteString(" " + key + `="` + html.EscapeString(val) + `"`)
+	}
+	if tag == "a" {
+		if hasTarget {
+			sb.WriteString(` rel="nofollow noopener noreferrer"`)
+		} else {
+			sb.WriteString(` rel="nofollow"`)
+		}
+	}
+}
+
+// isSafeURL 只允许 http/https/mailto 和相对路径
+func isSafeURL(raw string) bool {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" {
+		// 相对路径不允许在首个 / 之前出现冒号
+		if idx := strings.Index(raw, ":"); idx != -1 {
+			slash := strings.Index(raw, "/")
+			return slash != -1 && slash < idx
+		}
+		return true
+	}
+	return allowedSchemes[strings.ToLower(u.Scheme)]
+}
+
+// isAllowedIframe 判断 iframe 来源是否可信
+func isAllowedIframe(attrs []html.Attribute) bool {
+	for _, attr := range attrs {
+		if strings.ToLower(attr.Key) != "src" {
+			continue
+		}
+		for _, prefix := range allowedIframePrefixes {
+			if strings.HasPrefix(attr.Val, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sanitizeStyle 只保留白名单内的样式声明
+func sanitizeStyle(style string) string {
+	var kept []string
+	for _, decl := range strings.Split(style, ";") {
+		parts := strings.SplitN(decl, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		prop := strings.ToLower(strings.TrimSpace(parts[0]))
+		val := strings.TrimSpace(parts[1])
+		if !allowedStyles[prop] || !styleValuePattern.MatchString(val) {
+			continue
+		}
+		kept = append(kept, prop+":"+val)
+	}
+	return strings.Join(kept, ";")
+}
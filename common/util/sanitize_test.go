@@ -0,0 +1,62 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		// 链接协议
+		{"javascript 链接", `<a href="javascript:alert(1)">x</a>`, `<a rel="nofollow">x</a>`},
+		{"大小写与空白混淆", `<a href=" JaVaScRiPt:alert(1)">x</a>`, `<a rel="nofollow">x</a>`},
+		{"实体编码混淆", `<a href="java&#x09;script:alert(1)">x</a>`, `<a rel="nofollow">x</a>`},
+		{"data 链接", `<a href="data:text/html;base64,PHNjcmlwdD4=">x</a>`, `<a rel="nofollow">x</a>`},
+		{"未知协议", `<a href="foo:bar/baz">x</a>`, `<a rel="nofollow">x</a>`},
+		{"外部链接新窗口", `<a href="https://example.com" target="_blank">x</a>`, `<a href="https://example.com" target="_blank" rel="nofollow noopener noreferrer">x</a>`},
+		{"相对路径", `<a href="/game/1">x</a>`, `<a href="/game/1" rel="nofollow">x</a>`},
+
+		// 事件属性与样式
+		{"img onerror", `<img src="x" onerror="alert(1)">`, `<img src="x" />`},
+		{"事件属性", `<p onclick="alert(1)" onmouseover=alert(2)>hi</p>`, `<p>hi</p>`},
+		{"iframe onload", `<iframe src="https://www.youtube.com/embed/abc" onload="x"></iframe>`, `<iframe src="https://www.youtube.com/embed/abc"></iframe>`},
+		{"危险样式", `<div style="color:red;background:url(javascript:alert(1))">x</div>`, `<div style="color:red">x</div>`},
+		{"不在白名单的属性", `<p title="x"><span>"quoted" & <b>'x'</b></span></p>`, `<p><span>&#34;quoted&#34; &amp; <b>&#39;x&#39;</b></span></p>`},
+
+		// 连同内容丢弃的标签
+		{"svg 子树", `<svg><script>alert(1)</script><a href="https://a">x</a></svg>ok`, `ok`},
+		{"未闭合的 svg", `<svg onload=alert(1)/>after`, ``},
+		{"style 标签", `<style>body{background:url(javascript:alert(1))}</style>text`, `text`},
+		{"script 标签", `<script>alert(1)</script>safe`, `safe`},
+		{"math 子树", `<math><mi xlink:href="javascript:alert(1)">x</mi></math>y`, `y`},
+		{"不可信的 iframe", `<iframe src="https://evil.com/x"><p>hidden</p></iframe>shown`, `shown`},
+		{"注释", `<!-- <script>alert(1)</script> -->ok`, `ok`},
+
+		// 嵌套与畸形标签
+		{"嵌套 script", `<div><script><script>alert(1)</script></script>x</div>`, `<div>x</div>`},
+		{"拆分的 script", `<scr<script>ipt>alert(1)</script>`, `ipt&gt;alert(1)`},
+		{"双尖括号", `<<script>script>alert(1)<</script>/script>`, `&lt;/script&gt;`},
+		{"交错闭合", `<p><b>bold<i>both</p>tail`, `<p><b>bold<i>both</i></b></p>tail`},
+		{"未闭合标签", `<b>unclosed`, `<b>unclosed</b>`},
+		{"多余的闭合标签", `</div>stray</p>`, `stray`},
+		{"自闭合标签", `<img src="x"/><br></br>`, `<img src="x" /><br />`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := SanitizeHTML(c.input)
+			if got != c.want {
+				t.Fatalf("SanitizeHTML(%q) = %q, want %q", c.input, got, c.want)
+			}
+			lower := strings.ToLower(got)
+			for _, bad := range []string{"<script", "<svg", "<style", "javascript:", "onerror", "onclick", "onload"} {
+				if strings.Contains(lower, bad) {
+					t.Fatalf("SanitizeHTML(%q) = %q, contains %q", c.input, got, bad)
+				}
+			}
+		})
+	}
+}
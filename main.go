@@ -1,20 +1,39 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"runtime/debug"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
+	collectorModels "github.com/GoFurry/gofurry-game-backend/apps/collector/models"
+	collector "github.com/GoFurry/gofurry-game-backend/apps/collector/service"
+	"github.com/GoFurry/gofurry-game-backend/apps/collector/stub"
 	"github.com/GoFurry/gofurry-game-backend/apps/schedule"
+	"github.com/GoFurry/gofurry-game-backend/apps/schedule/task"
+	"github.com/GoFurry/gofurry-game-backend/apps/seed"
+	subscribe "github.com/GoFurry/gofurry-game-backend/apps/subscribe/service"
+	smtpStub "github.com/GoFurry/gofurry-game-backend/apps/subscribe/stub"
+	system "github.com/GoFurry/gofurry-game-backend/apps/system/service"
 	"github.com/GoFurry/gofurry-game-backend/common"
 	gfLog "github.com/GoFurry/gofurry-game-backend/common/log"
 	cs "github.com/GoFurry/gofurry-game-backend/common/service"
 	"github.com/GoFurry/gofurry-game-backend/middleware"
+	"github.com/GoFurry/gofurry-game-backend/roof/db"
 	"github.com/GoFurry/gofurry-game-backend/roof/env"
+	"github.com/GoFurry/gofurry-game-backend/roof/migrate"
 	"github.com/GoFurry/gofurry-game-backend/routers"
+	"github.com/gofiber/fiber/v2"
 	"github.com/kardianos/service"
 )
 
@@ -26,6 +45,9 @@ var (
 	errChan = make(chan error)
 )
 
+// 默认优雅关闭超时时间
+const defaultShutdownTimeout = 30 * time.Second
+
 func main() {
 	dir, _ := os.Getwd()
 
@@ -64,7 +86,7 @@ WantedBy=multi-user.target`,
 		case "install":
 			err = s.Install()
 			if err != nil {
-				slog.Error("服务安装失败: ", err)
+				slog.Error("服务安装失败", "err", err)
 			} else {
 				slog.Info("服务安装成功.")
 			}
@@ -72,7 +94,7 @@ WantedBy=multi-user.target`,
 		case "uninstall":
 			err = s.Uninstall()
 			if err != nil {
-				slog.Error("服务卸载失败: ", err)
+				slog.Error("服务卸载失败", "err", err)
 			} else {
 				slog.Info("服务卸载成功.")
 			}
@@ -83,10 +105,52 @@ WantedBy=multi-user.target`,
 		case "help":
 			slog.Info(common.COMMON_PROJECT_HELP)
 			return
+		case "migrate":
+			if err = runMigrate(os.Args[2:]); err != nil {
+				slog.Error("数据库迁移失败", "err", err)
+				os.Exit(1)
+			}
+			return
+		case "collect":
+			if err = runCollect(os.Args[2:]); err != nil {
+				slog.Error("采集失败", "err", err)
+				os.Exit(1)
+			}
+			return
+		case "steam-stub":
+			if err = runSteamStub(os.Args[2:]); err != nil {
+				slog.Error("Steam 替身服务退出", "err", err)
+				os.Exit(1)
+			}
+			return
+		case "digest":
+			if err = runDigest(); err != nil {
+				slog.Error("发送降价提醒失败", "err", err)
+				os.Exit(1)
+			}
+			return
+		case "smtp-stub":
+			if err = runSmtpStub(os.Args[2:]); err != nil {
+				slog.Error("SMTP 替身服务退出", "err", err)
+				os.Exit(1)
+			}
+			return
+		case "seed":
+			if err = runSeed(os.Args[2:]); err != nil {
+				slog.Error("导入初始数据失败", "err", err)
+				os.Exit(1)
+			}
+			return
 		}
 		return
 	}
 
+	// 启动服务需要签名密钥, 其余子命令不校验
+	if err = env.GetServerConfig().ValidateSecrets(); err != nil {
+		slog.Error("配置校验失败", "err", err)
+		os.Exit(1)
+	}
+
 	// 内存限制和 GC 策略
 	debug.SetGCPercent(env.GetServerConfig().Server.GCPercent)
 	debug.SetMemoryLimit(int64(env.GetServerConfig().Server.MemoryLimit << 30))
@@ -100,11 +164,165 @@ WantedBy=multi-user.target`,
 	}
 }
 
-type goFurry struct{}
+// runMigrate 执行 migrate up|down [n]|status
+func runMigrate(args []string) error {
+	m, err := migrate.New(db.Orm.DB())
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return errors.New("用法: migrate up|down [n]|status")
+	}
+	switch args[0] {
+	case "up":
+		return m.Up(os.Stdout)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return errors.New("回滚数量必须为正整数: " + args[1])
+			}
+		}
+		return m.Down(os.Stdout, steps)
+	case "status":
+		return m.Status(os.Stdout)
+	default:
+		return errors.New("未知的 migrate 命令: " + args[0])
+	}
+}
 
-func InitOnStart() {
+// checkSchema 存在未执行或被修改的迁移时拒绝启动, 避免代码访问不存在的表或字段
+func checkSchema() error {
+	m, err := migrate.New(db.Orm.DB())
+	if err != nil {
+		return err
+	}
+	pending, err := m.Pending()
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		names := make([]string, len(pending))
+		for i, mg := range pending {
+			names[i] = fmt.Sprintf("%d_%s", mg.Version, mg.Name)
+		}
+		return fmt.Errorf("存在未执行的迁移 %s, 请先执行 migrate up", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// runSeed 执行 seed [--fixtures <dir>] [--force]
+func runSeed(args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	dir := fs.String("fixtures", seed.DefaultFixtureDir, "夹具目录")
+	force := fs.Bool("force", false, "非 debug 模式下强制导入")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	initLogger()
+	defer gfLog.Sync()
+	cs.InitRedisOnStart()
+	defer cs.CloseRedis()
+	defer db.Orm.Close()
+	return seed.Run(*dir, os.Stdout, *force)
+}
+
+// runCollect 执行 collect [info|players], 缺省时两者都采集
+func runCollect(args []string) error {
+	target := "all"
+	if len(args) > 0 {
+		target = args[0]
+	}
+	if target != "all" && target != "info" && target != "players" {
+		return errors.New("用法: collect [info|players]")
+	}
+	initLogger()
+	defer gfLog.Sync()
+	cs.InitRedisOnStart()
+	defer cs.CloseRedis()
+	defer db.Orm.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	jobs := []struct {
+		name    string
+		collect func(context.Context) (collectorModels.CollectVo, common.GFError)
+	}{
+		{"info", collector.GetCollectorService().CollectAppInfo},
+		{"players", collector.GetCollectorService().CollectPlayerCount},
+	}
+	for _, job := range jobs {
+		if target != "all" && target != job.name {
+			continue
+		}
+		res, gfErr := job.collect(ctx)
+		if gfErr != nil {
+			return errors.New(gfErr.GetMsg())
+		}
+		fmt.Printf("%s: total %d, success %d, failed %d\n", job.name, res.Total, res.Success, res.Failed)
+		for _, v := range res.Errors {
+			fmt.Println("  " + v)
+		}
+	}
+	task.UpdateGamePanelCache()
+	task.UpdateGameNewsCache()
+	return nil
+}
+
+// runSteamStub 执行 steam-stub [--dir <dir>] [--addr <addr>], 用录制的响应模拟 Steam 接口
+func runSteamStub(args []string) error {
+	fs := flag.NewFlagSet("steam-stub", flag.ContinueOnError)
+	dir := fs.String("dir", "./conf/fixtures/steam", "录制响应目录")
+	addr := fs.String("addr", "127.0.0.1:9997", "监听地址")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	slog.Info("Steam 替身服务已启动", "addr", *addr, "dir", *dir)
+	return http.ListenAndServe(*addr, stub.NewHandler(*dir))
+}
+
+// runDigest 立即发送一次降价提醒
+func runDigest() error {
+	if err := env.GetServerConfig().ValidateSecrets(); err != nil {
+		return err
+	}
+	initLogger()
+	defer gfLog.Sync()
+	cs.InitRedisOnStart()
+	defer cs.CloseRedis()
+	defer db.Orm.Close()
+
+	res, gfErr := subscribe.GetSubscribeService().SendPriceDigest()
+	fmt.Printf("changed %d, alerts %d, sent %d/%d\n", res.Changed, res.Alerts, res.Sent, res.Emails)
+	if gfErr != nil {
+		return errors.New(gfErr.GetMsg())
+	}
+	return nil
+}
+
+// runSmtpStub 执行 smtp-stub [--dir <dir>] [--addr <addr>], 接收邮件并保存为 .eml 文件
+func runSmtpStub(args []string) error {
+	fs := flag.NewFlagSet("smtp-stub", flag.ContinueOnError)
+	dir := fs.String("dir", "./data/mail", "邮件保存目录")
+	addr := fs.String("addr", "127.0.0.1:1025", "监听地址")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	slog.Info("SMTP 替身服务已启动", "addr", *addr, "dir", *dir)
+	return smtpStub.Serve(l, *dir, os.Stdout)
+}
+
+type goFurry struct {
+	app *fiber.App
+}
+
+// initLogger 按运行模式初始化自定义日志
+func initLogger() {
 	cfg := env.GetServerConfig()
-	// 初始化自定义日志
 	logCfg := &gfLog.Config{
 		ShowLine:   true,
 		TimeFormat: common.TIME_FORMAT_DATE,
@@ -125,12 +343,22 @@ func InitOnStart() {
 		logCfg.TimeFormat = common.TIME_FORMAT_LOG
 	}
 
-	// 初始化自定义日志
 	err := gfLog.InitLogger(logCfg)
 	if err != nil {
 		slog.Error(err.Error())
 		os.Exit(1)
 	}
+}
+
+func InitOnStart() {
+	cfg := env.GetServerConfig()
+	// 初始化自定义日志
+	initLogger()
+	// 后台管理、登录等功能依赖迁移后的表结构
+	if err := checkSchema(); err != nil {
+		gfLog.Error("数据库表结构校验失败: ", err)
+		os.Exit(1)
+	}
 
 	// 初始化 Prometheus 中间件
 	middleware.InitPrometheus(middleware.FiberPromConf{
@@ -141,7 +369,9 @@ func InitOnStart() {
 	middleware.InitGeoIP()
 	// 初始化 Coraza 中间件
 	if cfg.Waf.WafSwitch {
-		middleware.InitGlobalWAF(cfg.Waf.ConfPath)
+		if err := middleware.InitGlobalWAF(cfg.Waf.ConfPath); err != nil {
+			gfLog.Error("初始化 WAF 失败: ", err)
+		}
 	}
 	// 初始化 redis
 	cs.InitRedisOnStart()
@@ -153,19 +383,28 @@ func InitOnStart() {
 }
 
 func (gf *goFurry) Start(s service.Service) error {
+	gf.app = routers.Router.Init()
 	go gf.run()
 	return nil
 }
 
+// 退出信号由 service.Run 统一处理, 收到后调用 Stop
 func (gf *goFurry) run() {
+	// SIGHUP 热重载配置
 	go func() {
-		c := make(chan os.Signal)
-		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
-		errChan <- fmt.Errorf("%s", <-c)
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		for range hup {
+			gfLog.Info("收到 SIGHUP, 开始热重载")
+			if _, err := system.GetSystemService().Reload(); err != nil {
+				gfLog.Error("SIGHUP 热重载失败: ", err.GetMsg())
+			}
+		}
 	}()
+
 	// 启动 web
 	go func() {
-		app := routers.Router.Init()
+		app := gf.app
 
 		addr := env.GetServerConfig().Server.IPAddress + ":" + env.GetServerConfig().Server.Port
 		// nginx 完成 https 就不使用 TLS
@@ -185,6 +424,53 @@ func (gf *goFurry) run() {
 	}
 }
 
+// Stop 优雅关闭: 停止接收请求 -> 停止时间轮 -> 等待定时任务 -> 关闭连接
 func (gf *goFurry) Stop(s service.Service) error {
+	timeout := time.Duration(env.GetServerConfig().Server.ShutdownTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	gfLog.Info("开始优雅关闭, 超时时间: ", timeout)
+
+	// 1. 停止接收新请求, 等待处理中的请求完成
+	if gf.app != nil {
+		if err := gf.app.ShutdownWithTimeout(timeout); err != nil {
+			gfLog.Error("关闭 Fiber 失败: ", err)
+		} else {
+			gfLog.Info("Fiber 已关闭")
+		}
+	}
+
+	// 2. 停止时间轮, 不再触发新的定时任务
+	cs.Stop()
+	gfLog.Info("时间轮已停止")
+
+	// 3. 等待执行中的定时任务
+	if schedule.Wait(time.Until(deadline)) {
+		gfLog.Info("定时任务已全部结束")
+	} else {
+		gfLog.Warn("等待定时任务超时, 强制关闭")
+	}
+
+	// 4. 关闭 Redis、Postgres、Mongo
+	if err := cs.CloseRedis(); err != nil {
+		gfLog.Error("关闭 Redis 失败: ", err)
+	} else {
+		gfLog.Info("Redis 已关闭")
+	}
+	if err := db.Orm.Close(); err != nil {
+		gfLog.Error("关闭 Postgres 失败: ", err)
+	} else {
+		gfLog.Info("Postgres 已关闭")
+	}
+	if err := cs.Mongo.Close(); err != nil {
+		gfLog.Error("关闭 MongoDB 失败: ", err)
+	} else {
+		gfLog.Info("MongoDB 已关闭")
+	}
+
+	gfLog.Info("优雅关闭完成")
+	_ = gfLog.Sync()
 	return nil
 }
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/common/log"
+	cm "github.com/GoFurry/gofurry-game-backend/common/models"
+	"github.com/GoFurry/gofurry-game-backend/common/util"
+	"github.com/gofiber/fiber/v2"
+)
+
+/*
+ * @Desc: 鉴权中间件
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+// 剩余有效期低于该值时续租 Token
+const tokenRenewThreshold = common.JWT_RELET_NUM * time.Hour / 2
+
+// AuthMiddleware 校验 Bearer Token, 通过后将用户信息写入 Locals
+// Token 剩余有效期不足一半时, 通过 X-Renew-Token 响应头下发新 Token
+func AuthMiddleware(c *fiber.Ctx) error {
+	authorization := c.Get(fiber.HeaderAuthorization)
+	tokenStr, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || strings.TrimSpace(tokenStr) == "" {
+		return common.NewResponse(c).ErrorWithCode("未登录", fiber.StatusUnauthorized)
+	}
+
+	claims, err := util.ParseToken(strings.TrimSpace(tokenStr))
+	if err != nil || claims == nil {
+		return common.NewResponse(c).ErrorWithCode("登录已失效, 请重新登录", fiber.StatusUnauthorized)
+	}
+
+	// 滑动续租
+	if claims.ExpiresAt != nil && time.Until(claims.ExpiresAt.Time) < tokenRenewThreshold {
+		newToken, err := util.NewToken(claims.UserId, claims.UserName, claims.Role)
+		if err != nil {
+			log.Error("AuthMiddleware renew token err: ", err)
+		} else {
+			c.Set(common.TOKEN_RENEW_HEADER, newToken)
+		}
+	}
+
+	c.Locals(common.COMMON_AUTH_CURRENT, claims)
+	return c.Next()
+}
+
+// RequireRole 校验当前用户角色, 需在 AuthMiddleware 之后使用
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals(common.COMMON_AUTH_CURRENT).(*cm.GFClaims)
+		if !ok || claims == nil {
+			return common.NewResponse(c).ErrorWithCode("未登录", fiber.StatusUnauthorized)
+		}
+		if !util.In(claims.Role, roles) {
+			return common.NewResponse(c).ErrorWithCode("权限不足", fiber.StatusForbidden)
+		}
+		return c.Next()
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -10,9 +11,10 @@ import (
 	"os"
 	"strconv"
 	"strings"
-	"sync"
+	"sync/atomic"
 
 	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/roof/env"
 	"github.com/corazawaf/coraza/v3"
 	"github.com/corazawaf/coraza/v3/debuglog"
 	"github.com/corazawaf/coraza/v3/experimental"
@@ -27,12 +29,8 @@ import (
  * @version: v1.0.2
  */
 
-// 全局 WAF 单例与初始化锁
-var (
-	globalWAF  coraza.WAF
-	wafOnce    sync.Once
-	wafInitErr error
-)
+// 全局 WAF 实例, 热重载时原子替换
+var globalWAF atomic.Pointer[coraza.WAF]
 
 // CorazaCfg 中间件配置文件
 type CorazaCfg struct {
@@ -79,45 +77,53 @@ func DefaultCorazaCfg() CorazaCfg {
 	}
 }
 
-// InitGlobalWAFWithCfg 基于配置初始化全局 WAF 单例
-func InitGlobalWAFWithCfg(cfg CorazaCfg) {
-	wafOnce.Do(func() {
-		globalWAF, wafInitErr = createWAFWithCfg(cfg)
-		if wafInitErr != nil {
-			slog.Error("[CorazaWAF] InitGlobalWAFWithCfg Error", wafInitErr.Error())
-		}
-	})
+// InitGlobalWAFWithCfg 基于配置创建 WAF 并替换全局实例
+// 创建失败时保留原实例, 重复调用即为热重载
+func InitGlobalWAFWithCfg(cfg CorazaCfg) error {
+	waf, err := createWAFWithCfg(cfg)
+	if err != nil {
+		slog.Error("[CorazaWAF] InitGlobalWAFWithCfg Error", "err", err)
+		return err
+	}
+	globalWAF.Store(&waf)
+	slog.Info("[CorazaWAF] WAF 规则加载成功", "file", cfg.DirectivesFile)
+	return nil
+}
+
+// WAFLoaded 全局 WAF 是否加载成功
+func WAFLoaded() bool {
+	return globalWAF.Load() != nil
 }
 
 // InitGlobalWAF 传入 Coraza 配置文件路径完成初始化
-func InitGlobalWAF(path ...string) {
+func InitGlobalWAF(path ...string) error {
 	if len(path) > 0 {
-		InitGlobalWAFWithCfg(CorazaCfg{
+		return InitGlobalWAFWithCfg(CorazaCfg{
 			DirectivesFile: path[0],
 		})
-	} else {
-		InitGlobalWAFWithCfg(DefaultCorazaCfg())
 	}
+	return InitGlobalWAFWithCfg(DefaultCorazaCfg())
 }
 
-// CorazaMiddleware 中间件
+// CorazaMiddleware 中间件 按当前配置决定是否启用
 func CorazaMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) (err error) {
-		// 直接使用全局 WAF 实例
-		if wafInitErr != nil {
-			slog.Error("WAF全局实例初始化失败: ", wafInitErr)
-			return common.NewResponse(c).ErrorWithCode("WAF 初始化失败", http.StatusInternalServerError)
+		if !env.GetServerConfig().Waf.WafSwitch {
+			return c.Next()
 		}
-		if globalWAF == nil {
+		// 取出当前的全局 WAF 实例, 本次请求内不受热重载影响
+		wafPtr := globalWAF.Load()
+		if wafPtr == nil {
 			return common.NewResponse(c).ErrorWithCode("WAF 实例未初始化", http.StatusInternalServerError)
 		}
+		waf := *wafPtr
 
 		// 事件句柄匿名函数
 		newTX := func(*http.Request) types.Transaction {
-			return globalWAF.NewTransaction()
+			return waf.NewTransaction()
 		}
 		// 事件句柄匿名函数
-		if ctxwaf, ok := globalWAF.(experimental.WAFWithOptions); ok {
+		if ctxwaf, ok := waf.(experimental.WAFWithOptions); ok {
 			newTX = func(r *http.Request) types.Transaction {
 				return ctxwaf.NewTransactionWithOptions(experimental.Options{
 					Context: r.Context(),
@@ -172,7 +178,7 @@ func CorazaMiddleware() fiber.Handler {
 // logError WAF 错误日志
 func logError(error types.MatchedRule) {
 	slog.Warn("WAF rule matched",
-		slog.String("severity", string(error.Rule().Severity())),
+		slog.String("severity", error.Rule().Severity().String()),
 		slog.String("error_log", error.ErrorLog()),
 		slog.Int("rule_id", error.Rule().ID()),
 	)
@@ -290,13 +296,10 @@ func createWAFWithCfg(cfg CorazaCfg) (coraza.WAF, error) {
 
 	// 验证核心配置有效性
 	if cfg.DirectivesFile == "" {
-		slog.Warn("WAF 规则文件路径未配置 (directives_file 为空)")
-		panic("WAF 规则文件路径未配置 (directives_file 为空)")
-	} else {
-		if _, err := os.Stat(cfg.DirectivesFile); os.IsNotExist(err) {
-			slog.Warn("WAF 规则文件不存在: ", cfg.DirectivesFile)
-			panic("WAF 规则文件路径未配置 (directives_file 为空)")
-		}
+		return nil, errors.New("WAF 规则文件路径未配置 (directives_file 为空)")
+	}
+	if _, err := os.Stat(cfg.DirectivesFile); os.IsNotExist(err) {
+		return nil, errors.New("WAF 规则文件不存在: " + cfg.DirectivesFile)
 	}
 
 	wafConfig := coraza.NewWAFConfig()
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"sync/atomic"
+
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/roof/env"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+/*
+ * @Desc: 跨域中间件 支持热重载
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+var corsHandler atomic.Pointer[fiber.Handler]
+
+// CorsMiddleware 使用当前配置的跨域中间件
+func CorsMiddleware() fiber.Handler {
+	if corsHandler.Load() == nil {
+		ReloadCors()
+	}
+	return func(c *fiber.Ctx) error {
+		return (*corsHandler.Load())(c)
+	}
+}
+
+// ReloadCors 按当前配置重建跨域中间件
+func ReloadCors() {
+	handler := cors.New(cors.Config{
+		AllowOrigins:     env.GetServerConfig().Middleware.Cors.AllowOrigins,
+		AllowMethods:     "GET,POST,PUT,DELETE,PATCH,OPTIONS",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Requested-With",
+		AllowCredentials: true,
+		ExposeHeaders:    "Content-Length, " + common.TOKEN_RENEW_HEADER, // 允许前端读取续租后的 Token
+		MaxAge:           86400,                                          // 预检请求缓存 24 小时
+	})
+	corsHandler.Store(&handler)
+}
@@ -2,10 +2,12 @@ package middleware
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/GoFurry/gofurry-game-backend/common/log"
@@ -22,8 +24,10 @@ import (
  * @version: v1.0.0
  */
 
-// GeoIP DB 全局变量
+// GeoIP DB 全局变量 查询时持有读锁, 替换时持有写锁
+// 旧 Reader 关闭后内存映射失效, 因此查询必须在读锁内完成
 var (
+	geoMu     sync.RWMutex
 	countryDB *geoip2.Reader
 	cityDB    *geoip2.Reader
 	asnDB     *geoip2.Reader
@@ -31,22 +35,56 @@ var (
 
 // 初始化 GeoIP DB
 func InitGeoIP() {
-	var err error
+	if err := ReloadGeoIP(); err != nil {
+		log.Error("[GeoLite2] ", err)
+	}
+}
+
+// ReloadGeoIP 重新打开 GeoIP DB 并替换, 打开失败的库保留原 Reader
+func ReloadGeoIP() error {
 	var url = env.GetServerConfig().Resource.Geolite2Path
+	var errs []error
 
-	countryDB, err = geoip2.Open(url + "/GeoLite2-Country.mmdb")
-	if err != nil {
-		log.Error("[GeoLite2] open Country DB fail 打开 Country DB 失败: ", err)
+	open := func(name string) *geoip2.Reader {
+		reader, err := geoip2.Open(url + "/GeoLite2-" + name + ".mmdb")
+		if err != nil {
+			errs = append(errs, fmt.Errorf("open %s DB fail 打开 %s DB 失败: %w", name, name, err))
+			return nil
+		}
+		return reader
 	}
+	newCountry, newCity, newASN := open("Country"), open("City"), open("ASN")
 
-	cityDB, err = geoip2.Open(url + "/GeoLite2-City.mmdb")
-	if err != nil {
-		log.Error("[GeoLite2] open City DB fail 打开 City DB 失败: ", err)
+	var stale []*geoip2.Reader
+	geoMu.Lock()
+	if newCountry != nil {
+		stale, countryDB = append(stale, countryDB), newCountry
+	}
+	if newCity != nil {
+		stale, cityDB = append(stale, cityDB), newCity
+	}
+	if newASN != nil {
+		stale, asnDB = append(stale, asnDB), newASN
 	}
+	geoMu.Unlock()
 
-	asnDB, err = geoip2.Open(url + "/GeoLite2-ASN.mmdb")
-	if err != nil {
-		log.Error("[GeoLite2] open ASN DB fail 打开 ASN DB 失败: ", err)
+	// 写锁释放后已无请求持有旧 Reader
+	for _, reader := range stale {
+		if reader != nil {
+			reader.Close()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GeoIPStatus 各 GeoIP 数据库是否加载成功
+func GeoIPStatus() map[string]bool {
+	geoMu.RLock()
+	defer geoMu.RUnlock()
+	return map[string]bool{
+		"country": countryDB != nil,
+		"city":    cityDB != nil,
+		"asn":     asnDB != nil,
 	}
 }
 
@@ -157,28 +195,11 @@ func getClientIP(c *fiber.Ctx) string {
 	return ip
 }
 
-// 中间件入口
-func GeoIPStat(c *fiber.Ctx) error {
-	ip := getClientIP(c)
-	if ip == "" {
-		// 无法获取公网 IP 不计数
-		return c.Next()
-	}
-
-	parsedIP := net.ParseIP(ip)
-	if parsedIP == nil {
-		return c.Next()
-	}
-
-	cacheKey := "stat-geoip-cache:" + ip
-	var info ipInfo
-
-	// 查缓存
-	if cached, err := cs.GetString(cacheKey); err == nil && cached != "" {
-		return c.Next()
-	}
+// lookupLocalGeoIP 在读锁内查询本地 GeoIP 数据库
+func lookupLocalGeoIP(parsedIP net.IP, info *ipInfo) {
+	geoMu.RLock()
+	defer geoMu.RUnlock()
 
-	// 本地 GeoIP 数据库
 	if countryDB != nil {
 		if countryInfo, err := countryDB.Country(parsedIP); err == nil && countryInfo != nil {
 			if name, ok := countryInfo.Country.Names["zh-CN"]; ok && name != "" {
@@ -200,6 +221,31 @@ func GeoIPStat(c *fiber.Ctx) error {
 			}
 		}
 	}
+}
+
+// 中间件入口
+func GeoIPStat(c *fiber.Ctx) error {
+	ip := getClientIP(c)
+	if ip == "" {
+		// 无法获取公网 IP 不计数
+		return c.Next()
+	}
+
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return c.Next()
+	}
+
+	cacheKey := "stat-geoip-cache:" + ip
+	var info ipInfo
+
+	// 查缓存
+	if cached, err := cs.GetString(cacheKey); err == nil && cached != "" {
+		return c.Next()
+	}
+
+	// 本地 GeoIP 数据库
+	lookupLocalGeoIP(parsedIP, &info)
 
 	// 如果本地数据不全，调用百度 API
 	if info.Country == "" || (info.Country == "中国" && (info.City == "" || info.ISP == "")) {
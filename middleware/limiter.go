@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/roof/env"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+/*
+ * @Desc: 限流中间件 支持热重载
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+var limiterHandler atomic.Pointer[fiber.Handler]
+
+// LimiterMiddleware 使用当前配置的限流中间件, 关闭时直接放行
+func LimiterMiddleware() fiber.Handler {
+	if limiterHandler.Load() == nil {
+		ReloadLimiter()
+	}
+	return func(c *fiber.Ctx) error {
+		return (*limiterHandler.Load())(c)
+	}
+}
+
+// ReloadLimiter 按当前配置重建限流中间件, 重建后计数重新开始
+func ReloadLimiter() {
+	cfg := env.GetServerConfig().Middleware.Limiter
+	handler := func(c *fiber.Ctx) error { return c.Next() }
+	if cfg.IsOn {
+		handler = limiter.New(limiter.Config{
+			Max:        cfg.MaxRequests,              // 单位时间最大请求数
+			Expiration: cfg.Expiration * time.Second, // 时间窗口
+			KeyGenerator: func(c *fiber.Ctx) string {
+				return c.IP() // 按 IP 限流
+			},
+			LimitReached: func(c *fiber.Ctx) error {
+				return common.NewResponse(c).ErrorWithCode("请求过于频繁, 请稍后再试", fiber.StatusTooManyRequests)
+			},
+		})
+	}
+	limiterHandler.Store(&handler)
+}
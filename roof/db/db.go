@@ -1,6 +1,8 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -52,6 +54,30 @@ func (db *orm) loadDBConfig() {
 	}
 }
 
+// Ping 检测数据库连接, 未初始化时返回错误
+func (db *orm) Ping(ctx context.Context) error {
+	if db.engine == nil {
+		return errors.New("database 未初始化")
+	}
+	sqlDB, err := db.engine.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Close 关闭底层连接池, 未初始化时直接返回
+func (db *orm) Close() error {
+	if db.engine == nil {
+		return nil
+	}
+	sqlDB, err := db.engine.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
 func (db *orm) DB() *gorm.DB {
 	once.Do(initOrm)
 	return db.engine
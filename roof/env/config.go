@@ -4,17 +4,20 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/GoFurry/gofurry-game-backend/common"
 	"gopkg.in/yaml.v2"
 )
 
-func init() {
-	InitServerConfig(common.COMMON_PROJECT_NAME)
-}
+// 当前生效的配置, 热重载时整体替换
+var configuration atomic.Pointer[serverConfig]
 
-var configuration = new(serverConfig)
+// 首次读取配置时加载, 只引用工具函数的包(如单元测试)不需要配置文件
+var configOnce sync.Once
 
 type serverConfig struct {
 	ClusterId  int              `yaml:"cluster_id"`
@@ -31,6 +34,66 @@ type serverConfig struct {
 	Resource   ResourceConfig   `yaml:"resource"`
 	Auth       AuthConfig       `yaml:"auth"`
 	Prometheus PrometheusConfig `yaml:"prometheus"`
+	Collector  CollectorConfig  `yaml:"collector"`
+	Mail       MailConfig       `yaml:"mail"`
+	Site       SiteConfig       `yaml:"site"`
+	Recommend  RecommendConfig  `yaml:"recommend"`
+}
+
+// RecommendConfig 推荐算法配置
+type RecommendConfig struct {
+	CFWeight    float64 `yaml:"cf_weight"`     // 混合推荐中协同过滤的权重, 与 cbf_weight 均为 0 时各占一半
+	CBFWeight   float64 `yaml:"cbf_weight"`    // 混合推荐中基于内容推荐的权重
+	CFMinCommon int     `yaml:"cf_min_common"` // 计算物品相似度所需的最少共同评分人数, 0 时为 2
+	CFShrinkage float64 `yaml:"cf_shrinkage"`  // 相似度收缩系数, 共同评分人数少时降低相似度
+}
+
+// SiteConfig 站点信息, 用于订阅源、站点地图等对外链接
+type SiteConfig struct {
+	URL           string `yaml:"url"`            // 前端站点地址
+	APIURL        string `yaml:"api_url"`        // 后端对外地址, 用于订阅源与站点地图自身链接
+	GamePath      string `yaml:"game_path"`      // 游戏页面路径, {lang} 与 {id} 会被替换
+	TagPath       string `yaml:"tag_path"`       // 标签页面路径, 同上
+	CreatorPath   string `yaml:"creator_path"`   // 创作者页面路径, 同上
+	SitemapChunk  int    `yaml:"sitemap_chunk"`  // 单个站点地图的最大 URL 数, 0 时为 50000
+	Title         string `yaml:"title"`          // 站点名称
+	TitleEn       string `yaml:"title_en"`       // 站点英文名称
+	Description   string `yaml:"description"`    // 站点简介
+	DescriptionEn string `yaml:"description_en"` // 站点英文简介
+}
+
+// PageURL 将页面路径中的 {lang} 与 {id} 替换后拼接到站点地址
+func (s SiteConfig) PageURL(path string, lang string, id string) string {
+	path = strings.NewReplacer("{lang}", lang, "{id}", id).Replace(path)
+	return strings.TrimRight(s.URL, "/") + path
+}
+
+// MailConfig 邮件发送与降价订阅配置
+type MailConfig struct {
+	IsOn              bool   `yaml:"is_on"`              // 是否启用邮件发送与降价提醒
+	Host              string `yaml:"host"`               // SMTP 地址, 本地联调可指向 smtp-stub
+	Port              int    `yaml:"port"`               // SMTP 端口
+	Username          string `yaml:"username"`           // 为空时不认证
+	Password          string `yaml:"password"`           // 认证密码
+	From              string `yaml:"from"`               // 发件人
+	SSL               bool   `yaml:"ssl"`                // 是否直接使用 TLS 连接(465 端口)
+	SignSecret        string `yaml:"sign_secret"`        // 退订链接签名密钥
+	UnsubscribeURL    string `yaml:"unsubscribe_url"`    // 退订接口地址, 邮件中拼接 id 与签名
+	CodeExpire        int    `yaml:"code_expire"`        // 验证码有效期(分钟)
+	DiscountThreshold int64  `yaml:"discount_threshold"` // 默认折扣阈值(百分比), 折扣达到阈值时提醒
+}
+
+// CollectorConfig Steam 数据采集配置
+type CollectorConfig struct {
+	IsOn      bool     `yaml:"is_on"`      // 是否启用定时采集
+	StoreURL  string   `yaml:"store_url"`  // 商店接口地址, 本地联调时可指向替身服务
+	APIURL    string   `yaml:"api_url"`    // Web API 地址
+	Countries []string `yaml:"countries"`  // 价格列表采集的地区代码
+	NewsCount int      `yaml:"news_count"` // 每种语言保留的更新公告数
+	Timeout   int      `yaml:"timeout"`    // 单次请求超时(秒)
+	Retry     int      `yaml:"retry"`      // 失败重试次数
+	Backoff   int      `yaml:"backoff"`    // 首次重试等待(毫秒), 之后按指数增长
+	UseProxy  bool     `yaml:"use_proxy"`  // 是否通过 proxy.url 访问
 }
 
 type PrometheusConfig struct {
@@ -130,6 +193,8 @@ type ServerConfig struct {
 	GCPercent     int    `yaml:"gc_percent"`
 	Network       string `yaml:"network"`
 	EnablePrefork bool   `yaml:"enable_prefork"`
+	// 优雅关闭超时时间(秒)
+	ShutdownTimeout int `yaml:"shutdown_timeout"`
 }
 
 type KeyConfig struct {
@@ -140,10 +205,50 @@ type KeyConfig struct {
 }
 
 func InitServerConfig(projectName string) {
-	InitConfig(projectName, "server.yaml", configuration)
+	conf, err := loadServerConfig(projectName)
+	if err != nil {
+		fmt.Println("invalid server config:\n" + err.Error())
+		panic("invalid server config: " + err.Error())
+	}
+	configuration.Store(conf)
+}
+
+// ReloadServerConfig 重新读取配置, 校验通过后替换当前配置, 失败时保留原配置
+// 数据库、Redis、监听地址等连接类配置需重启后生效
+// 只在服务运行时调用, 因此同时校验签名密钥
+func ReloadServerConfig() (*serverConfig, error) {
+	conf, err := loadServerConfig(common.COMMON_PROJECT_NAME)
+	if err != nil {
+		return nil, err
+	}
+	if err = conf.ValidateSecrets(); err != nil {
+		return nil, err
+	}
+	configuration.Store(conf)
+	return conf, nil
+}
+
+// loadServerConfig 读取配置文件, 用环境变量覆盖后统一校验, 签名密钥由 ValidateSecrets 单独校验
+func loadServerConfig(projectName string) (*serverConfig, error) {
+	conf := new(serverConfig)
+	if err := LoadConfig(projectName, "server.yaml", conf); err != nil {
+		return nil, err
+	}
+	if err := errors.Join(applyEnvOverrides(conf), conf.Validate()); err != nil {
+		return nil, err
+	}
+	return conf, nil
 }
 
 func InitConfig(projectName string, fileName string, conf interface{}) {
+	if err := LoadConfig(projectName, fileName, conf); err != nil {
+		fmt.Println(err.Error())
+		panic(err.Error())
+	}
+}
+
+// LoadConfig 依次尝试 /etc/<项目名>/ 与 ./conf/ 下的配置文件
+func LoadConfig(projectName string, fileName string, conf interface{}) error {
 	hit := false
 
 	file := "/etc/" + projectName + "/" + fileName
@@ -175,9 +280,9 @@ func InitConfig(projectName string, fileName string, conf interface{}) {
 	}
 
 	if hit == false {
-		fmt.Println("can not find any " + fileName + " file")
-		panic("can not find any " + fileName + " file")
+		return errors.New("can not find any " + fileName + " file")
 	}
+	return nil
 }
 
 func getOrDefault(key string, def string) string {
@@ -212,5 +317,10 @@ func loadYaml(path string, conf interface{}) (err error) {
 }
 
 func GetServerConfig() *serverConfig {
-	return configuration
+	configOnce.Do(func() {
+		if configuration.Load() == nil {
+			InitServerConfig(common.COMMON_PROJECT_NAME)
+		}
+	})
+	return configuration.Load()
 }
@@ -0,0 +1,240 @@
+package env
+
+/*
+ * @Desc: 环境变量覆盖与配置校验
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// 环境变量前缀, 嵌套字段按 yaml 标签以下划线拼接
+// 如 database.db_password -> GF_GAME_DATABASE_DB_PASSWORD
+const envPrefix = "GF_GAME"
+
+// applyEnvOverrides 用环境变量覆盖配置, 返回所有无法解析的值
+func applyEnvOverrides(conf any) error {
+	v := reflect.ValueOf(conf)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return errors.New("配置必须为结构体指针")
+	}
+	var errs []error
+	overrideStruct(envPrefix, v.Elem(), &errs)
+	return errors.Join(errs...)
+}
+
+func overrideStruct(prefix string, v reflect.Value, errs *[]error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := prefix + "_" + strings.ToUpper(tag)
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			overrideStruct(key, fv, errs)
+			continue
+		}
+		raw := getOrDefault(key, "")
+		if raw == "" {
+			continue
+		}
+		if err := setValue(fv, raw); err != nil {
+			*errs = append(*errs, fmt.Errorf("环境变量 %s=%q 无效: %w", key, raw, err))
+		}
+	}
+}
+
+// setValue 按字段类型解析字符串
+func setValue(fv reflect.Value, raw string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// time.Duration 字段在配置中以秒为单位的整数书写, 同样按整数解析
+		n, err := strconv.ParseInt(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		// 字符串切片以逗号分隔
+		if fv.Type().Elem().Kind() != reflect.String {
+			return errors.New("不支持的切片类型 " + fv.Type().String())
+		}
+		parts := strings.Split(raw, ",")
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		fv.Set(reflect.ValueOf(parts))
+	default:
+		return errors.New("不支持的字段类型 " + fv.Type().String())
+	}
+	return nil
+}
+
+// Validate 校验配置, 一次性返回所有问题
+func (c *serverConfig) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.ClusterId >= 0 && c.ClusterId <= 1023, "cluster_id 必须在 0-1023 之间: %d", c.ClusterId)
+
+	// 服务器
+	check(isPort(c.Server.Port), "server.port 不是合法端口: %q", c.Server.Port)
+	check(c.Server.Network == "" || c.Server.Network == "tcp" || c.Server.Network == "tcp4" || c.Server.Network == "tcp6",
+		"server.network 只能为 tcp/tcp4/tcp6: %q", c.Server.Network)
+	check(c.Server.ShutdownTimeout >= 0, "server.shutdown_timeout 不能为负数: %d", c.Server.ShutdownTimeout)
+
+	// 数据库
+	check(strings.TrimSpace(c.DataBase.DBHost) != "", "database.db_host 不能为空")
+	check(isPort(c.DataBase.DBPort), "database.db_port 不是合法端口: %q", c.DataBase.DBPort)
+	check(strings.TrimSpace(c.DataBase.DBName) != "", "database.db_name 不能为空")
+	check(strings.TrimSpace(c.DataBase.DBUsername) != "", "database.db_username 不能为空")
+
+	// redis
+	host, port, err := net.SplitHostPort(c.Redis.RedisAddr)
+	check(err == nil && host != "" && isPort(port), "redis.redis_addr 必须为 host:port 格式: %q", c.Redis.RedisAddr)
+
+	// mongodb 配置了地址时才校验端口
+	if c.Mongodb.Host != "" {
+		check(isPort(c.Mongodb.Port), "mongodb.port 不是合法端口: %q", c.Mongodb.Port)
+	}
+
+	// 日志
+	if c.Server.Mode != "debug" {
+		check(c.Log.LogPath != "", "非 debug 模式下 log.log_path 不能为空")
+	}
+
+	// 中间件
+	if c.Middleware.Limiter.IsOn {
+		check(c.Middleware.Limiter.MaxRequests > 0, "middleware.limiter.max_requests 必须大于 0: %d", c.Middleware.Limiter.MaxRequests)
+		check(c.Middleware.Limiter.Expiration > 0, "middleware.limiter.expiration 必须大于 0: %d", c.Middleware.Limiter.Expiration)
+	}
+	if c.Waf.WafSwitch {
+		check(c.Waf.ConfPath != "", "waf.waf_switch 已开启但 waf.conf_path 为空")
+		if c.Waf.ConfPath != "" {
+			_, statErr := os.Stat(c.Waf.ConfPath)
+			check(statErr == nil, "waf.conf_path 文件不存在: %q", c.Waf.ConfPath)
+		}
+	}
+
+	// 线程
+	check(c.Thread.SteamAppInfoThread >= 0, "thread.steam_app_info_thread 不能为负数: %d", c.Thread.SteamAppInfoThread)
+
+	// 采集
+	if c.Collector.IsOn {
+		check(c.Collector.StoreURL != "", "collector.is_on 已开启但 collector.store_url 为空")
+		check(c.Collector.APIURL != "", "collector.is_on 已开启但 collector.api_url 为空")
+		check(c.Thread.SteamAppInfoThread > 0, "collector.is_on 已开启但 thread.steam_app_info_thread 不大于 0")
+	}
+	check(c.Collector.Retry >= 0 && c.Collector.Retry <= maxCollectorRetry,
+		"collector.retry 必须在 0-%d 之间: %d", maxCollectorRetry, c.Collector.Retry)
+	check(c.Collector.Backoff >= 0, "collector.backoff 不能为负数: %d", c.Collector.Backoff)
+	check(c.Collector.Timeout >= 0, "collector.timeout 不能为负数: %d", c.Collector.Timeout)
+	if c.Collector.UseProxy {
+		check(c.Proxy.Url != "", "collector.use_proxy 已开启但 proxy.url 为空")
+	}
+
+	// 邮件
+	if c.Mail.IsOn {
+		check(strings.TrimSpace(c.Mail.Host) != "", "mail.is_on 已开启但 mail.host 为空")
+		check(c.Mail.Port > 0 && c.Mail.Port <= 65535, "mail.port 不是合法端口: %d", c.Mail.Port)
+		check(strings.TrimSpace(c.Mail.From) != "", "mail.is_on 已开启但 mail.from 为空")
+		check(c.Mail.UnsubscribeURL != "", "mail.is_on 已开启但 mail.unsubscribe_url 为空")
+	}
+	check(c.Mail.CodeExpire >= 0, "mail.code_expire 不能为负数: %d", c.Mail.CodeExpire)
+	check(c.Mail.DiscountThreshold >= 0 && c.Mail.DiscountThreshold <= 100,
+		"mail.discount_threshold 必须在 0-100 之间: %d", c.Mail.DiscountThreshold)
+
+	// 站点
+	for _, site := range []struct{ key, raw string }{{"site.url", c.Site.URL}, {"site.api_url", c.Site.APIURL}} {
+		if site.raw == "" {
+			continue
+		}
+		u, parseErr := url.Parse(site.raw)
+		check(parseErr == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"%s 必须为 http(s) 地址: %q", site.key, site.raw)
+	}
+	for _, page := range []struct{ key, path string }{
+		{"site.game_path", c.Site.GamePath}, {"site.tag_path", c.Site.TagPath}, {"site.creator_path", c.Site.CreatorPath},
+	} {
+		check(page.path == "" || strings.HasPrefix(page.path, "/") && strings.Contains(page.path, "{id}"),
+			"%s 必须以 / 开头且包含 {id}: %q", page.key, page.path)
+	}
+	check(c.Site.SitemapChunk >= 0 && c.Site.SitemapChunk <= 50000, "site.sitemap_chunk 必须在 0-50000 之间: %d", c.Site.SitemapChunk)
+
+	// 推荐
+	check(c.Recommend.CFWeight >= 0 && c.Recommend.CBFWeight >= 0,
+		"recommend.cf_weight 与 recommend.cbf_weight 不能为负数: %v/%v", c.Recommend.CFWeight, c.Recommend.CBFWeight)
+	check(c.Recommend.CFMinCommon >= 0, "recommend.cf_min_common 不能为负数: %d", c.Recommend.CFMinCommon)
+	check(c.Recommend.CFShrinkage >= 0, "recommend.cf_shrinkage 不能为负数: %v", c.Recommend.CFShrinkage)
+
+	return errors.Join(errs...)
+}
+
+// ValidateSecrets 校验签名密钥, 只在启动服务与发送邮件前调用
+// 迁移、导入初始数据等子命令不需要密钥, 本地开发时无需配置
+func (c *serverConfig) ValidateSecrets() error {
+	var errs []error
+	if len(c.Auth.JwtSecret) < minSecretLength {
+		errs = append(errs, fmt.Errorf("auth.jwt_secret 必须为至少 %d 字节的随机字符串, 请设置 GF_GAME_AUTH_JWT_SECRET, 可使用 openssl rand -base64 48 生成", minSecretLength))
+	}
+	if c.Mail.IsOn && (c.Mail.SignSecret == placeholderSignSecret || len(c.Mail.SignSecret) < minSecretLength) {
+		errs = append(errs, fmt.Errorf("mail.sign_secret 必须为至少 %d 字节的随机字符串, 不能使用示例值, 请设置 GF_GAME_MAIL_SIGN_SECRET", minSecretLength))
+	}
+	return errors.Join(errs...)
+}
+
+// 采集失败的最大重试次数, 退避等待已封顶, 过多的重试只会拖慢整轮采集
+const maxCollectorRetry = 10
+
+// 密钥的最小长度(字节), 如 openssl rand -hex 16 或 openssl rand -base64 48 的输出
+const (
+	minSecretLength = 32
+
+	// 旧版示例配置中的退订签名密钥
+	placeholderSignSecret = "change-me-unsubscribe-secret"
+)
+
+// isPort 判断是否为 1-65535 的端口号
+func isPort(port string) bool {
+	n, err := strconv.Atoi(strings.TrimSpace(port))
+	return err == nil && n > 0 && n <= 65535
+}
@@ -0,0 +1,315 @@
+package migrate
+
+/*
+ * @Desc: 数据库版本迁移
+ * @author: 福狼
+ * @version: v1.0.0
+ */
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 迁移文件命名: <版本号>_<名称>.up.sql / <版本号>_<名称>.down.sql
+// down 脚本可省略, 省略的版本不可回滚, 如会删除全部业务数据的 0001 初始表结构
+//
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+const tableName = "gfg_schema_migrations"
+
+// Migration 单个迁移版本
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // up 脚本的 sha256, 用于检测已执行脚本被修改
+}
+
+// appliedRecord 已执行的迁移记录
+type appliedRecord struct {
+	Version   int64     `gorm:"column:version"`
+	Name      string    `gorm:"column:name"`
+	Checksum  string    `gorm:"column:checksum"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New 创建迁移执行器并加载内嵌的迁移脚本
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := load(migrationFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// load 读取并校验迁移脚本, 按版本号升序返回
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		fileName := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+		base := strings.TrimSuffix(fileName, "."+direction+".sql")
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("迁移文件命名有误: %s", fileName)
+		}
+		version, err := strconv.ParseInt(versionStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("迁移文件版本号有误: %s", fileName)
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, fileName))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("迁移版本 %d 存在多个名称: %s, %s", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(content)
+			m.Checksum = checksum(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	res := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("迁移版本 %d 缺少 up 脚本", m.Version)
+		}
+		res = append(res, *m)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+	return res, nil
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// ensureTable 创建迁移记录表
+func (m *Migrator) ensureTable() error {
+	return m.db.Exec(`CREATE TABLE IF NOT EXISTS ` + tableName + ` (
+		version    bigint PRIMARY KEY,
+		name       character varying(255) NOT NULL,
+		checksum   character varying(64) NOT NULL,
+		applied_at timestamp(0) without time zone NOT NULL DEFAULT now()
+	)`).Error
+}
+
+// applied 获取已执行的迁移记录
+func (m *Migrator) applied() (map[int64]appliedRecord, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+	var records []appliedRecord
+	if err := m.db.Table(tableName).Order("version").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	res := make(map[int64]appliedRecord, len(records))
+	for _, r := range records {
+		res[r.Version] = r
+	}
+	return res, nil
+}
+
+// Drift 检查已执行的迁移脚本是否被修改或删除
+func (m *Migrator) Drift() error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	return m.drift(applied)
+}
+
+func (m *Migrator) drift(applied map[int64]appliedRecord) error {
+	known := map[int64]Migration{}
+	for _, mg := range m.migrations {
+		known[mg.Version] = mg
+	}
+	var errs []error
+	for version, record := range applied {
+		mg, ok := known[version]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("已执行的迁移 %d_%s 不存在于当前版本中", version, record.Name))
+		case mg.Checksum != record.Checksum:
+			errs = append(errs, fmt.Errorf("已执行的迁移 %d_%s 脚本已被修改 (checksum %s -> %s)",
+				version, record.Name, shortSum(record.Checksum), shortSum(mg.Checksum)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Pending 返回尚未执行的迁移, 存在脚本漂移时返回错误
+func (m *Migrator) Pending() ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	if err = m.drift(applied); err != nil {
+		return nil, err
+	}
+	var res []Migration
+	for _, mg := range m.migrations {
+		if _, ok := applied[mg.Version]; !ok {
+			res = append(res, mg)
+		}
+	}
+	return res, nil
+}
+
+// Up 按顺序执行所有未执行的迁移, 存在脚本漂移时拒绝执行
+// 每个版本在独立事务中执行, 失败时停止并保留之前已成功的版本
+func (m *Migrator) Up(out io.Writer) error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	if err = m.drift(applied); err != nil {
+		return fmt.Errorf("检测到迁移脚本漂移, 已停止执行:\n%w", err)
+	}
+
+	count := 0
+	for _, mg := range m.migrations {
+		if _, ok := applied[mg.Version]; ok {
+			continue
+		}
+		begin := time.Now()
+		err = m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(mg.Up).Error; err != nil {
+				return err
+			}
+			return tx.Exec("INSERT INTO "+tableName+" (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)",
+				mg.Version, mg.Name, mg.Checksum, time.Now()).Error
+		})
+		if err != nil {
+			return fmt.Errorf("执行迁移 %d_%s 失败: %w", mg.Version, mg.Name, err)
+		}
+		count++
+		fmt.Fprintf(out, "applied %d_%s (%s)\n", mg.Version, mg.Name, time.Since(begin).Round(time.Millisecond))
+	}
+	if count == 0 {
+		fmt.Fprintln(out, "no pending migrations")
+	}
+	return nil
+}
+
+// Down 回滚最近执行的 steps 个迁移
+func (m *Migrator) Down(out io.Writer, steps int) error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	if err = m.drift(applied); err != nil {
+		return fmt.Errorf("检测到迁移脚本漂移, 已停止回滚:\n%w", err)
+	}
+
+	for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+		mg := m.migrations[i]
+		if _, ok := applied[mg.Version]; !ok {
+			continue
+		}
+		if strings.TrimSpace(mg.Down) == "" {
+			return fmt.Errorf("迁移 %d_%s 没有 down 脚本, 无法回滚", mg.Version, mg.Name)
+		}
+		err = m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(mg.Down).Error; err != nil {
+				return err
+			}
+			return tx.Exec("DELETE FROM "+tableName+" WHERE version = ?", mg.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("回滚迁移 %d_%s 失败: %w", mg.Version, mg.Name, err)
+		}
+		steps--
+		fmt.Fprintf(out, "reverted %d_%s\n", mg.Version, mg.Name)
+	}
+	return nil
+}
+
+// Status 输出每个版本的执行状态, 存在漂移时返回错误
+func (m *Migrator) Status(out io.Writer) error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT\tCHECKSUM")
+	for _, mg := range m.migrations {
+		record, ok := applied[mg.Version]
+		switch {
+		case !ok:
+			fmt.Fprintf(w, "%d\t%s\tpending\t-\t%s\n", mg.Version, mg.Name, shortSum(mg.Checksum))
+		case record.Checksum != mg.Checksum:
+			fmt.Fprintf(w, "%d\t%s\tDRIFT\t%s\t%s\n", mg.Version, mg.Name, record.AppliedAt.Format(time.DateTime), shortSum(record.Checksum))
+		default:
+			fmt.Fprintf(w, "%d\t%s\tapplied\t%s\t%s\n", mg.Version, mg.Name, record.AppliedAt.Format(time.DateTime), shortSum(mg.Checksum))
+		}
+	}
+	// 数据库中存在但当前版本没有的迁移
+	for version, record := range applied {
+		if !m.known(version) {
+			fmt.Fprintf(w, "%d\t%s\tMISSING\t%s\t%s\n", version, record.Name, record.AppliedAt.Format(time.DateTime), shortSum(record.Checksum))
+		}
+	}
+	w.Flush()
+	return m.drift(applied)
+}
+
+func (m *Migrator) known(version int64) bool {
+	for _, mg := range m.migrations {
+		if mg.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+func shortSum(sum string) string {
+	if len(sum) > 12 {
+		return sum[:12]
+	}
+	return sum
+}
@@ -0,0 +1,126 @@
+-- 基线表结构, 已存在的表不做修改
+
+CREATE TABLE IF NOT EXISTS gfg_game (
+    id           bigint PRIMARY KEY,
+    name         character varying(255) NOT NULL,
+    name_en      character varying(255) NOT NULL,
+    info         character varying(300) NOT NULL,
+    info_en      character varying(300) NOT NULL,
+    create_time  timestamp(0) without time zone NOT NULL DEFAULT now(),
+    update_time  timestamp(0) without time zone NOT NULL DEFAULT now(),
+    resources    json,
+    groups       json,
+    release_date character varying(255) NOT NULL,
+    developers   json NOT NULL,
+    publishers   json NOT NULL,
+    appid        bigint NOT NULL,
+    header       character varying(255) NOT NULL,
+    links        json,
+    weight       bigint NOT NULL DEFAULT 0
+);
+COMMENT ON TABLE gfg_game IS '游戏表';
+COMMENT ON COLUMN gfg_game.resources IS '游戏相关资源';
+COMMENT ON COLUMN gfg_game.groups IS '游戏相关社群';
+COMMENT ON COLUMN gfg_game.appid IS 'SteamAPI appid';
+COMMENT ON COLUMN gfg_game.header IS '游戏封面图';
+COMMENT ON COLUMN gfg_game.links IS '三方网站链接';
+COMMENT ON COLUMN gfg_game.weight IS '权重';
+
+CREATE TABLE IF NOT EXISTS gfg_game_record (
+    id           bigint PRIMARY KEY,
+    game_id      bigint NOT NULL,
+    language     text NOT NULL,
+    release_date character varying(30) NOT NULL,
+    platform     character varying(50) NOT NULL,
+    developer    character varying(100) NOT NULL,
+    publisher    character varying(100) NOT NULL,
+    info         text NOT NULL,
+    cover        character varying(255),
+    lang         character varying(20) NOT NULL,
+    price_list   json NOT NULL,
+    initial      bigint NOT NULL,
+    final        bigint NOT NULL,
+    discount     bigint NOT NULL
+);
+COMMENT ON TABLE gfg_game_record IS '游戏记录表 按语言采集的 Steam 商店信息';
+COMMENT ON COLUMN gfg_game_record.price_list IS '游戏价格列表';
+COMMENT ON COLUMN gfg_game_record.initial IS '游戏价格';
+COMMENT ON COLUMN gfg_game_record.final IS '当前价格';
+COMMENT ON COLUMN gfg_game_record.discount IS '折扣百分比';
+
+CREATE TABLE IF NOT EXISTS gfg_game_news (
+    id          bigint PRIMARY KEY,
+    game_id     bigint NOT NULL,
+    headline    character varying(255) NOT NULL,
+    content     text NOT NULL,
+    index       bigint NOT NULL,
+    post_time   timestamp(0) without time zone NOT NULL,
+    create_time timestamp(0) without time zone NOT NULL DEFAULT now(),
+    author      character varying(50) NOT NULL,
+    url         character varying(255) NOT NULL,
+    total       bigint NOT NULL,
+    lang        character varying(30) NOT NULL
+);
+COMMENT ON TABLE gfg_game_news IS '游戏更新公告记录表';
+COMMENT ON COLUMN gfg_game_news.index IS '更新公告编号';
+COMMENT ON COLUMN gfg_game_news.total IS '公告总数';
+
+CREATE TABLE IF NOT EXISTS gfg_tag (
+    id          bigint PRIMARY KEY,
+    name        character varying(255) NOT NULL,
+    name_en     character varying(255) NOT NULL,
+    info        character varying(255) NOT NULL,
+    info_en     character varying(255) NOT NULL,
+    prefix      bigint NOT NULL DEFAULT -1,
+    create_time timestamp(0) without time zone NOT NULL DEFAULT now(),
+    update_time timestamp(0) without time zone NOT NULL DEFAULT now()
+);
+COMMENT ON TABLE gfg_tag IS '标签表';
+COMMENT ON COLUMN gfg_tag.prefix IS '父标签 没有为-1';
+
+CREATE TABLE IF NOT EXISTS gfg_tag_map (
+    id          bigint PRIMARY KEY,
+    game_id     bigint NOT NULL,
+    tag_id      bigint NOT NULL,
+    create_time timestamp(0) without time zone NOT NULL DEFAULT now(),
+    update_time timestamp(0) without time zone NOT NULL DEFAULT now()
+);
+COMMENT ON TABLE gfg_tag_map IS '游戏标签映射表';
+
+CREATE TABLE IF NOT EXISTS gfg_game_comment (
+    id          bigint PRIMARY KEY,
+    region      character varying(50) NOT NULL,
+    content     character varying(255) NOT NULL,
+    score       double precision NOT NULL,
+    create_time timestamp(0) without time zone NOT NULL DEFAULT now(),
+    game_id     bigint NOT NULL,
+    ip          character varying(50) NOT NULL,
+    name        character varying(50)
+);
+COMMENT ON TABLE gfg_game_comment IS '评论表';
+
+CREATE TABLE IF NOT EXISTS gfg_game_player_count (
+    id          bigint PRIMARY KEY,
+    game_id     bigint NOT NULL,
+    count       bigint NOT NULL,
+    create_time timestamp(0) without time zone NOT NULL DEFAULT now()
+);
+COMMENT ON TABLE gfg_game_player_count IS '在线人数表';
+
+CREATE TABLE IF NOT EXISTS gfg_game_creator (
+    id          bigint PRIMARY KEY,
+    name        character varying(50) NOT NULL,
+    info        character varying(255) NOT NULL,
+    main_url    character varying(255) NOT NULL,
+    links       jsonb,
+    cover       character varying(255) NOT NULL,
+    contact     jsonb,
+    create_time timestamp(0) without time zone NOT NULL DEFAULT now(),
+    update_time timestamp(0) without time zone NOT NULL DEFAULT now(),
+    type        bigint NOT NULL,
+    name_en     character varying(255),
+    info_en     character varying(255),
+    deleted     boolean NOT NULL DEFAULT false
+);
+COMMENT ON TABLE gfg_game_creator IS '相关作者表';
+COMMENT ON COLUMN gfg_game_creator.type IS '类型描述 1=Steam鉴赏家 2=博主 3=开发者 4=发行者 5=汉化者 6=内容创作者';
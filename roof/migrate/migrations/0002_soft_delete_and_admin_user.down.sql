@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS gfg_admin_user;
+
+ALTER TABLE gfg_tag_map DROP COLUMN IF EXISTS deleted;
+ALTER TABLE gfg_tag DROP COLUMN IF EXISTS deleted;
+ALTER TABLE gfg_game DROP COLUMN IF EXISTS deleted;
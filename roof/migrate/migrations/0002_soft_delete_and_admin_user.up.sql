@@ -0,0 +1,21 @@
+-- 后台管理使用的软删除字段与管理员表
+
+ALTER TABLE gfg_game ADD COLUMN IF NOT EXISTS deleted boolean NOT NULL DEFAULT false;
+ALTER TABLE gfg_tag ADD COLUMN IF NOT EXISTS deleted boolean NOT NULL DEFAULT false;
+ALTER TABLE gfg_tag_map ADD COLUMN IF NOT EXISTS deleted boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS gfg_admin_user (
+    id              bigint PRIMARY KEY,
+    username        character varying(50) NOT NULL,
+    password        character varying(64) NOT NULL,
+    role            character varying(20) NOT NULL,
+    last_login_time timestamp(0) without time zone,
+    create_time     timestamp(0) without time zone NOT NULL DEFAULT now(),
+    update_time     timestamp(0) without time zone NOT NULL DEFAULT now(),
+    deleted         boolean NOT NULL DEFAULT false
+);
+COMMENT ON TABLE gfg_admin_user IS '管理员表';
+COMMENT ON COLUMN gfg_admin_user.password IS '加盐后的密码哈希';
+COMMENT ON COLUMN gfg_admin_user.role IS '角色 admin/editor';
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_gfg_admin_user_username ON gfg_admin_user (username) WHERE deleted IS NOT TRUE;
@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS idx_gfg_game_comment_create_time;
+DROP INDEX IF EXISTS idx_gfg_game_comment_game;
+DROP INDEX IF EXISTS idx_gfg_tag_prefix;
+DROP INDEX IF EXISTS uk_gfg_tag_map_game_tag;
+DROP INDEX IF EXISTS idx_gfg_tag_map_tag;
+DROP INDEX IF EXISTS idx_gfg_tag_map_game;
+DROP INDEX IF EXISTS idx_gfg_game_player_count_game_time;
+DROP INDEX IF EXISTS idx_gfg_game_news_lang_post;
+DROP INDEX IF EXISTS idx_gfg_game_news_game_lang_post;
+DROP INDEX IF EXISTS idx_gfg_game_record_lang_final;
+DROP INDEX IF EXISTS idx_gfg_game_record_game_lang;
+DROP INDEX IF EXISTS idx_gfg_game_weight;
+DROP INDEX IF EXISTS idx_gfg_game_create_time;
+DROP INDEX IF EXISTS idx_gfg_game_release_date;
+DROP INDEX IF EXISTS idx_gfg_game_info_en_trgm;
+DROP INDEX IF EXISTS idx_gfg_game_info_trgm;
+DROP INDEX IF EXISTS idx_gfg_game_name_en_trgm;
+DROP INDEX IF EXISTS idx_gfg_game_name_trgm;
@@ -0,0 +1,43 @@
+-- 搜索与面板查询使用的索引
+
+-- 模糊搜索 name/name_en/info/info_en ILIKE
+-- 迁移在事务中执行, 无法使用 CREATE INDEX CONCURRENTLY, 建索引期间会阻塞 gfg_game 的写入
+-- gfg_game 只有游戏条目, 数据量小, 阻塞时间可以忽略; 数据量大时请先在线执行
+--   CREATE INDEX CONCURRENTLY IF NOT EXISTS <同名索引> ...
+-- 再执行 migrate up, 已存在的索引会被跳过
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_gfg_game_name_trgm ON gfg_game USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_gfg_game_name_en_trgm ON gfg_game USING gin (name_en gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_gfg_game_info_trgm ON gfg_game USING gin (info gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_gfg_game_info_en_trgm ON gfg_game USING gin (info_en gin_trgm_ops);
+
+-- 首页分组 最近发售/最新收录/权重排序
+CREATE INDEX IF NOT EXISTS idx_gfg_game_release_date ON gfg_game (release_date DESC);
+CREATE INDEX IF NOT EXISTS idx_gfg_game_create_time ON gfg_game (create_time DESC);
+CREATE INDEX IF NOT EXISTS idx_gfg_game_weight ON gfg_game (weight);
+
+-- 游戏记录与价格面板
+CREATE INDEX IF NOT EXISTS idx_gfg_game_record_game_lang ON gfg_game_record (game_id, lang);
+CREATE INDEX IF NOT EXISTS idx_gfg_game_record_lang_final ON gfg_game_record (lang, final DESC);
+
+-- 更新公告
+CREATE INDEX IF NOT EXISTS idx_gfg_game_news_game_lang_post ON gfg_game_news (game_id, lang, post_time DESC);
+CREATE INDEX IF NOT EXISTS idx_gfg_game_news_lang_post ON gfg_game_news (lang, post_time DESC);
+
+-- 在线人数面板
+CREATE INDEX IF NOT EXISTS idx_gfg_game_player_count_game_time ON gfg_game_player_count (game_id, create_time DESC);
+
+-- 标签过滤与推荐
+CREATE INDEX IF NOT EXISTS idx_gfg_tag_map_game ON gfg_tag_map (game_id);
+CREATE INDEX IF NOT EXISTS idx_gfg_tag_map_tag ON gfg_tag_map (tag_id);
+-- 同一游戏重复关联同一标签时只保留 ID 最小的一条, 其余软删除, 否则唯一索引无法创建
+UPDATE gfg_tag_map m SET deleted = true
+FROM gfg_tag_map k
+WHERE m.game_id = k.game_id AND m.tag_id = k.tag_id AND m.id > k.id
+  AND m.deleted IS NOT TRUE AND k.deleted IS NOT TRUE;
+CREATE UNIQUE INDEX IF NOT EXISTS uk_gfg_tag_map_game_tag ON gfg_tag_map (game_id, tag_id) WHERE deleted IS NOT TRUE;
+CREATE INDEX IF NOT EXISTS idx_gfg_tag_prefix ON gfg_tag (prefix);
+
+-- 评论统计与最新评论
+CREATE INDEX IF NOT EXISTS idx_gfg_game_comment_game ON gfg_game_comment (game_id);
+CREATE INDEX IF NOT EXISTS idx_gfg_game_comment_create_time ON gfg_game_comment (create_time DESC);
@@ -0,0 +1,19 @@
+-- 价格历史, 采集到的价格与上一条不同时追加
+
+CREATE TABLE IF NOT EXISTS gfg_game_price_history (
+    id          bigint PRIMARY KEY,
+    game_id     bigint NOT NULL,
+    region      character varying(10) NOT NULL,
+    currency    character varying(10) NOT NULL,
+    initial     bigint NOT NULL,
+    final       bigint NOT NULL,
+    discount    bigint NOT NULL,
+    create_time timestamp(0) without time zone NOT NULL DEFAULT now()
+);
+COMMENT ON TABLE gfg_game_price_history IS '游戏价格历史表';
+COMMENT ON COLUMN gfg_game_price_history.region IS '地区代码 cn/us/...';
+COMMENT ON COLUMN gfg_game_price_history.initial IS '原价(分)';
+COMMENT ON COLUMN gfg_game_price_history.final IS '实际价格(分)';
+COMMENT ON COLUMN gfg_game_price_history.discount IS '折扣百分比';
+
+CREATE INDEX IF NOT EXISTS idx_gfg_game_price_history_game_region_time ON gfg_game_price_history (game_id, region, create_time);
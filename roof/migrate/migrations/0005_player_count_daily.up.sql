@@ -0,0 +1,25 @@
+-- 在线人数按天汇总, 长时间范围的走势查询只读汇总表
+
+CREATE TABLE IF NOT EXISTS gfg_game_player_count_daily (
+    game_id     bigint NOT NULL,
+    day         date NOT NULL,
+    count_min   bigint NOT NULL,
+    count_avg   bigint NOT NULL,
+    count_max   bigint NOT NULL,
+    samples     bigint NOT NULL,
+    update_time timestamp(0) without time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (game_id, day)
+);
+COMMENT ON TABLE gfg_game_player_count_daily IS '在线人数日汇总表';
+COMMENT ON COLUMN gfg_game_player_count_daily.day IS '统计日期';
+COMMENT ON COLUMN gfg_game_player_count_daily.count_min IS '当日最低在线人数';
+COMMENT ON COLUMN gfg_game_player_count_daily.count_avg IS '当日平均在线人数';
+COMMENT ON COLUMN gfg_game_player_count_daily.count_max IS '当日最高在线人数';
+COMMENT ON COLUMN gfg_game_player_count_daily.samples IS '当日采样次数';
+
+-- 汇总已有的采样数据
+INSERT INTO gfg_game_player_count_daily (game_id, day, count_min, count_avg, count_max, samples)
+SELECT game_id, create_time::date, MIN(count), ROUND(AVG(count))::INT8, MAX(count), COUNT(*)
+FROM gfg_game_player_count
+GROUP BY game_id, create_time::date
+ON CONFLICT (game_id, day) DO NOTHING;
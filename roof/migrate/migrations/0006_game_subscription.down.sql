@@ -0,0 +1 @@
+DROP TABLE IF EXISTS gfg_game_subscription;
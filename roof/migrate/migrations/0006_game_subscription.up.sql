@@ -0,0 +1,24 @@
+-- 降价订阅, 记录订阅时的价格作为比较基准, 每次提醒后更新
+
+CREATE TABLE IF NOT EXISTS gfg_game_subscription (
+    id            bigint PRIMARY KEY,
+    email         character varying(255) NOT NULL,
+    game_id       bigint NOT NULL,
+    lang          character varying(20) NOT NULL,
+    threshold     bigint NOT NULL,
+    last_final    bigint NOT NULL,
+    last_discount bigint NOT NULL,
+    notify_time   timestamp(0) without time zone,
+    create_time   timestamp(0) without time zone NOT NULL DEFAULT now(),
+    update_time   timestamp(0) without time zone NOT NULL DEFAULT now(),
+    deleted       boolean NOT NULL DEFAULT false
+);
+COMMENT ON TABLE gfg_game_subscription IS '游戏降价订阅表';
+COMMENT ON COLUMN gfg_game_subscription.lang IS '订阅的价格记录语言 zh/en';
+COMMENT ON COLUMN gfg_game_subscription.threshold IS '折扣阈值(百分比)';
+COMMENT ON COLUMN gfg_game_subscription.last_final IS '上次提醒时的价格(分)';
+COMMENT ON COLUMN gfg_game_subscription.last_discount IS '上次提醒时的折扣百分比';
+COMMENT ON COLUMN gfg_game_subscription.notify_time IS '上次提醒时间';
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_gfg_game_subscription_email_game ON gfg_game_subscription (email, game_id) WHERE deleted IS NOT TRUE;
+CREATE INDEX IF NOT EXISTS idx_gfg_game_subscription_game ON gfg_game_subscription (game_id);
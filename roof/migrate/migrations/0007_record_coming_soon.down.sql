@@ -0,0 +1 @@
+ALTER TABLE gfg_game_record DROP COLUMN IF EXISTS coming_soon;
@@ -0,0 +1,4 @@
+-- Steam 商店的 release_date.coming_soon, 用于发售日历识别未发售游戏
+
+ALTER TABLE gfg_game_record ADD COLUMN IF NOT EXISTS coming_soon boolean NOT NULL DEFAULT false;
+COMMENT ON COLUMN gfg_game_record.coming_soon IS '是否尚未发售';
@@ -0,0 +1,2 @@
+ALTER TABLE gfg_tag_map DROP COLUMN IF EXISTS weight;
+ALTER TABLE gfg_tag DROP COLUMN IF EXISTS weight;
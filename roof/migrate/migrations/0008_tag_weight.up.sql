@@ -0,0 +1,7 @@
+-- 基于内容推荐使用的标签权重, 映射权重用于单个游戏内突出或弱化某个标签
+
+ALTER TABLE gfg_tag ADD COLUMN IF NOT EXISTS weight double precision NOT NULL DEFAULT 1;
+COMMENT ON COLUMN gfg_tag.weight IS '推荐权重';
+
+ALTER TABLE gfg_tag_map ADD COLUMN IF NOT EXISTS weight double precision NOT NULL DEFAULT 1;
+COMMENT ON COLUMN gfg_tag_map.weight IS '该游戏下的标签权重';
@@ -0,0 +1,2 @@
+COMMENT ON COLUMN gfg_admin_user.password IS '加盐后的密码哈希';
+ALTER TABLE gfg_admin_user ALTER COLUMN password TYPE character varying(64);
@@ -0,0 +1,4 @@
+-- 管理员密码改为 bcrypt 哈希, 旧版 MD5 哈希在下次登录成功时自动升级
+
+ALTER TABLE gfg_admin_user ALTER COLUMN password TYPE character varying(255);
+COMMENT ON COLUMN gfg_admin_user.password IS 'bcrypt 密码哈希';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS uk_gfg_game_news_game_lang_gid;
+ALTER TABLE gfg_game_news DROP COLUMN IF EXISTS gid;
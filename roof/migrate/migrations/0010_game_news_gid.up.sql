@@ -0,0 +1,7 @@
+-- Steam 公告的 gid, 采集时按 game_id + lang + gid 更新已有公告, 不再整体替换
+-- 旧数据 gid 为空, 采集时按 url 匹配后补齐
+
+ALTER TABLE gfg_game_news ADD COLUMN IF NOT EXISTS gid character varying(30) NOT NULL DEFAULT '';
+COMMENT ON COLUMN gfg_game_news.gid IS 'Steam 公告 gid';
+
+CREATE UNIQUE INDEX IF NOT EXISTS uk_gfg_game_news_game_lang_gid ON gfg_game_news (game_id, lang, gid) WHERE gid <> '';
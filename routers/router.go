@@ -16,8 +16,6 @@ import (
 	"github.com/GoFurry/gofurry-game-backend/roof/env"
 	"github.com/gofiber/contrib/swagger"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/gofiber/fiber/v2/middleware/pprof"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 )
@@ -62,6 +60,12 @@ func (router *router) Init() *fiber.App {
 	recommendApi(app.Group("/api/recommend"))
 	searchApi(app.Group("/api/search"))
 	reviewApi(app.Group("/api/review"))
+	subscribeApi(app.Group("/api/subscribe"))
+	authApi(app.Group("/api/auth"))
+	feedApi(app.Group("/feed"))
+	sitemapApi(app)
+	// 后台管理 需登录且为管理员或编辑
+	adminApi(app.Group("/api/admin", middleware.AuthMiddleware, middleware.RequireRole(common.ROLE_ADMIN, common.ROLE_EDITOR)))
 
 	app.Get("/api/swagger/doc.json", func(c *fiber.Ctx) error {
 		return c.SendFile("./docs/swagger.json")
@@ -78,34 +82,17 @@ func registerMiddlewares(app *fiber.App) {
 		EnableStackTrace: cfg.Server.Mode == "debug", // 仅调试模式打印堆栈
 	}))
 
-	// 跨域中间件
-	app.Use(cors.New(cors.Config{
-		AllowOrigins:     cfg.Middleware.Cors.AllowOrigins,
-		AllowMethods:     "GET,POST,PUT,DELETE,PATCH,OPTIONS",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Requested-With",
-		AllowCredentials: true,
-		ExposeHeaders:    "Content-Length",
-		MaxAge:           86400, // 预检请求缓存 24 小时
-	}))
+	// 健康检查 注册在限流、WAF、统计等中间件之前, 探针请求不受其影响
+	systemApi(app)
 
-	// 请求限流
-	if cfg.Middleware.Limiter.IsOn {
-		app.Use(limiter.New(limiter.Config{
-			Max:        cfg.Middleware.Limiter.MaxRequests,              // 单位时间最大请求数
-			Expiration: cfg.Middleware.Limiter.Expiration * time.Second, // 时间窗口
-			KeyGenerator: func(c *fiber.Ctx) string {
-				return c.IP() // 按 IP 限流
-			},
-			LimitReached: func(c *fiber.Ctx) error {
-				return common.NewResponse(c).ErrorWithCode("请求过于频繁, 请稍后再试", fiber.StatusTooManyRequests)
-			},
-		}))
-	}
+	// 跨域中间件 支持热重载
+	app.Use(middleware.CorsMiddleware())
 
-	// WAF 中间件
-	if cfg.Waf.WafSwitch {
-		app.Use(middleware.CorazaMiddleware())
-	}
+	// 请求限流 支持热重载, 关闭时直接放行
+	app.Use(middleware.LimiterMiddleware())
+
+	// WAF 中间件 支持热重载, 关闭时直接放行
+	app.Use(middleware.CorazaMiddleware())
 
 	// 调试模式专属
 	if cfg.Server.Mode == "debug" {
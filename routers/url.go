@@ -1,10 +1,18 @@
 package routers
 
 import (
+	admin "github.com/GoFurry/gofurry-game-backend/apps/admin/controller"
+	auth "github.com/GoFurry/gofurry-game-backend/apps/auth/controller"
+	feed "github.com/GoFurry/gofurry-game-backend/apps/feed/controller"
 	game "github.com/GoFurry/gofurry-game-backend/apps/game/controller"
 	recommend "github.com/GoFurry/gofurry-game-backend/apps/recommend/controller"
 	review "github.com/GoFurry/gofurry-game-backend/apps/review/controller"
 	search "github.com/GoFurry/gofurry-game-backend/apps/search/controller"
+	sitemap "github.com/GoFurry/gofurry-game-backend/apps/sitemap/controller"
+	subscribe "github.com/GoFurry/gofurry-game-backend/apps/subscribe/controller"
+	system "github.com/GoFurry/gofurry-game-backend/apps/system/controller"
+	"github.com/GoFurry/gofurry-game-backend/common"
+	"github.com/GoFurry/gofurry-game-backend/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -15,28 +23,58 @@ import (
  */
 
 func gameApi(g fiber.Router) {
-	g.Get("/info", game.GameApi.GetGameInfo) // 获取单条游戏的基础信息
-	//g.Get("/info/intro", game.GameApi.GetGameIntro)     // 获取游戏内容详情
-	g.Get("/info/list", game.GameApi.GetGameList)       // 获取前 num 条游戏记录
-	g.Get("/info/main", game.GameApi.GetGameMainList)   // 获取首页展示数据
-	g.Get("/panel/main", game.GameApi.GetPanelMainList) // 获取首页面板数据
-	g.Get("/update/latest", game.GameApi.GetUpdateNews) // 获取首页更新公告
+	editorOnly := middleware.RequireRole(common.ROLE_ADMIN, common.ROLE_EDITOR) // 管理员或编辑
+
+	g.Get("/info", game.GameApi.GetGameInfo)                                                     // 获取单条游戏的基础信息
+	g.Get("/info/intro", game.GameApi.GetGameIntro)                                              // 获取游戏内容详情
+	g.Get("/info/intro/batch", game.GameApi.BatchGetGameIntro)                                   // 批量获取游戏内容详情
+	g.Post("/info/intro", middleware.AuthMiddleware, editorOnly, game.GameApi.SaveGameIntro)     // 保存游戏内容详情
+	g.Delete("/info/intro", middleware.AuthMiddleware, editorOnly, game.GameApi.DeleteGameIntro) // 删除游戏内容详情
+	g.Get("/info/list", game.GameApi.GetGameList)                                                // 获取前 num 条游戏记录
+	g.Get("/info/main", game.GameApi.GetGameMainList)                                            // 获取首页展示数据
+	g.Get("/panel/main", game.GameApi.GetPanelMainList)                                          // 获取首页面板数据
+	g.Get("/update/latest", game.GameApi.GetUpdateNews)                                          // 获取首页更新公告
 
 	g.Get("/remark", game.GameApi.GetGameRemark) // 获取单条游戏的评论
 
-	g.Get("/tag/list", game.GameApi.GetTagList) // 获取标签列表
+	g.Get("/news", game.GameApi.PageGameNews)         // 分页获取游戏更新公告
+	g.Get("/news/detail", game.GameApi.GetNewsDetail) // 获取更新公告详情
+	g.Get("/news/feed", game.GameApi.PageNewsFeed)    // 分页获取全站更新公告
+
+	g.Get("/price/history", game.GameApi.GetPriceHistory) // 获取游戏价格历史
+	g.Get("/price/stat", game.GameApi.GetPriceStat)       // 获取游戏各地区价格统计
+
+	g.Get("/online/history", game.GameApi.GetOnlineHistory)   // 获取游戏在线人数走势
+	g.Get("/online/trending", game.GameApi.GetOnlineTrending) // 获取在线人数增长最快的游戏
+
+	g.Get("/calendar", game.GameApi.GetCalendar)        // 获取发售日历
+	g.Get("/calendar.ics", game.GameApi.GetCalendarICS) // 导出发售日历
+
+	g.Get("/tag/list", game.GameApi.GetTagList)        // 获取标签列表
+	g.Get("/tag/tree", game.GameApi.GetTagTree)        // 获取标签树
+	g.Get("/tag/related", game.GameApi.GetRelatedTags) // 获取相关标签
+	g.Get("/tag/graph", game.GameApi.GetTagGraph)      // 获取标签共现图
 
 	g.Get("/creator", game.GameApi.GetGameCreator) // 获取相关开发者列表
 }
 
 func recommendApi(g fiber.Router) {
-	// TODO: 标签表新增一个权重字段
-
 	// 基于内容的推荐（Content-based Filtering）
 	// 优点: 存储小 速度快 无冷启动 无需用户行为数据
-	// 缺点: 需要传入初始物品, 特征值永远为静态, 每次推荐相同
-	// 实现重点: 余弦相似度 特征提取-独热编码
-	g.Get("/game/CBF", recommend.RecommendApi.RecommendByCBF)     // 用 CBF 返回游戏记录
+	// 缺点: 需要传入初始物品, 特征值永远为静态
+	// 结果重排: 最大边际相关(MMR) 按 diversity 分散标签相近的结果, 传入 seed 时随机探索, 同一 seed 结果可复现
+	// 实现重点: 加权余弦相似度 特征提取-标签权重*IDF, 常见标签自动降权
+	g.Get("/game/CBF", recommend.RecommendApi.RecommendByCBF) // 用 CBF 返回游戏记录
+
+	// 基于物品的协同过滤（Item-based Collaborative Filtering）
+	// 数据来源: 匿名评论的评分, 评分人以 ip+名称区分
+	// 实现重点: 调整余弦相似度 共同评分人数收缩, 评分不足时回退到 CBF
+	g.Get("/game/CF", recommend.RecommendApi.RecommendByCF)         // 用 CF 返回游戏记录
+	g.Get("/game/hybrid", recommend.RecommendApi.RecommendByHybrid) // 按权重混合 CF 与 CBF
+
+	// 按一组游戏推荐, 由多个游戏的标签构建偏好向量, 可指定不喜欢的游戏
+	g.Post("/game/profile", recommend.RecommendApi.RecommendByProfile) // 为收藏列表推荐
+
 	g.Get("/game/random", recommend.RecommendApi.GetRandomGameID) // 返回一个随机的游戏记录 ID
 }
 
@@ -49,3 +87,48 @@ func reviewApi(g fiber.Router) {
 	g.Post("/anonymous", review.ReviewApi.SimpleSearch)    // 匿名评论
 	g.Get("/latest", review.ReviewApi.GetLatestReviewList) // 获取最新的评论列表
 }
+
+func subscribeApi(g fiber.Router) {
+	g.Post("/code", subscribe.SubscribeApi.SendCode)          // 申请订阅验证码
+	g.Post("", subscribe.SubscribeApi.Subscribe)              // 订阅降价提醒
+	g.Get("/unsubscribe", subscribe.SubscribeApi.Unsubscribe) // 退订降价提醒
+}
+
+func feedApi(g fiber.Router) {
+	g.Get("/news.xml", feed.FeedApi.NewsRSS)   // 更新公告 RSS
+	g.Get("/news.atom", feed.FeedApi.NewsAtom) // 更新公告 Atom
+	g.Get("/news.json", feed.FeedApi.NewsJSON) // 更新公告 JSON Feed
+	g.Get("/games.xml", feed.FeedApi.GamesRSS) // 新游收录 RSS
+}
+
+func sitemapApi(g fiber.Router) {
+	g.Get("/sitemap.xml", sitemap.SitemapApi.GetIndex)   // 站点地图索引
+	g.Get("/sitemap/:name", sitemap.SitemapApi.GetChunk) // 站点地图分片
+}
+
+func systemApi(g fiber.Router) {
+	g.Get("/healthz", system.SystemApi.Liveness) // 存活检测
+	g.Get("/readyz", system.SystemApi.Readiness) // 就绪检测
+}
+
+func authApi(g fiber.Router) {
+	g.Post("/login", auth.AuthApi.Login)        // 管理员登录
+	g.Get("/pubkey", auth.AuthApi.GetPublicKey) // 获取登录公钥
+}
+
+func adminApi(g fiber.Router) {
+	g.Post("/game", admin.AdminApi.AddGame)            // 新增游戏
+	g.Put("/game", admin.AdminApi.UpdateGame)          // 修改游戏
+	g.Delete("/game", admin.AdminApi.DeleteGame)       // 软删除游戏
+	g.Post("/tag", admin.AdminApi.AddTag)              // 新增标签
+	g.Put("/tag", admin.AdminApi.UpdateTag)            // 修改标签
+	g.Delete("/tag", admin.AdminApi.DeleteTag)         // 软删除标签
+	g.Post("/tag-map", admin.AdminApi.AddTagMap)       // 新增游戏标签映射
+	g.Put("/tag-map", admin.AdminApi.UpdateTagMap)     // 修改游戏标签映射
+	g.Delete("/tag-map", admin.AdminApi.DeleteTagMap)  // 软删除游戏标签映射
+	g.Post("/creator", admin.AdminApi.AddCreator)      // 新增相关作者
+	g.Put("/creator", admin.AdminApi.UpdateCreator)    // 修改相关作者
+	g.Delete("/creator", admin.AdminApi.DeleteCreator) // 软删除相关作者
+
+	g.Post("/system/reload", middleware.RequireRole(common.ROLE_ADMIN), system.SystemApi.Reload) // 热重载配置 仅管理员
+}